@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/ruranjo/unientrega/internal/config"
 	"github.com/ruranjo/unientrega/internal/database"
+	"github.com/ruranjo/unientrega/internal/models"
 	"github.com/ruranjo/unientrega/internal/routes"
 )
 
@@ -20,6 +21,9 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Prices and fees are in the configured currency
+	models.SetDefaultCurrency(cfg.App.Currency)
+
 	// Initialize database connection
 	if err := database.Connect(cfg); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
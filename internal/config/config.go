@@ -3,9 +3,12 @@ package config
 import (
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,18 +16,27 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	CORS     CORSConfig
-	Server   ServerConfig
+	App         AppConfig
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	CORS        CORSConfig
+	Server      ServerConfig
+	Dispatch    DispatchConfig
+	Pricing     PricingConfig
+	Idempotency IdempotencyConfig
+	Cart        CartConfig
+	Storage     StorageConfig
+	Mail        MailConfig
+	Membership  MembershipConfig
+	Inventory   InventoryConfig
 }
 
 // AppConfig holds application-level configuration
 type AppConfig struct {
-	Env  string
-	Port string
-	Host string
+	Env      string
+	Port     string
+	Host     string
+	Currency string // ISO 4217 code of prices and fees
 }
 
 // DatabaseConfig holds database connection configuration
@@ -44,6 +56,9 @@ type DatabaseConfig struct {
 type JWTConfig struct {
 	Secret     string
 	Expiration time.Duration
+
+	RefreshExpiration      time.Duration // How long a session stays signed in without refreshing
+	SessionCleanupInterval time.Duration // How often expired and revoked sessions are removed
 }
 
 // CORSConfig holds CORS configuration
@@ -60,6 +75,75 @@ type ServerConfig struct {
 	IdleTimeout  time.Duration
 }
 
+// DispatchConfig holds automatic courier dispatch configuration
+type DispatchConfig struct {
+	Enabled         bool
+	Strategy        string
+	OfferTimeout    time.Duration
+	ReofferCooldown time.Duration // How long before a courier may be offered the same order again
+	SweepInterval   time.Duration
+}
+
+// IdempotencyConfig holds the Idempotency-Key store configuration
+type IdempotencyConfig struct {
+	TTL             time.Duration // How long a key is remembered
+	CleanupInterval time.Duration // How often expired keys are removed
+}
+
+// CartConfig holds shopping cart configuration
+type CartConfig struct {
+	TTL time.Duration // How long a cart is kept without changes
+}
+
+// StorageConfig holds file upload storage configuration
+type StorageConfig struct {
+	Driver        string // Storage backend, only "local" for now
+	LocalPath     string // Root directory of the local backend
+	MaxUploadSize int64  // Largest accepted upload in bytes
+
+	SigningSecret  string        // Signs short-lived download URLs, defaults to the JWT secret
+	DownloadURLTTL time.Duration // How long a signed download URL stays valid
+}
+
+// MailConfig holds outgoing email configuration
+type MailConfig struct {
+	Driver       string // "log" writes emails to the server log, "smtp" sends them
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// MembershipConfig holds store staff membership configuration
+type MembershipConfig struct {
+	InvitationTTL time.Duration // How long a store invitation can be accepted
+}
+
+// InventoryConfig holds stock alert configuration
+type InventoryConfig struct {
+	LowStockAlerts        bool          // Email store members when products drop to their reorder threshold
+	LowStockCheckInterval time.Duration // How often stock levels are checked
+}
+
+// PricingConfig holds delivery fee configuration.
+// Amounts are in minor units (cents) of the application currency; the environment
+// variables take decimal amounts, rounded half away from zero to whole cents.
+type PricingConfig struct {
+	DeliveryBaseFee       int64            // Fee when no zone or distance rule applies
+	ZoneFees              map[string]int64 // Fee per zone pair, keyed by ZonePairKey
+	DistanceBands         []DistanceBand   // Fee by straight-line distance, sorted by MaxMeters
+	FreeDeliveryThreshold int64            // Subtotal from which delivery is free, 0 disables
+	SmallOrderThreshold   int64            // Subtotal below which the surcharge applies, 0 disables
+	SmallOrderSurcharge   int64
+}
+
+// DistanceBand is the delivery fee for destinations up to MaxMeters away from the store
+type DistanceBand struct {
+	MaxMeters float64
+	Fee       int64
+}
+
 // LoadEnv attempts to load .env file from current directory or parent directories
 func LoadEnv() {
 	// Try to load from current directory first
@@ -101,9 +185,10 @@ func LoadEnv() {
 func Load() (*Config, error) {
 	cfg := &Config{
 		App: AppConfig{
-			Env:  getEnv("APP_ENV", "development"),
-			Port: getEnv("APP_PORT", "8080"),
-			Host: getEnv("APP_HOST", "0.0.0.0"),
+			Env:      getEnv("APP_ENV", "development"),
+			Port:     getEnv("APP_PORT", "8080"),
+			Host:     getEnv("APP_HOST", "0.0.0.0"),
+			Currency: strings.ToUpper(getEnv("APP_CURRENCY", "USD")),
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -119,22 +204,120 @@ func Load() (*Config, error) {
 		JWT: JWTConfig{
 			Secret:     getEnv("JWT_SECRET", "your-secret-key-change-this-in-production"),
 			Expiration: getEnvAsDuration("JWT_EXPIRATION", 24*time.Hour),
+
+			RefreshExpiration:      getEnvAsDuration("JWT_REFRESH_EXPIRATION", 7*24*time.Hour),
+			SessionCleanupInterval: getEnvAsDuration("SESSION_CLEANUP_INTERVAL", time.Hour),
 		},
 		CORS: CORSConfig{
 			AllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:8080"),
 			AllowedMethods: getEnv("CORS_ALLOWED_METHODS", "GET,POST,PUT,DELETE,OPTIONS"),
-			AllowedHeaders: getEnv("CORS_ALLOWED_HEADERS", "Content-Type,Authorization"),
+			AllowedHeaders: getEnv("CORS_ALLOWED_HEADERS", "Content-Type,Authorization,Idempotency-Key"),
 		},
 		Server: ServerConfig{
 			ReadTimeout:  getEnvAsDuration("SERVER_READ_TIMEOUT", 10*time.Second),
 			WriteTimeout: getEnvAsDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
 			IdleTimeout:  getEnvAsDuration("SERVER_IDLE_TIMEOUT", 120*time.Second),
 		},
+		Dispatch: DispatchConfig{
+			Enabled:         getEnvAsBool("DISPATCH_ENABLED", true),
+			Strategy:        getEnv("DISPATCH_STRATEGY", "least_loaded"),
+			OfferTimeout:    getEnvAsDuration("DISPATCH_OFFER_TIMEOUT", 60*time.Second),
+			ReofferCooldown: getEnvAsDuration("DISPATCH_REOFFER_COOLDOWN", 5*time.Minute),
+			SweepInterval:   getEnvAsDuration("DISPATCH_SWEEP_INTERVAL", 15*time.Second),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:             getEnvAsDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+			CleanupInterval: getEnvAsDuration("IDEMPOTENCY_CLEANUP_INTERVAL", time.Hour),
+		},
+		Cart: CartConfig{
+			TTL: getEnvAsDuration("CART_TTL", 72*time.Hour),
+		},
+		Storage: StorageConfig{
+			Driver:         getEnv("STORAGE_DRIVER", "local"),
+			LocalPath:      getEnv("STORAGE_LOCAL_PATH", "./uploads"),
+			MaxUploadSize:  int64(getEnvAsInt("STORAGE_MAX_UPLOAD_MB", 50)) << 20,
+			DownloadURLTTL: getEnvAsDuration("STORAGE_DOWNLOAD_URL_TTL", 5*time.Minute),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "UniEntrega <no-reply@unientrega.local>"),
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		},
+		Membership: MembershipConfig{
+			InvitationTTL: getEnvAsDuration("STORE_INVITATION_TTL", 7*24*time.Hour),
+		},
+		Inventory: InventoryConfig{
+			LowStockAlerts:        getEnvAsBool("LOW_STOCK_ALERTS_ENABLED", true),
+			LowStockCheckInterval: getEnvAsDuration("LOW_STOCK_CHECK_INTERVAL", 5*time.Minute),
+		},
+	}
+
+	cfg.Storage.SigningSecret = getEnv("STORAGE_SIGNING_SECRET", cfg.JWT.Secret)
+
+	pricing, err := loadPricingConfig()
+	if err != nil {
+		return nil, err
 	}
+	cfg.Pricing = *pricing
 
 	return cfg, nil
 }
 
+// loadPricingConfig reads the delivery fee rules.
+// DELIVERY_ZONE_FEES uses the format "zoneA:zoneB=1.50,zoneA:zoneA=0.50" (pairs are symmetric)
+// and DELIVERY_DISTANCE_BANDS the format "300=0.50,800=1.00" (meters=fee).
+func loadPricingConfig() (*PricingConfig, error) {
+	pricing := &PricingConfig{
+		DeliveryBaseFee:       getEnvAsMinorUnits("DELIVERY_BASE_FEE", 100),
+		ZoneFees:              make(map[string]int64),
+		FreeDeliveryThreshold: getEnvAsMinorUnits("FREE_DELIVERY_THRESHOLD", 0),
+		SmallOrderThreshold:   getEnvAsMinorUnits("SMALL_ORDER_THRESHOLD", 0),
+		SmallOrderSurcharge:   getEnvAsMinorUnits("SMALL_ORDER_SURCHARGE", 0),
+	}
+
+	for _, entry := range splitList(getEnv("DELIVERY_ZONE_FEES", "")) {
+		pair, fee, err := parseKeyValue(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DELIVERY_ZONE_FEES entry %q: %w", entry, err)
+		}
+		zones := strings.SplitN(pair, ":", 2)
+		if len(zones) != 2 {
+			return nil, fmt.Errorf("invalid DELIVERY_ZONE_FEES entry %q: expected zoneA:zoneB=fee", entry)
+		}
+		pricing.ZoneFees[ZonePairKey(zones[0], zones[1])] = fee
+	}
+
+	for _, entry := range splitList(getEnv("DELIVERY_DISTANCE_BANDS", "")) {
+		meters, fee, err := parseKeyValue(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DELIVERY_DISTANCE_BANDS entry %q: %w", entry, err)
+		}
+		maxMeters, err := strconv.ParseFloat(meters, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DELIVERY_DISTANCE_BANDS entry %q: %w", entry, err)
+		}
+		pricing.DistanceBands = append(pricing.DistanceBands, DistanceBand{MaxMeters: maxMeters, Fee: fee})
+	}
+	sort.Slice(pricing.DistanceBands, func(i, j int) bool {
+		return pricing.DistanceBands[i].MaxMeters < pricing.DistanceBands[j].MaxMeters
+	})
+
+	return pricing, nil
+}
+
+// ZonePairKey returns the key of a zone pair in PricingConfig.ZoneFees, independent of direction
+func ZonePairKey(a, b string) string {
+	a = strings.ToLower(strings.TrimSpace(a))
+	b = strings.ToLower(strings.TrimSpace(b))
+	if b < a {
+		a, b = b, a
+	}
+	return a + ":" + b
+}
+
 // GetDSN returns the database connection string
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf(
@@ -167,3 +350,53 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsMinorUnits reads a decimal amount and returns it in minor units
+func getEnvAsMinorUnits(key string, defaultValue int64) int64 {
+	if value, err := parseMinorUnits(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// parseMinorUnits converts a decimal amount such as "1.50" to minor units (150),
+// rounding half away from zero
+func parseMinorUnits(value string) (int64, error) {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(amount * 100)), nil
+}
+
+// splitList splits a comma separated value, skipping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseKeyValue parses a "key=amount" entry, returning the amount in minor units
+func parseKeyValue(entry string) (string, int64, error) {
+	parts := strings.SplitN(entry, "=", 2)
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("expected key=value")
+	}
+	value, err := parseMinorUnits(parts[1])
+	if err != nil {
+		return "", 0, err
+	}
+	return strings.TrimSpace(parts[0]), value, nil
+}
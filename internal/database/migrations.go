@@ -1,8 +1,11 @@
 package database
 
 import (
+	"fmt"
 	"log"
 
+	"gorm.io/gorm"
+
 	"github.com/ruranjo/unientrega/internal/models"
 )
 
@@ -10,12 +13,47 @@ import (
 func Migrate() error {
 	log.Println("Running database migrations...")
 
+	if err := prepareMoneyColumns(); err != nil {
+		return err
+	}
+
+	if err := prepareCartItems(); err != nil {
+		return err
+	}
+
 	// Auto-migrate models
 	err := db.AutoMigrate(
 		&models.User{},
 		&models.PasswordReset{},
+		&models.UserSession{},
+		&models.RefreshToken{},
+		&models.CampusLocation{},
+		&models.DeliveryAddress{},
 		&models.Store{},
+		&models.StoreMember{},
+		&models.StoreInvitation{},
+		&models.StoreOpeningHours{},
+		&models.StoreSpecialHours{},
+		&models.TimeSlotSettings{},
+		&models.TimeSlotBooking{},
 		&models.Product{},
+		&models.ProductVariant{},
+		&models.ProductOptionGroup{},
+		&models.ProductModifier{},
+		&models.StockMovement{},
+		&models.Order{},
+		&models.OrderItem{},
+		&models.OrderItemModifier{},
+		&models.OrderStatusEvent{},
+		&models.CourierAvailability{},
+		&models.DeliveryOffer{},
+		&models.Refund{},
+		&models.IdempotencyKey{},
+		&models.Cart{},
+		&models.CartItem{},
+		&models.PrintPricing{},
+		&models.PrintJob{},
+		&models.AuditLog{},
 		// Add more models here as you create them
 	)
 
@@ -23,6 +61,212 @@ func Migrate() error {
 		return err
 	}
 
+	if err := migrateMoneyColumns(); err != nil {
+		return err
+	}
+
+	if err := migrateStoreOwners(); err != nil {
+		return err
+	}
+
+	if err := migrateOpeningStock(); err != nil {
+		return err
+	}
+
+	if err := migrateProductSearch(); err != nil {
+		return err
+	}
+
 	log.Println("Database migrations completed successfully")
 	return nil
 }
+
+// moneyColumns lists the legacy decimal(10,2) columns replaced by models.Money,
+// keyed by table, with the decimal column name as the prefix of the new columns
+var moneyColumns = map[string][]string{
+	"products":    {"price"},
+	"order_items": {"price"},
+	"orders":      {"subtotal", "delivery_fee", "small_order_fee", "total"},
+}
+
+// prepareMoneyColumns adds the currency columns of legacy decimal amounts before the models
+// are migrated. Currency columns have no default, so adding them to tables with rows needs
+// one; it is dropped again when the models are migrated.
+func prepareMoneyColumns() error {
+	currency := models.NewMoney(0, "")
+	if !currency.IsValidCurrency() {
+		return fmt.Errorf("invalid default currency %q", currency.Currency)
+	}
+
+	migrator := db.Migrator()
+	for table, columns := range moneyColumns {
+		for _, column := range columns {
+			if !migrator.HasColumn(table, column) {
+				continue
+			}
+			statement := fmt.Sprintf(
+				"ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s_currency varchar(3) NOT NULL DEFAULT '%s'",
+				table, column, currency.Currency,
+			)
+			if err := db.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to add %s.%s_currency: %w", table, column, err)
+			}
+		}
+	}
+	return nil
+}
+
+// prepareCartItems keys the items of carts saved before product configurations by their
+// product, as the configuration key of a product without variant or modifiers, and drops
+// the former one item per product index
+func prepareCartItems() error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("cart_items") || migrator.HasColumn("cart_items", "configuration_key") {
+		return nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			`ALTER TABLE cart_items ADD COLUMN configuration_key text`,
+			`UPDATE cart_items SET configuration_key = product_id::text || '/'`,
+			`ALTER TABLE cart_items ALTER COLUMN configuration_key SET NOT NULL`,
+			`DROP INDEX IF EXISTS idx_cart_items_cart_product`,
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate cart items: %w", err)
+	}
+	return nil
+}
+
+// migrateMoneyColumns converts legacy decimal amounts to minor units in the default currency
+// and drops the decimal columns. Amounts are rounded half away from zero, like models.MoneyFromDecimal.
+func migrateMoneyColumns() error {
+	migrator := db.Migrator()
+	for table, columns := range moneyColumns {
+		for _, column := range columns {
+			if !migrator.HasColumn(table, column) {
+				continue
+			}
+
+			log.Printf("Migrating %s.%s to minor units", table, column)
+			err := db.Transaction(func(tx *gorm.DB) error {
+				update := fmt.Sprintf(
+					"UPDATE %s SET %s_amount = ROUND(COALESCE(%s, 0) * 100), %s_currency = ?",
+					table, column, column, column,
+				)
+				if err := tx.Exec(update, models.DefaultCurrency).Error; err != nil {
+					return err
+				}
+				return tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column)).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to migrate %s.%s: %w", table, column, err)
+			}
+		}
+	}
+	return nil
+}
+
+// migrateStoreOwners gives the owner of every store that has no members an owner membership,
+// so stores created before store memberships keep their manager
+func migrateStoreOwners() error {
+	result := db.Exec(`
+		INSERT INTO store_members (id, store_id, user_id, role, created_at, updated_at)
+		SELECT gen_random_uuid(), s.id, s.owner_id, ?, NOW(), NOW()
+		FROM stores s
+		WHERE s.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM store_members m WHERE m.store_id = s.id)`,
+		models.StoreMemberOwner,
+	)
+	if result.Error != nil {
+		return fmt.Errorf("failed to migrate store owners: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Created owner memberships for %d stores", result.RowsAffected)
+	}
+	return nil
+}
+
+// migrateOpeningStock records the stock of products and variants without ledger entries
+// as an opening adjustment, so that stock always equals the sum of its movements
+func migrateOpeningStock() error {
+	products := db.Exec(`
+		INSERT INTO stock_movements (id, product_id, type, quantity, stock_after, reason, created_at)
+		SELECT gen_random_uuid(), p.id, ?, p.stock, p.stock, 'opening balance', NOW()
+		FROM products p
+		WHERE p.stock <> 0
+		AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = p.id AND m.variant_id IS NULL)`,
+		models.StockMovementAdjustment,
+	)
+	if products.Error != nil {
+		return fmt.Errorf("failed to migrate opening stock: %w", products.Error)
+	}
+	variants := db.Exec(`
+		INSERT INTO stock_movements (id, product_id, variant_id, type, quantity, stock_after, reason, created_at)
+		SELECT gen_random_uuid(), v.product_id, v.id, ?, v.stock, v.stock, 'opening balance', NOW()
+		FROM product_variants v
+		WHERE v.stock <> 0
+		AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.variant_id = v.id)`,
+		models.StockMovementAdjustment,
+	)
+	if variants.Error != nil {
+		return fmt.Errorf("failed to migrate opening stock: %w", variants.Error)
+	}
+	if total := products.RowsAffected + variants.RowsAffected; total > 0 {
+		log.Printf("Recorded opening stock movements for %d products and variants", total)
+	}
+	return nil
+}
+
+// productSearchStatements set up full-text product search: accent-insensitive Spanish and
+// English text search configurations, a weighted tsvector generated from name, tags and
+// description, and a trigram index on the name for fuzzy matches
+var productSearchStatements = []string{
+	`CREATE EXTENSION IF NOT EXISTS unaccent`,
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`DO $$
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'spanish_unaccent') THEN
+			CREATE TEXT SEARCH CONFIGURATION spanish_unaccent (COPY = spanish);
+			ALTER TEXT SEARCH CONFIGURATION spanish_unaccent
+				ALTER MAPPING FOR hword, hword_part, word WITH unaccent, spanish_stem;
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'english_unaccent') THEN
+			CREATE TEXT SEARCH CONFIGURATION english_unaccent (COPY = english);
+			ALTER TEXT SEARCH CONFIGURATION english_unaccent
+				ALTER MAPPING FOR hword, hword_part, word WITH unaccent, english_stem;
+		END IF;
+	END
+	$$`,
+	// unaccent() is only stable, indexes need an immutable function
+	`CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text
+		AS $$ SELECT public.unaccent('public.unaccent', $1) $$
+		LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('spanish_unaccent'::regconfig, coalesce(name, '')), 'A') ||
+		setweight(to_tsvector('english_unaccent'::regconfig, coalesce(name, '')), 'A') ||
+		setweight(jsonb_to_tsvector('spanish_unaccent'::regconfig, coalesce(tags, '[]'), '["string"]'), 'B') ||
+		setweight(jsonb_to_tsvector('english_unaccent'::regconfig, coalesce(tags, '[]'), '["string"]'), 'B') ||
+		setweight(to_tsvector('spanish_unaccent'::regconfig, coalesce(description, '')), 'C') ||
+		setweight(to_tsvector('english_unaccent'::regconfig, coalesce(description, '')), 'C')
+	) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (immutable_unaccent(lower(name)) gin_trgm_ops)`,
+}
+
+// migrateProductSearch creates the search column and indexes of products
+func migrateProductSearch() error {
+	for _, statement := range productSearchStatements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to set up product search: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// AddressHandler handles the saved delivery addresses of the current user
+type AddressHandler struct {
+	addressService *services.AddressService
+}
+
+// NewAddressHandler creates a new address handler
+func NewAddressHandler(addressService *services.AddressService) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+	}
+}
+
+// CreateAddress saves a new delivery address
+// @Summary Create delivery address
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.AddressRequest true "Address data"
+// @Success 201 {object} models.DeliveryAddress
+// @Router /api/v1/addresses [post]
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	var req services.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	address, err := h.addressService.CreateAddress(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, address)
+}
+
+// ListAddresses returns the saved delivery addresses of the current user
+// @Summary List delivery addresses
+// @Tags addresses
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.DeliveryAddress
+// @Router /api/v1/addresses [get]
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	addresses, err := h.addressService.ListAddresses(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, addresses)
+}
+
+// UpdateAddress updates a saved delivery address
+// @Summary Update delivery address
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Address ID"
+// @Param request body services.AddressRequest true "Address data"
+// @Success 200 {object} models.DeliveryAddress
+// @Router /api/v1/addresses/{id} [put]
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address ID"})
+		return
+	}
+
+	var req services.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	address, err := h.addressService.UpdateAddress(id, userID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// DeleteAddress deletes a saved delivery address
+// @Summary Delete delivery address
+// @Tags addresses
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Address ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/addresses/{id} [delete]
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	if err := h.addressService.DeleteAddress(id, userID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Address deleted successfully"})
+}
+
+// respondError maps address errors to HTTP responses
+func (h *AddressHandler) respondError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "address not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
 	"github.com/ruranjo/unientrega/internal/services"
 )
 
@@ -37,7 +38,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.Register(&req)
+	device := services.DeviceInfo{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}
+	response, err := h.authService.Register(&req, device)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -61,7 +63,8 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.Login(&req)
+	device := services.DeviceInfo{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}
+	response, err := h.authService.Login(&req, device)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -72,11 +75,12 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 // RefreshToken handles token refresh
 // @Summary Refresh access token
+// @Description Refresh tokens are single use: the response carries the refresh token to use next time. Presenting a used refresh token again revokes its session.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body map[string]string true "Refresh token"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} services.TokenPair
 // @Router /api/v1/auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req struct {
@@ -88,13 +92,14 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	accessToken, err := h.authService.RefreshToken(req.RefreshToken)
+	device := services.DeviceInfo{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}
+	tokens, err := h.authService.RefreshToken(req.RefreshToken, device)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"access_token": accessToken})
+	c.JSON(http.StatusOK, tokens)
 }
 
 // GetMe returns the current authenticated user
@@ -120,21 +125,173 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-// Logout handles user logout (placeholder for token blacklist)
+// Logout ends the session of the access token
 // @Summary Logout user
+// @Description Revokes the session, so its refresh token can no longer be used. The access token stays valid until it expires a few minutes later.
 // @Tags auth
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} map[string]string
 // @Router /api/v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	// In a production app, you would:
-	// 1. Add the token to a blacklist (Redis)
-	// 2. Or invalidate the refresh token in database
-	// For now, just return success
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	sessionIDStr, _ := c.Get("session_id")
+	sessionID := sessionIDStr.(uuid.UUID)
+
+	if err := h.authService.Logout(userID, sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
+// ListSessions returns the devices the current user is signed in on
+// @Summary List my sessions
+// @Description Lists the active sessions of the current user with their device details. The session of the access token is marked current.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	sessionIDStr, _ := c.Get("session_id")
+	sessionID := sessionIDStr.(uuid.UUID)
+
+	sessions, err := h.authService.ListSessions(userID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeSession signs the current user out of one of their sessions
+// @Summary Revoke my session
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	if err := h.authService.RevokeSession(userID, sessionID, models.SessionLogout); err != nil {
+		h.respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// LogoutEverywhere signs the current user out of all their sessions
+// @Summary Log out everywhere
+// @Description Revokes every session of the current user, including the one of the access token.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/sessions [delete]
+func (h *AuthHandler) LogoutEverywhere(c *gin.Context) {
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	revoked, err := h.authService.RevokeAllSessions(userID, models.SessionLogout)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions", "revoked": revoked})
+}
+
+// ListUserSessions returns the active sessions of any user
+// @Summary List user sessions
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/{id}/sessions [get]
+func (h *AuthHandler) ListUserSessions(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(userID, uuid.Nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeUserSession ends a session of any user
+// @Summary Revoke user session
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param session_id path string true "Session ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/users/{id}/sessions/{session_id} [delete]
+func (h *AuthHandler) RevokeUserSession(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, sessionID, models.SessionAdminRevoke); err != nil {
+		h.respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeUserSessions signs any user out of all their sessions, e.g. when the account is compromised
+// @Summary Revoke all user sessions
+// @Description Revokes every session of the user. Access tokens already issued stay valid until they expire a few minutes later.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/{id}/sessions [delete]
+func (h *AuthHandler) RevokeUserSessions(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.authService.RevokeAllSessions(userID, models.SessionAdminRevoke)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All sessions of the user revoked", "revoked": revoked})
+}
+
 // RequestPasswordReset handles password reset requests
 // @Summary Request password reset
 // @Tags auth
@@ -224,3 +381,26 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
 }
+
+// parseUserID reads the user of a user route and checks that it exists
+func (h *AuthHandler) parseUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	if _, err := h.userService.GetUserByID(userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// respondSessionError maps session errors to HTTP responses
+func (h *AuthHandler) respondSessionError(c *gin.Context, err error) {
+	if err.Error() == "session not found" {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// CartHandler handles the shopping carts of the current user
+type CartHandler struct {
+	cartService *services.CartService
+}
+
+// NewCartHandler creates a new cart handler
+func NewCartHandler(cartService *services.CartService) *CartHandler {
+	return &CartHandler{
+		cartService: cartService,
+	}
+}
+
+// ListCarts returns the active carts of the current user
+// @Summary List carts
+// @Tags cart
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} services.CartView
+// @Router /api/v1/cart [get]
+func (h *CartHandler) ListCarts(c *gin.Context) {
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	carts, err := h.cartService.ListCarts(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, carts)
+}
+
+// GetCart returns the cart of the current user at a store with live prices and stock
+// @Summary Get cart
+// @Tags cart
+// @Produce json
+// @Security BearerAuth
+// @Param store_id path string true "Store ID"
+// @Success 200 {object} services.CartView
+// @Router /api/v1/cart/{store_id} [get]
+func (h *CartHandler) GetCart(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("store_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	cart, err := h.cartService.GetCart(userID, storeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// AddItem adds a product to the cart
+// @Summary Add cart item
+// @Description Adds the quantity to the item with the same product, variant and modifiers, if any
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param store_id path string true "Store ID"
+// @Param request body services.CartItemRequest true "Product, variant, modifiers and quantity"
+// @Success 200 {object} services.CartView
+// @Failure 409 {object} map[string]interface{} "Insufficient stock"
+// @Router /api/v1/cart/{store_id}/items [post]
+func (h *CartHandler) AddItem(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("store_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	var req services.CartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	cart, err := h.cartService.AddItem(userID, storeID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// UpdateItem changes the quantity of an item in the cart
+// @Summary Update cart item
+// @Description A quantity of 0 removes the item from the cart
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param store_id path string true "Store ID"
+// @Param item_id path string true "Cart item ID"
+// @Param request body services.UpdateCartItemRequest true "New quantity"
+// @Success 200 {object} services.CartView
+// @Failure 409 {object} map[string]interface{} "Insufficient stock"
+// @Router /api/v1/cart/{store_id}/items/{item_id} [put]
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	storeID, itemID, ok := parseCartItemParams(c)
+	if !ok {
+		return
+	}
+
+	var req services.UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	cart, err := h.cartService.UpdateItem(userID, storeID, itemID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// RemoveItem removes an item from the cart
+// @Summary Remove cart item
+// @Tags cart
+// @Produce json
+// @Security BearerAuth
+// @Param store_id path string true "Store ID"
+// @Param item_id path string true "Cart item ID"
+// @Success 200 {object} services.CartView
+// @Router /api/v1/cart/{store_id}/items/{item_id} [delete]
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	storeID, itemID, ok := parseCartItemParams(c)
+	if !ok {
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	cart, err := h.cartService.RemoveItem(userID, storeID, itemID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// ClearCart empties the cart of the current user at a store
+// @Summary Clear cart
+// @Tags cart
+// @Produce json
+// @Security BearerAuth
+// @Param store_id path string true "Store ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/cart/{store_id} [delete]
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("store_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	if err := h.cartService.ClearCart(userID, storeID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cart cleared successfully"})
+}
+
+// Checkout places an order with the contents of the cart
+// @Summary Checkout cart
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param store_id path string true "Store ID"
+// @Param Idempotency-Key header string false "Client generated key; retries with the same key replay the first response"
+// @Param request body services.CheckoutRequest true "Destination and optional expected subtotal"
+// @Success 201 {object} models.Order
+// @Failure 409 {object} map[string]interface{} "Insufficient stock, prices changed, store closed or time slot full"
+// @Router /api/v1/cart/{store_id}/checkout [post]
+func (h *CartHandler) Checkout(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("store_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	var req services.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	order, err := h.cartService.Checkout(userID, role, storeID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// respondError maps cart service errors to HTTP responses
+func (h *CartHandler) respondError(c *gin.Context, err error) {
+	var stockErr *services.InsufficientStockError
+	var closedErr *services.StoreClosedError
+	switch {
+	case errors.As(err, &stockErr):
+		c.JSON(http.StatusConflict, gin.H{"error": stockErr.Error(), "items": stockErr.Items})
+	case errors.As(err, &closedErr):
+		c.JSON(http.StatusConflict, gin.H{"error": closedErr.Error(), "next_opening_at": closedErr.NextOpeningAt})
+	case errors.Is(err, services.ErrCartChanged) || errors.Is(err, services.ErrSlotFull):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err.Error() == "product not in cart" || err.Error() == "product not found" || err.Error() == "store not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// parseCartItemParams reads the store and item IDs of a cart item route
+func parseCartItemParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	storeID, err := uuid.Parse(c.Param("store_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cart item ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return storeID, itemID, true
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// DeliveryHandler handles courier delivery requests
+type DeliveryHandler struct {
+	deliveryService *services.DeliveryService
+}
+
+// NewDeliveryHandler creates a new delivery handler
+func NewDeliveryHandler(deliveryService *services.DeliveryService) *DeliveryHandler {
+	return &DeliveryHandler{
+		deliveryService: deliveryService,
+	}
+}
+
+// ListAvailable returns ready orders waiting for a courier
+// @Summary List orders awaiting delivery
+// @Tags deliveries
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Limit" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Param cursor query string false "Cursor from next_cursor of the previous page; replaces offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/deliveries/available [get]
+func (h *DeliveryHandler) ListAvailable(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	cursor := c.Query("cursor")
+
+	orders, next, total, err := h.deliveryService.ListAvailable(limit, offset, cursor)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("orders", orders, total, limit, offset, cursor, next))
+}
+
+// ListMyDeliveries returns the deliveries of the current courier
+// @Summary List my deliveries
+// @Tags deliveries
+// @Produce json
+// @Security BearerAuth
+// @Param scope query string false "active or past" default(active)
+// @Param limit query int false "Limit" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Param cursor query string false "Cursor from next_cursor of the previous page; replaces offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/deliveries [get]
+func (h *DeliveryHandler) ListMyDeliveries(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	cursor := c.Query("cursor")
+	scope := c.DefaultQuery("scope", services.DeliveryScopeActive)
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	orders, next, total, err := h.deliveryService.ListCourierDeliveries(userID, scope, limit, offset, cursor)
+	if err != nil {
+		if err.Error() == "invalid scope" || err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("orders", orders, total, limit, offset, cursor, next))
+}
+
+// ClaimOrder assigns an order to the current courier
+// @Summary Claim an order for delivery
+// @Tags deliveries
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} models.Order
+// @Failure 409 {object} map[string]string "Already claimed or not ready"
+// @Router /api/v1/deliveries/{id}/claim [post]
+func (h *DeliveryHandler) ClaimOrder(c *gin.Context) {
+	h.handleAction(c, func(orderID, userID uuid.UUID, role models.Role) (*models.Order, error) {
+		return h.deliveryService.ClaimOrder(orderID, userID)
+	})
+}
+
+// ReleaseOrder returns a claimed order to the pool of available deliveries
+// @Summary Release a claimed order
+// @Tags deliveries
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} models.Order
+// @Router /api/v1/deliveries/{id}/release [post]
+func (h *DeliveryHandler) ReleaseOrder(c *gin.Context) {
+	h.handleAction(c, func(orderID, userID uuid.UUID, role models.Role) (*models.Order, error) {
+		return h.deliveryService.ReleaseOrder(orderID, userID)
+	})
+}
+
+// MarkPickedUp marks an order as collected by the courier
+// @Summary Mark order picked up
+// @Tags deliveries
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} models.Order
+// @Router /api/v1/deliveries/{id}/pickup [post]
+func (h *DeliveryHandler) MarkPickedUp(c *gin.Context) {
+	h.handleAction(c, h.deliveryService.MarkPickedUp)
+}
+
+// MarkDelivered marks an order as delivered to the customer
+// @Summary Mark order delivered
+// @Tags deliveries
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} models.Order
+// @Router /api/v1/deliveries/{id}/deliver [post]
+func (h *DeliveryHandler) MarkDelivered(c *gin.Context) {
+	h.handleAction(c, h.deliveryService.MarkDelivered)
+}
+
+// handleAction parses the order ID and current user, runs a delivery action and writes the response
+func (h *DeliveryHandler) handleAction(c *gin.Context, action func(orderID, userID uuid.UUID, role models.Role) (*models.Order, error)) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	order, err := action(id, userID, role)
+	if err != nil {
+		switch {
+		case err.Error() == "permission denied":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err.Error() == "order not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		case errors.Is(err, services.ErrOrderAlreadyClaimed),
+			errors.Is(err, services.ErrOrderNotReady),
+			errors.Is(err, services.ErrInvalidTransition),
+			errors.Is(err, services.ErrStatusConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// DispatchHandler handles courier availability and delivery offer requests
+type DispatchHandler struct {
+	dispatchService *services.DispatchService
+}
+
+// NewDispatchHandler creates a new dispatch handler
+func NewDispatchHandler(dispatchService *services.DispatchService) *DispatchHandler {
+	return &DispatchHandler{
+		dispatchService: dispatchService,
+	}
+}
+
+// GetAvailability returns the availability of the current courier
+// @Summary Get courier availability
+// @Tags deliveries
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.CourierAvailability
+// @Router /api/v1/deliveries/availability [get]
+func (h *DispatchHandler) GetAvailability(c *gin.Context) {
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	availability, err := h.dispatchService.GetAvailability(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+// SetAvailability updates the availability of the current courier
+// @Summary Set courier availability
+// @Tags deliveries
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.SetAvailabilityRequest true "Status (online, offline, busy) and campus zone"
+// @Success 200 {object} models.CourierAvailability
+// @Router /api/v1/deliveries/availability [put]
+func (h *DispatchHandler) SetAvailability(c *gin.Context) {
+	var req services.SetAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	availability, err := h.dispatchService.SetAvailability(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+// ListOffers returns the open delivery offers of the current courier
+// @Summary List delivery offers
+// @Tags deliveries
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.DeliveryOffer
+// @Router /api/v1/deliveries/offers [get]
+func (h *DispatchHandler) ListOffers(c *gin.Context) {
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	offers, err := h.dispatchService.ListOffers(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
+
+// AcceptOffer accepts a delivery offer and assigns the order to the current courier
+// @Summary Accept delivery offer
+// @Tags deliveries
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Offer ID"
+// @Success 200 {object} models.Order
+// @Failure 409 {object} map[string]string "Offer expired or order already claimed"
+// @Router /api/v1/deliveries/offers/{id}/accept [post]
+func (h *DispatchHandler) AcceptOffer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	order, err := h.dispatchService.AcceptOffer(id, userID)
+	if err != nil {
+		h.respondOfferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// DeclineOffer declines a delivery offer so the order goes to the next courier
+// @Summary Decline delivery offer
+// @Tags deliveries
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Offer ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/deliveries/offers/{id}/decline [post]
+func (h *DispatchHandler) DeclineOffer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	if err := h.dispatchService.DeclineOffer(id, userID); err != nil {
+		h.respondOfferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Offer declined"})
+}
+
+// respondOfferError maps offer errors to HTTP responses
+func (h *DispatchHandler) respondOfferError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "offer not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+	case errors.Is(err, services.ErrOfferExpired),
+		errors.Is(err, services.ErrOfferClosed),
+		errors.Is(err, services.ErrOrderAlreadyClaimed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// LocationHandler handles campus location catalog requests
+type LocationHandler struct {
+	locationService *services.LocationService
+}
+
+// NewLocationHandler creates a new location handler
+func NewLocationHandler(locationService *services.LocationService) *LocationHandler {
+	return &LocationHandler{
+		locationService: locationService,
+	}
+}
+
+// CreateLocation adds a location to the campus catalog
+// @Summary Create campus location
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CampusLocation true "Location data"
+// @Success 201 {object} models.CampusLocation
+// @Router /api/v1/locations [post]
+func (h *LocationHandler) CreateLocation(c *gin.Context) {
+	var location models.CampusLocation
+
+	if err := c.ShouldBindJSON(&location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.locationService.CreateLocation(&location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, location)
+}
+
+// GetLocation returns a campus location by ID
+// @Summary Get campus location by ID
+// @Tags locations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Location ID"
+// @Success 200 {object} models.CampusLocation
+// @Router /api/v1/locations/{id} [get]
+func (h *LocationHandler) GetLocation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
+		return
+	}
+
+	location, err := h.locationService.GetLocationByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Location not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+// ListLocations returns the campus location catalog
+// @Summary List campus locations
+// @Tags locations
+// @Produce json
+// @Security BearerAuth
+// @Param building query string false "Filter by building"
+// @Param active_only query bool false "Show only active locations" default(true)
+// @Success 200 {array} models.CampusLocation
+// @Router /api/v1/locations [get]
+func (h *LocationHandler) ListLocations(c *gin.Context) {
+	building := c.Query("building")
+	activeOnlyStr := c.DefaultQuery("active_only", "true")
+	activeOnly := activeOnlyStr == "true" || activeOnlyStr == "1"
+
+	locations, err := h.locationService.ListLocations(building, activeOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, locations)
+}
+
+// UpdateLocation updates a campus location
+// @Summary Update campus location
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Location ID"
+// @Param request body models.CampusLocation true "Location data"
+// @Success 200 {object} models.CampusLocation
+// @Router /api/v1/locations/{id} [put]
+func (h *LocationHandler) UpdateLocation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
+		return
+	}
+
+	var updateData models.CampusLocation
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get existing location
+	location, err := h.locationService.GetLocationByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Location not found"})
+		return
+	}
+
+	// Update fields
+	location.Name = updateData.Name
+	location.Building = updateData.Building
+	location.Floor = updateData.Floor
+	location.Room = updateData.Room
+	location.Zone = updateData.Zone
+	location.Description = updateData.Description
+	location.Latitude = updateData.Latitude
+	location.Longitude = updateData.Longitude
+	location.IsActive = updateData.IsActive
+
+	err = h.locationService.UpdateLocation(location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+// DeleteLocation deletes a campus location
+// @Summary Delete campus location
+// @Tags locations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Location ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/locations/{id} [delete]
+func (h *LocationHandler) DeleteLocation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location ID"})
+		return
+	}
+
+	err = h.locationService.DeleteLocation(id)
+	if err != nil {
+		if err.Error() == "location not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Location not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Location deleted successfully"})
+}
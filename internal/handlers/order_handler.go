@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/ruranjo/unientrega/internal/models"
 	"github.com/ruranjo/unientrega/internal/services"
+	"github.com/ruranjo/unientrega/internal/utils"
 )
 
 // OrderHandler handles order requests
@@ -28,8 +30,11 @@ func NewOrderHandler(orderService *services.OrderService) *OrderHandler {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param Idempotency-Key header string false "Client generated key; retries with the same key replay the first response"
 // @Param request body services.CreateOrderRequest true "Order data"
 // @Success 201 {object} models.Order
+// @Failure 409 {object} map[string]interface{} "Insufficient stock, store closed, time slot full or request still in progress"
+// @Failure 422 {object} map[string]string "Idempotency key reused with a different request"
 // @Router /api/v1/orders [post]
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	var req services.CreateOrderRequest
@@ -40,9 +45,25 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 
 	userIDStr, _ := c.Get("user_id")
 	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
 
-	order, err := h.orderService.CreateOrder(userID, &req)
+	order, err := h.orderService.CreateOrder(userID, role, &req)
 	if err != nil {
+		var stockErr *services.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			c.JSON(http.StatusConflict, gin.H{"error": stockErr.Error(), "items": stockErr.Items})
+			return
+		}
+		var closedErr *services.StoreClosedError
+		if errors.As(err, &closedErr) {
+			c.JSON(http.StatusConflict, gin.H{"error": closedErr.Error(), "next_opening_at": closedErr.NextOpeningAt})
+			return
+		}
+		if errors.Is(err, services.ErrSlotFull) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -86,77 +107,71 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 
 // ListOrders returns a list of orders
 // @Summary List orders
+// @Description Filters: field=value or field[op]=value with op eq, ne, gt, gte, lt, lte, in (comma separated values) or contains. Sort: sort=-created_at,name (minus for descending; cursors only follow the default order). Fields: fields=id,name. Filter and sort fields: status, payment_status, user_id, delivery_person_id, subtotal and total (minor units), pickup_at_store, scheduled_for, created_at, updated_at.
 // @Tags orders
 // @Produce json
 // @Security BearerAuth
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
-// @Param store_id query string false "Filter by store ID (for store owners)"
+// @Param cursor query string false "Cursor from next_cursor of the previous page; replaces offset"
+// @Param store_id query string false "Filter by store ID (for store members)"
+// @Param sort query string false "Sort fields"
+// @Param fields query string false "Fields to return"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/orders [get]
 func (h *OrderHandler) ListOrders(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	cursor := c.Query("cursor")
 	storeIDStr := c.Query("store_id")
 
+	list, err := utils.ParseListQuery(c.Request.URL.Query(), "store_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	userIDStr, _ := c.Get("user_id")
 	userID := userIDStr.(uuid.UUID)
 	roleStr, _ := c.Get("user_role")
 	role := roleStr.(models.Role)
 
 	var orders []models.Order
+	var next string
 	var total int64
-	var err error
 
-	// If store_id is provided, check if user is owner or superuser
-	if storeIDStr != "" {
-		storeID, err := uuid.Parse(storeIDStr)
-		if err != nil {
+	// With store_id, store members and superusers list the orders of the store.
+	// Clients can't list all orders of a store (privacy), they always see their own.
+	if storeIDStr != "" && role != models.RoleClient {
+		storeID, parseErr := uuid.Parse(storeIDStr)
+		if parseErr != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
 			return
 		}
+		orders, next, total, err = h.orderService.ListStoreOrders(storeID, userID, role, limit, offset, cursor, list)
+	} else {
+		orders, next, total, err = h.orderService.ListUserOrders(userID, limit, offset, cursor, list)
+	}
 
-		// In a real app, we'd check if userID owns storeID here or in service
-		// For simplicity, we'll let the service/repo return the list, but we should verify ownership
-		// Since we don't have a quick "IsStoreOwner" check here without DB call,
-		// let's assume if they ask for a store, they are the owner or admin.
-		// A better way is to have the service enforce this.
-		// For now, let's just list by store if they ask, but ideally we should restrict.
-		// Actually, let's restrict: if not superuser, they can only list their own orders OR orders for their store.
-		// But "ListStoreOrders" in service doesn't check ownership.
-		// Let's stick to: Clients see their own orders. Store Owners see their store's orders.
-
-		// If user is client, they can only see their own orders, ignoring store_id filter for security?
-		// Or maybe they want to see "My orders from Store X"?
-
-		// Let's implement:
-		// 1. If store_id provided: List orders for that store (Require Owner/Admin)
-		// 2. If no store_id: List orders for the current user (Client view)
-
-		if role == models.RoleClient {
-			// Clients can't list all orders of a store (privacy)
-			// They can only see their own.
-			orders, total, err = h.orderService.ListUserOrders(userID, limit, offset)
-		} else {
-			// Store/Admin can list store orders
-			orders, total, err = h.orderService.ListStoreOrders(storeID, limit, offset)
+	if err != nil {
+		switch err.Error() {
+		case "permission denied":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case "store not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			respondListError(c, err)
 		}
-	} else {
-		// No store_id, list user's own orders
-		orders, total, err = h.orderService.ListUserOrders(userID, limit, offset)
+		return
 	}
 
+	items, err := utils.SelectFields(orders, list.Fields)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondListError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"orders": orders,
-		"total":  total,
-		"limit":  limit,
-		"offset": offset,
-	})
+	c.JSON(http.StatusOK, pageResponse("orders", items, total, limit, offset, cursor, next))
 }
 
 // UpdateOrderStatus updates the status of an order
@@ -166,8 +181,9 @@ func (h *OrderHandler) ListOrders(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Order ID"
-// @Param request body map[string]string true "Status"
+// @Param request body map[string]string true "Status and optional reason"
 // @Success 200 {object} models.Order
+// @Failure 409 {object} map[string]string "Transition not allowed"
 // @Router /api/v1/orders/{id}/status [patch]
 func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	idStr := c.Param("id")
@@ -179,6 +195,7 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 
 	var req struct {
 		Status string `json:"status" binding:"required"`
+		Reason string `json:"reason"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -191,12 +208,14 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	roleStr, _ := c.Get("user_role")
 	role := roleStr.(models.Role)
 
-	order, err := h.orderService.UpdateOrderStatus(id, models.OrderStatus(req.Status), userID, role)
+	order, err := h.orderService.UpdateOrderStatus(id, models.OrderStatus(req.Status), req.Reason, userID, role)
 	if err != nil {
 		if err.Error() == "permission denied" {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		} else if err.Error() == "invalid status" {
+		} else if err.Error() == "invalid status" || errors.Is(err, services.ErrUseCancelOrder) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if errors.Is(err, services.ErrInvalidTransition) || errors.Is(err, services.ErrStatusConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
@@ -205,3 +224,87 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, order)
 }
+
+// GetOrderTimeline returns the status history of an order
+// @Summary Get order timeline
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {array} models.OrderStatusEvent
+// @Router /api/v1/orders/{id}/timeline [get]
+func (h *OrderHandler) GetOrderTimeline(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	events, err := h.orderService.GetOrderTimeline(id, userID, role)
+	if err != nil {
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// CancelOrder cancels an order, restoring stock and refunding it if it was paid
+// @Summary Cancel order
+// @Description Customers may cancel pending orders; store staff may cancel orders until they are completed.
+// @Description reason_code is one of customer_request, ordered_by_mistake, taking_too_long, out_of_stock,
+// @Description store_closed, unable_to_deliver, payment_issue or other (requires a note).
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param request body services.CancelOrderRequest true "Reason code and optional note"
+// @Success 200 {object} models.Order
+// @Failure 409 {object} map[string]string "Order can no longer be cancelled"
+// @Router /api/v1/orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var req services.CancelOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	order, err := h.orderService.CancelOrder(id, &req, userID, role)
+	if err != nil {
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else if err.Error() == "order not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		} else if errors.Is(err, services.ErrInvalidTransition) || errors.Is(err, services.ErrStatusConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ruranjo/unientrega/internal/utils"
+)
+
+// pageResponse builds the body of a list page. next_cursor continues the list after this page
+// and is null on the last one; the total and offset are only given in offset mode, when no
+// cursor was sent.
+func pageResponse(key string, items interface{}, total int64, limit, offset int, cursor, next string) gin.H {
+	response := gin.H{
+		key:           items,
+		"limit":       limit,
+		"next_cursor": nil,
+	}
+	if next != "" {
+		response["next_cursor"] = next
+	}
+	if cursor == "" {
+		response["total"] = total
+		response["offset"] = offset
+	}
+	return response
+}
+
+// respondListError maps the errors of listing a page to HTTP responses: invalid cursors and
+// list queries are client errors, anything else a server error
+func respondListError(c *gin.Context, err error) {
+	if err.Error() == "invalid cursor" || errors.Is(err, utils.ErrInvalidListQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// PrintJobHandler handles document uploads for copy center stores
+type PrintJobHandler struct {
+	printJobService *services.PrintJobService
+	maxUploadSize   int64
+}
+
+// NewPrintJobHandler creates a new print job handler; documents may be at most maxUploadSize bytes
+func NewPrintJobHandler(printJobService *services.PrintJobService, maxUploadSize int64) *PrintJobHandler {
+	return &PrintJobHandler{
+		printJobService: printJobService,
+		maxUploadSize:   maxUploadSize,
+	}
+}
+
+// CreatePrintJob uploads a PDF document to be printed
+// @Summary Upload print job
+// @Description Uploads a PDF, counts its pages and prices it with the store rates.
+// @Description Add the returned job ID to print_job_ids when creating the order.
+// @Tags print-jobs
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param store_id formData string true "Copy center store ID"
+// @Param file formData file true "PDF document"
+// @Param color_mode formData string false "bw (default) or color"
+// @Param duplex formData bool false "Print on both sides"
+// @Param copies formData int false "Number of copies" default(1)
+// @Param page_ranges formData string false "Pages to print, e.g. 1-3,5,8-"
+// @Param binding formData string false "none (default), staple, spiral or thermal"
+// @Param notes formData string false "Notes for the copy center"
+// @Success 201 {object} models.PrintJob
+// @Failure 413 {object} map[string]string
+// @Router /api/v1/print-jobs [post]
+func (h *PrintJobHandler) CreatePrintJob(c *gin.Context) {
+	if !parseUpload(c, h.maxUploadSize) {
+		return
+	}
+
+	storeID, err := uuid.Parse(c.PostForm("store_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	var options services.PrintOptions
+	if err := c.ShouldBind(&options); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A PDF file is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	job, err := h.printJobService.CreatePrintJob(userID, storeID, fileHeader.Filename, file, &options)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, job)
+}
+
+// ListPrintJobs returns the print jobs of the current user
+// @Summary List print jobs
+// @Tags print-jobs
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Limit" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Param cursor query string false "Cursor from next_cursor of the previous page; replaces offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/print-jobs [get]
+func (h *PrintJobHandler) ListPrintJobs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	cursor := c.Query("cursor")
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	jobs, next, total, err := h.printJobService.ListPrintJobs(userID, limit, offset, cursor)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("print_jobs", jobs, total, limit, offset, cursor, next))
+}
+
+// GetPrintJob returns a print job
+// @Summary Get print job
+// @Tags print-jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Print job ID"
+// @Success 200 {object} models.PrintJob
+// @Router /api/v1/print-jobs/{id} [get]
+func (h *PrintJobHandler) GetPrintJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid print job ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	job, err := h.printJobService.GetPrintJob(id, userID, role)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// UpdatePrintJob changes the print options of a job that was not ordered yet
+// @Summary Update print job options
+// @Tags print-jobs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Print job ID"
+// @Param request body services.PrintOptions true "Print options"
+// @Success 200 {object} models.PrintJob
+// @Failure 409 {object} map[string]string "Print job already ordered"
+// @Router /api/v1/print-jobs/{id} [put]
+func (h *PrintJobHandler) UpdatePrintJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid print job ID"})
+		return
+	}
+
+	var options services.PrintOptions
+	if err := c.ShouldBindJSON(&options); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	job, err := h.printJobService.UpdatePrintJob(id, userID, &options)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// DeletePrintJob removes a print job that was not ordered yet
+// @Summary Delete print job
+// @Tags print-jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Print job ID"
+// @Success 200 {object} map[string]string
+// @Failure 409 {object} map[string]string "Print job already ordered"
+// @Router /api/v1/print-jobs/{id} [delete]
+func (h *PrintJobHandler) DeletePrintJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid print job ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	if err := h.printJobService.DeletePrintJob(id, userID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Print job deleted successfully"})
+}
+
+// GetPricing returns the printing rates of a store
+// @Summary Get store print pricing
+// @Tags print-jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Success 200 {object} models.PrintPricing
+// @Router /api/v1/stores/{id}/print-pricing [get]
+func (h *PrintJobHandler) GetPricing(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	pricing, err := h.printJobService.GetPricing(storeID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pricing)
+}
+
+// SetPricing configures the printing rates of a store
+// @Summary Set store print pricing
+// @Tags print-jobs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param request body services.PrintPricingRequest true "Rates per page side and binding prices per copy"
+// @Success 200 {object} models.PrintPricing
+// @Router /api/v1/stores/{id}/print-pricing [put]
+func (h *PrintJobHandler) SetPricing(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	var req services.PrintPricingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	pricing, err := h.printJobService.SetPricing(storeID, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pricing)
+}
+
+// respondError maps print job service errors to HTTP responses
+func (h *PrintJobHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "print job not found" || err.Error() == "store not found" || errors.Is(err, services.ErrPrintingNotOffered):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPrintJobOrdered):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// PrintQueueHandler handles the print queue of copy center stores
+type PrintQueueHandler struct {
+	printQueueService *services.PrintQueueService
+}
+
+// NewPrintQueueHandler creates a new print queue handler
+func NewPrintQueueHandler(printQueueService *services.PrintQueueService) *PrintQueueHandler {
+	return &PrintQueueHandler{
+		printQueueService: printQueueService,
+	}
+}
+
+// ListQueue returns the ordered print jobs of a store
+// @Summary Get store print queue
+// @Description Lists the ordered print jobs of a store, the earliest promised first.
+// @Description By default shows queued, printing and problem jobs.
+// @Tags print-queue
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param status query string false "Comma separated statuses: queued, printing, printed, problem, cancelled"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/stores/{id}/print-queue [get]
+func (h *PrintQueueHandler) ListQueue(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	var statuses []models.PrintJobStatus
+	if status := c.Query("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			statuses = append(statuses, models.PrintJobStatus(strings.TrimSpace(s)))
+		}
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	jobs, total, err := h.printQueueService.ListQueue(storeID, userID, role, statuses, limit, offset)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"print_jobs": jobs,
+		"total":      total,
+		"limit":      limit,
+		"offset":     offset,
+	})
+}
+
+// StartJob marks a print job as being printed
+// @Summary Start print job
+// @Description Moves a queued job, or one whose problem was solved, to printing.
+// @Description A pending or confirmed order moves to preparing.
+// @Tags print-queue
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param job_id path string true "Print job ID"
+// @Success 200 {object} models.PrintJob
+// @Failure 409 {object} map[string]string "Job status does not allow this action"
+// @Router /api/v1/stores/{id}/print-queue/{job_id}/start [post]
+func (h *PrintQueueHandler) StartJob(c *gin.Context) {
+	h.handleAction(c, h.printQueueService.StartJob)
+}
+
+// MarkPrinted marks a print job as printed
+// @Summary Mark print job printed
+// @Description When every print job of an order without other items is printed, the order becomes ready.
+// @Tags print-queue
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param job_id path string true "Print job ID"
+// @Success 200 {object} models.PrintJob
+// @Failure 409 {object} map[string]string "Job status does not allow this action"
+// @Router /api/v1/stores/{id}/print-queue/{job_id}/printed [post]
+func (h *PrintQueueHandler) MarkPrinted(c *gin.Context) {
+	h.handleAction(c, h.printQueueService.MarkPrinted)
+}
+
+// ReportProblem flags a print job that cannot be printed
+// @Summary Report print job problem
+// @Tags print-queue
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param job_id path string true "Print job ID"
+// @Param request body services.PrintProblemRequest true "Reason code (corrupt_file, out_of_toner, out_of_paper, printer_error, other) and note"
+// @Success 200 {object} models.PrintJob
+// @Failure 409 {object} map[string]string "Job status does not allow this action"
+// @Router /api/v1/stores/{id}/print-queue/{job_id}/problem [post]
+func (h *PrintQueueHandler) ReportProblem(c *gin.Context) {
+	var req services.PrintProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.handleAction(c, func(storeID, jobID, userID uuid.UUID, role models.Role) (*models.PrintJob, error) {
+		return h.printQueueService.ReportProblem(storeID, jobID, userID, role, &req)
+	})
+}
+
+// CreateDownloadURL returns a short-lived signed link to the original document
+// @Summary Get print job download URL
+// @Tags print-queue
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param job_id path string true "Print job ID"
+// @Success 200 {object} services.DownloadURL
+// @Router /api/v1/stores/{id}/print-queue/{job_id}/download-url [post]
+func (h *PrintQueueHandler) CreateDownloadURL(c *gin.Context) {
+	storeID, jobID, ok := parsePrintQueueParams(c)
+	if !ok {
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	downloadURL, err := h.printQueueService.CreateDownloadURL(storeID, jobID, userID, role)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, downloadURL)
+}
+
+// Download streams the original document of a print job
+// @Summary Download print job document
+// @Description Requires the signed query parameters returned by the download-url action
+// @Tags print-queue
+// @Produce application/pdf
+// @Param id path string true "Print job ID"
+// @Param expires query int true "Expiration (unix seconds)"
+// @Param signature query string true "URL signature"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]string "Invalid or expired link"
+// @Router /api/v1/print-jobs/{id}/download [get]
+func (h *PrintQueueHandler) Download(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid print job ID"})
+		return
+	}
+
+	job, document, err := h.printQueueService.OpenDocument(id, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	defer document.Close()
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": job.FileName}))
+	c.Header("Cache-Control", "private, no-store")
+	c.DataFromReader(http.StatusOK, job.FileSize, "application/pdf", document, nil)
+}
+
+// handleAction runs a print queue action on the job in the route
+func (h *PrintQueueHandler) handleAction(c *gin.Context, action func(storeID, jobID, userID uuid.UUID, role models.Role) (*models.PrintJob, error)) {
+	storeID, jobID, ok := parsePrintQueueParams(c)
+	if !ok {
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	job, err := action(storeID, jobID, userID, role)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// respondError maps print queue service errors to HTTP responses
+func (h *PrintQueueHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "permission denied" || errors.Is(err, services.ErrInvalidDownloadSignature):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "print job not found" || err.Error() == "store not found" || err.Error() == "print job document not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPrintJobConflict) || errors.Is(err, services.ErrInvalidTransition) || errors.Is(err, services.ErrStatusConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// parsePrintQueueParams reads the store and print job IDs of a print queue route
+func parsePrintQueueParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	jobID, err := uuid.Parse(c.Param("job_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid print job ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return storeID, jobID, true
+}
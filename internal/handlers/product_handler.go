@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/ruranjo/unientrega/internal/models"
 	"github.com/ruranjo/unientrega/internal/services"
+	"github.com/ruranjo/unientrega/internal/utils"
 )
 
 // ProductHandler handles product management requests
@@ -39,9 +40,14 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
-	err := h.productService.CreateProduct(&product)
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	err := h.productService.CreateProduct(&product, userID, role)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.respondError(c, err)
 		return
 	}
 
@@ -75,63 +81,63 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 
 // ListProducts returns a list of products
 // @Summary List products
+// @Description Products set to auto hide are left out while out of stock and listed again once restocked. Filters: field=value or field[op]=value with op eq, ne, gt, gte, lt, lte, in (comma separated values) or contains. Sort: sort=-created_at,name (minus for descending; cursors only follow the default order). Fields: fields=id,name. Filter and sort fields: name, sku, category, store_id, price (minor units), stock, is_active, auto_hide, created_at, updated_at.
 // @Tags products
 // @Produce json
 // @Security BearerAuth
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
+// @Param cursor query string false "Cursor from next_cursor of the previous page; replaces offset"
 // @Param category query string false "Filter by category"
 // @Param store_id query string false "Filter by store ID"
 // @Param active_only query bool false "Show only active products" default(false)
+// @Param sort query string false "Sort fields"
+// @Param fields query string false "Fields to return"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/products [get]
 func (h *ProductHandler) ListProducts(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	categoryStr := c.Query("category")
-	storeIDStr := c.Query("store_id")
+	cursor := c.Query("cursor")
 	activeOnlyStr := c.DefaultQuery("active_only", "false")
 
-	var category models.ProductCategory
-	if categoryStr != "" {
-		category = models.ProductCategory(categoryStr)
+	list, err := utils.ParseListQuery(c.Request.URL.Query(), "active_only")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-
-	var storeID uuid.UUID
-	if storeIDStr != "" {
-		var err error
-		storeID, err = uuid.Parse(storeIDStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
-			return
-		}
+	if activeOnlyStr == "true" || activeOnlyStr == "1" {
+		list.AddFilter("is_active", utils.FilterEq, "true")
 	}
 
-	activeOnly := activeOnlyStr == "true" || activeOnlyStr == "1"
-
-	products, err := h.productService.ListProducts(limit, offset, category, storeID, activeOnly)
+	products, next, err := h.productService.ListProducts(limit, offset, cursor, list)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondListError(c, err)
 		return
 	}
 
-	// Get total count for pagination
-	total, err := h.productService.CountProducts(category, storeID, activeOnly)
+	// Get total count for pagination, only needed in offset mode
+	var total int64
+	if cursor == "" {
+		total, err = h.productService.CountProducts(list)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	items, err := utils.SelectFields(products, list.Fields)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondListError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"products": products,
-		"total":    total,
-		"limit":    limit,
-		"offset":   offset,
-	})
+	c.JSON(http.StatusOK, pageResponse("products", items, total, limit, offset, cursor, next))
 }
 
 // UpdateProduct updates a product
 // @Summary Update product
+// @Description Stock is not changed here; record a stock movement instead.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -164,17 +170,24 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	// Update fields
 	product.Name = updateData.Name
 	product.Description = updateData.Description
+	product.Tags = updateData.Tags
 	product.Category = updateData.Category
 	product.Price = updateData.Price
-	product.Stock = updateData.Stock
 	product.StoreID = updateData.StoreID
 	product.SKU = updateData.SKU
 	product.ImageURL = updateData.ImageURL
 	product.IsActive = updateData.IsActive
+	product.ReorderThreshold = updateData.ReorderThreshold
+	product.AutoHide = updateData.AutoHide
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
 
-	err = h.productService.UpdateProduct(product)
+	err = h.productService.UpdateProduct(product, userID, role)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.respondError(c, err)
 		return
 	}
 
@@ -197,24 +210,30 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	err = h.productService.DeleteProduct(id)
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	err = h.productService.DeleteProduct(id, userID, role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
 }
 
-// UpdateStock updates the stock quantity of a product
-// @Summary Update product stock
+// UpdateStock records a stock movement of a product or one of its variants
+// @Summary Record stock movement
+// @Description Restocks and returns add a positive quantity, sales and spoilage remove it, adjustments add a signed quantity. The stock cannot drop below zero.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Product ID"
-// @Param request body map[string]int true "Stock quantity"
-// @Success 200 {object} map[string]string
+// @Param request body services.StockMovementRequest true "Stock movement"
+// @Success 201 {object} models.StockMovement
 // @Router /api/v1/products/{id}/stock [patch]
 func (h *ProductHandler) UpdateStock(c *gin.Context) {
 	idStr := c.Param("id")
@@ -224,20 +243,115 @@ func (h *ProductHandler) UpdateStock(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Stock int `json:"stock" binding:"required"`
-	}
-
+	var req services.StockMovementRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = h.productService.UpdateStock(id, req.Stock)
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	movement, err := h.productService.RecordStockMovement(id, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, movement)
+}
+
+// ListStockMovements returns the inventory ledger of a product
+// @Summary List stock movements
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variant_id query string false "Only movements of this variant"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Param cursor query string false "Cursor from next_cursor of the previous page; replaces offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/products/{id}/stock-movements [get]
+func (h *ProductHandler) ListStockMovements(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var variantID *uuid.UUID
+	if variantIDStr := c.Query("variant_id"); variantIDStr != "" {
+		parsed, err := uuid.Parse(variantIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid variant ID"})
+			return
+		}
+		variantID = &parsed
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	cursor := c.Query("cursor")
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	movements, next, total, err := h.productService.ListStockMovements(id, userID, role, variantID, limit, offset, cursor)
 	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pageResponse("movements", movements, total, limit, offset, cursor, next))
+}
+
+// BulkUpdateStock updates the stock quantity of several products at once
+// @Summary Update stock of several products
+// @Description All products are updated or none is; every product must belong to a store the caller manages.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.BulkStockRequest true "Stock quantities"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/products/stock [patch]
+func (h *ProductHandler) BulkUpdateStock(c *gin.Context) {
+	var req services.BulkStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Stock updated successfully"})
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	if err := h.productService.BulkUpdateStock(&req, userID, role); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stock updated successfully",
+		"updated": len(req.Items),
+	})
+}
+
+// respondError maps product service errors to HTTP responses
+func (h *ProductHandler) respondError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "product not found", "product variant not found", "store not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
 }
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+	"github.com/ruranjo/unientrega/internal/utils"
+)
+
+// ProductImportHandler handles bulk product imports and exports of a store catalog
+type ProductImportHandler struct {
+	importService *services.ProductImportService
+}
+
+// NewProductImportHandler creates a new product import handler
+func NewProductImportHandler(importService *services.ProductImportService) *ProductImportHandler {
+	return &ProductImportHandler{
+		importService: importService,
+	}
+}
+
+// ImportProducts creates or updates the products of a store from a CSV or XLSX file
+// @Summary Import store products
+// @Description Products are matched by SKU: existing ones are updated, new ones created. Columns: sku, name, category and price are required; description, tags (separated by semicolons), currency, stock, image_url, is_active, reorder_threshold and auto_hide are optional. Nothing is imported unless every row is valid; invalid rows are reported with status 422.
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Only validate the file" default(false)
+// @Success 200 {object} services.ImportResult
+// @Failure 413 {object} map[string]string
+// @Failure 422 {object} services.ImportResult
+// @Router /api/v1/stores/{id}/products/import [post]
+func (h *ProductImportHandler) ImportProducts(c *gin.Context) {
+	if !parseUpload(c, services.MaxImportFileSize) {
+		return
+	}
+
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	dryRunStr := c.DefaultQuery("dry_run", "false")
+	dryRun := dryRunStr == "true" || dryRunStr == "1"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A CSV or XLSX file is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	result, err := h.importService.ImportProducts(storeID, userID, role, fileHeader.Filename, file, dryRun)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ExportProducts downloads the products of a store in the import format
+// @Summary Export store products
+// @Tags products
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param format query string false "csv (default) or xlsx"
+// @Success 200 {file} file
+// @Router /api/v1/stores/{id}/products/export [get]
+func (h *ProductImportHandler) ExportProducts(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	fileName := "products-" + storeID.String() + "." + c.DefaultQuery("format", "csv")
+	format, err := utils.SpreadsheetFormatOf(fileName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	var file bytes.Buffer
+	if err := h.importService.ExportProducts(storeID, userID, role, format, &file); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	c.Data(http.StatusOK, format.ContentType(), file.Bytes())
+}
+
+// respondError maps product import service errors to HTTP responses
+func (h *ProductImportHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "store not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrUnsupportedSpreadsheet):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// ProductOptionHandler handles product variants and option groups
+type ProductOptionHandler struct {
+	optionService *services.ProductOptionService
+}
+
+// NewProductOptionHandler creates a new product option handler
+func NewProductOptionHandler(optionService *services.ProductOptionService) *ProductOptionHandler {
+	return &ProductOptionHandler{
+		optionService: optionService,
+	}
+}
+
+// CreateVariant adds a variant to a product
+// @Summary Create product variant
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body services.VariantRequest true "Variant with its own SKU, price and stock"
+// @Success 201 {object} models.ProductVariant
+// @Router /api/v1/products/{id}/variants [post]
+func (h *ProductOptionHandler) CreateVariant(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req services.VariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	variant, err := h.optionService.CreateVariant(productID, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, variant)
+}
+
+// UpdateVariant replaces the details of a product variant
+// @Summary Update product variant
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variant_id path string true "Variant ID"
+// @Param request body services.VariantRequest true "Variant with its own SKU, price and stock"
+// @Success 200 {object} models.ProductVariant
+// @Router /api/v1/products/{id}/variants/{variant_id} [put]
+func (h *ProductOptionHandler) UpdateVariant(c *gin.Context) {
+	productID, variantID, ok := parseProductOptionParams(c, "variant_id", "variant ID")
+	if !ok {
+		return
+	}
+
+	var req services.VariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	variant, err := h.optionService.UpdateVariant(productID, variantID, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, variant)
+}
+
+// DeleteVariant removes a variant from a product
+// @Summary Delete product variant
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param variant_id path string true "Variant ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/products/{id}/variants/{variant_id} [delete]
+func (h *ProductOptionHandler) DeleteVariant(c *gin.Context) {
+	productID, variantID, ok := parseProductOptionParams(c, "variant_id", "variant ID")
+	if !ok {
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	if err := h.optionService.DeleteVariant(productID, variantID, userID, role); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Variant deleted successfully"})
+}
+
+// CreateOptionGroup adds an option group with its modifiers to a product
+// @Summary Create product option group
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body services.OptionGroupRequest true "Option group with its modifiers"
+// @Success 201 {object} models.ProductOptionGroup
+// @Router /api/v1/products/{id}/option-groups [post]
+func (h *ProductOptionHandler) CreateOptionGroup(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req services.OptionGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	group, err := h.optionService.CreateOptionGroup(productID, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// UpdateOptionGroup replaces an option group and all its modifiers
+// @Summary Update product option group
+// @Description The modifiers in the request replace all modifiers of the group
+// @Tags products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param group_id path string true "Option group ID"
+// @Param request body services.OptionGroupRequest true "Option group with its modifiers"
+// @Success 200 {object} models.ProductOptionGroup
+// @Router /api/v1/products/{id}/option-groups/{group_id} [put]
+func (h *ProductOptionHandler) UpdateOptionGroup(c *gin.Context) {
+	productID, groupID, ok := parseProductOptionParams(c, "group_id", "option group ID")
+	if !ok {
+		return
+	}
+
+	var req services.OptionGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	group, err := h.optionService.UpdateOptionGroup(productID, groupID, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// DeleteOptionGroup removes an option group from a product
+// @Summary Delete product option group
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param group_id path string true "Option group ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/products/{id}/option-groups/{group_id} [delete]
+func (h *ProductOptionHandler) DeleteOptionGroup(c *gin.Context) {
+	productID, groupID, ok := parseProductOptionParams(c, "group_id", "option group ID")
+	if !ok {
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	if err := h.optionService.DeleteOptionGroup(productID, groupID, userID, role); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Option group deleted successfully"})
+}
+
+// respondError maps product option service errors to HTTP responses
+func (h *ProductOptionHandler) respondError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "product not found", "store not found", "product variant not found", "option group not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "product with this SKU already exists":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// parseProductOptionParams reads the product ID and the variant or option group ID of a route
+func parseProductOptionParams(c *gin.Context, param, label string) (uuid.UUID, uuid.UUID, bool) {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + label})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return productID, id, true
+}
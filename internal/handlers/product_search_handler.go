@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// ProductSearchHandler handles full-text product search
+type ProductSearchHandler struct {
+	searchService *services.ProductSearchService
+}
+
+// NewProductSearchHandler creates a new product search handler
+func NewProductSearchHandler(searchService *services.ProductSearchService) *ProductSearchHandler {
+	return &ProductSearchHandler{
+		searchService: searchService,
+	}
+}
+
+// SearchProducts searches products by name, tags and description
+// @Summary Search products
+// @Description Matches Spanish and English words regardless of accents, with a fuzzy fallback on the product name. Results are ranked best first; highlights are HTML-escaped with matches wrapped in <mark> tags. Facets count the matches per category and store.
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text"
+// @Param category query string false "Filter by category"
+// @Param store_id query string false "Filter by store ID"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/products/search [get]
+func (h *ProductSearchHandler) SearchProducts(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	category := models.ProductCategory(c.Query("category"))
+
+	var storeID uuid.UUID
+	if storeIDStr := c.Query("store_id"); storeIDStr != "" {
+		var err error
+		storeID, err = uuid.Parse(storeIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+			return
+		}
+	}
+
+	result, err := h.searchService.SearchProducts(c.Query("q"), category, storeID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": result.Matches,
+		"total":   result.Total,
+		"facets":  result.Facets,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
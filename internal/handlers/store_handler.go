@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/ruranjo/unientrega/internal/models"
 	"github.com/ruranjo/unientrega/internal/services"
+	"github.com/ruranjo/unientrega/internal/utils"
 )
 
 // StoreHandler handles store management requests
@@ -87,40 +88,56 @@ func (h *StoreHandler) GetStore(c *gin.Context) {
 
 // ListStores returns a list of stores
 // @Summary List stores
+// @Description Filters: field=value or field[op]=value with op eq, ne, gt, gte, lt, lte, in (comma separated values) or contains. Sort: sort=-created_at,name (minus for descending; cursors only follow the default order). Fields: fields=id,name. Filter and sort fields: name, zone, owner_id, is_active, is_paused, created_at, updated_at.
 // @Tags stores
 // @Produce json
 // @Security BearerAuth
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
+// @Param cursor query string false "Cursor from next_cursor of the previous page; replaces offset"
 // @Param active_only query bool false "Show only active stores" default(false)
+// @Param sort query string false "Sort fields"
+// @Param fields query string false "Fields to return"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/stores [get]
 func (h *StoreHandler) ListStores(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	cursor := c.Query("cursor")
 	activeOnlyStr := c.DefaultQuery("active_only", "false")
 
-	activeOnly := activeOnlyStr == "true" || activeOnlyStr == "1"
+	list, err := utils.ParseListQuery(c.Request.URL.Query(), "active_only")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if activeOnlyStr == "true" || activeOnlyStr == "1" {
+		list.AddFilter("is_active", utils.FilterEq, "true")
+	}
 
-	stores, err := h.storeService.ListStores(limit, offset, activeOnly)
+	stores, next, err := h.storeService.ListStores(limit, offset, cursor, list)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondListError(c, err)
 		return
 	}
 
-	// Get total count for pagination
-	total, err := h.storeService.CountStores(activeOnly)
+	// Get total count for pagination, only needed in offset mode
+	var total int64
+	if cursor == "" {
+		total, err = h.storeService.CountStores(list)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	items, err := utils.SelectFields(stores, list.Fields)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondListError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"stores": stores,
-		"total":  total,
-		"limit":  limit,
-		"offset": offset,
-	})
+	c.JSON(http.StatusOK, pageResponse("stores", items, total, limit, offset, cursor, next))
 }
 
 // UpdateStore updates a store
@@ -147,20 +164,18 @@ func (h *StoreHandler) UpdateStore(c *gin.Context) {
 		return
 	}
 
-	// Get existing store
-	store, err := h.storeService.GetStoreByID(id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Store not found"})
-		return
-	}
-
-	// Check permissions: only owner or superuser can update
+	// Get existing store, checking permissions: only store owners or superuser can update
 	userIDStr, _ := c.Get("user_id")
 	userRole, _ := c.Get("user_role")
 	userID := userIDStr.(uuid.UUID)
 
-	if store.OwnerID != userID && userRole != models.RoleSuperUser {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to update this store"})
+	store, err := h.storeService.AuthorizeStore(id, userID, userRole.(models.Role), models.PermissionManageStore)
+	if err != nil {
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to update this store"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Store not found"})
 		return
 	}
 
@@ -168,6 +183,8 @@ func (h *StoreHandler) UpdateStore(c *gin.Context) {
 	store.Name = updateData.Name
 	store.Description = updateData.Description
 	store.Location = updateData.Location
+	store.LocationID = updateData.LocationID
+	store.Zone = updateData.Zone
 	store.IsActive = updateData.IsActive
 
 	err = h.storeService.UpdateStore(store)
@@ -195,21 +212,17 @@ func (h *StoreHandler) DeleteStore(c *gin.Context) {
 		return
 	}
 
-	// Check permissions: only owner or superuser can delete
-	// For safety, maybe only superuser should delete stores?
-	// Let's allow owner too for now, but check ownership
-	store, err := h.storeService.GetStoreByID(id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Store not found"})
-		return
-	}
-
+	// Check permissions: only store owners or superuser can delete
 	userIDStr, _ := c.Get("user_id")
 	userRole, _ := c.Get("user_role")
 	userID := userIDStr.(uuid.UUID)
 
-	if store.OwnerID != userID && userRole != models.RoleSuperUser {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete this store"})
+	if _, err := h.storeService.AuthorizeStore(id, userID, userRole.(models.Role), models.PermissionManageStore); err != nil {
+		if err.Error() == "permission denied" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete this store"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Store not found"})
 		return
 	}
 
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// StoreHoursHandler handles store opening hours, special hours and pause mode
+type StoreHoursHandler struct {
+	storeHoursService *services.StoreHoursService
+}
+
+// NewStoreHoursHandler creates a new store hours handler
+func NewStoreHoursHandler(storeHoursService *services.StoreHoursService) *StoreHoursHandler {
+	return &StoreHoursHandler{
+		storeHoursService: storeHoursService,
+	}
+}
+
+// GetSchedule returns the opening schedule of a store
+// @Summary Get store schedule
+// @Description Weekly hours, current and upcoming special hours, pause state and whether the store is open now
+// @Tags stores
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Success 200 {object} services.StoreSchedule
+// @Router /api/v1/stores/{id}/hours [get]
+func (h *StoreHoursHandler) GetSchedule(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	schedule, err := h.storeHoursService.GetSchedule(storeID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// SetOpeningHours replaces the weekly schedule of a store
+// @Summary Set store opening hours
+// @Description Replaces the weekly schedule. Periods closing at or before their opening time end the next day.
+// @Description An empty list leaves the store open around the clock.
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param request body services.OpeningHoursRequest true "Time zone and weekly periods"
+// @Success 200 {object} services.StoreSchedule
+// @Router /api/v1/stores/{id}/hours [put]
+func (h *StoreHoursHandler) SetOpeningHours(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	var req services.OpeningHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	schedule, err := h.storeHoursService.SetOpeningHours(storeID, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// AddSpecialHours closes a store or changes its hours for a range of dates
+// @Summary Add store special hours
+// @Description Holiday closures or different hours, e.g. during exam week. The most recently added special hours win when they overlap.
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param request body services.SpecialHoursRequest true "Dates and hours"
+// @Success 201 {object} models.StoreSpecialHours
+// @Router /api/v1/stores/{id}/special-hours [post]
+func (h *StoreHoursHandler) AddSpecialHours(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	var req services.SpecialHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	special, err := h.storeHoursService.AddSpecialHours(storeID, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, special)
+}
+
+// DeleteSpecialHours removes special hours of a store
+// @Summary Delete store special hours
+// @Tags stores
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param special_id path string true "Special hours ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/stores/{id}/special-hours/{special_id} [delete]
+func (h *StoreHoursHandler) DeleteSpecialHours(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+	specialID, err := uuid.Parse(c.Param("special_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid special hours ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	if err := h.storeHoursService.DeleteSpecialHours(storeID, specialID, userID, role); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Special hours deleted successfully"})
+}
+
+// PauseStore stops order intake of a store
+// @Summary Pause store
+// @Description Stops accepting orders until the given time, or until the store is resumed
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param request body services.PauseStoreRequest false "Optional end time and reason"
+// @Success 200 {object} services.StoreSchedule
+// @Router /api/v1/stores/{id}/pause [post]
+func (h *StoreHoursHandler) PauseStore(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	var req services.PauseStoreRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	schedule, err := h.storeHoursService.PauseStore(storeID, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// ResumeStore resumes order intake of a paused store
+// @Summary Resume store
+// @Tags stores
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Success 200 {object} services.StoreSchedule
+// @Router /api/v1/stores/{id}/resume [post]
+func (h *StoreHoursHandler) ResumeStore(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	schedule, err := h.storeHoursService.ResumeStore(storeID, userID, role)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// respondError maps store hours service errors to HTTP responses
+func (h *StoreHoursHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "store not found" || err.Error() == "special hours not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
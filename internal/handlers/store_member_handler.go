@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// StoreMemberHandler handles store staff members and invitations
+type StoreMemberHandler struct {
+	memberService *services.StoreMemberService
+}
+
+// NewStoreMemberHandler creates a new store member handler
+func NewStoreMemberHandler(memberService *services.StoreMemberService) *StoreMemberHandler {
+	return &StoreMemberHandler{
+		memberService: memberService,
+	}
+}
+
+// ListMembers returns the members of a store
+// @Summary List store members
+// @Tags stores
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/stores/{id}/members [get]
+func (h *StoreMemberHandler) ListMembers(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	members, err := h.memberService.ListMembers(storeID, userID, role)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// UpdateMember changes the role of a store member
+// @Summary Update store member role
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param user_id path string true "Member user ID"
+// @Param request body services.UpdateMemberRequest true "New role: owner, manager or staff"
+// @Success 200 {object} models.StoreMember
+// @Router /api/v1/stores/{id}/members/{user_id} [put]
+func (h *StoreMemberHandler) UpdateMember(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+	memberUserID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req services.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	member, err := h.memberService.UpdateMember(storeID, memberUserID, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember removes a member from a store
+// @Summary Remove store member
+// @Description Members may remove themselves to leave a store
+// @Tags stores
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param user_id path string true "Member user ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/stores/{id}/members/{user_id} [delete]
+func (h *StoreMemberHandler) RemoveMember(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+	memberUserID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	if err := h.memberService.RemoveMember(storeID, memberUserID, userID, role); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// InviteMember emails an invitation to join a store
+// @Summary Invite store member
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param request body services.InviteMemberRequest true "Email and role of the new member"
+// @Success 201 {object} models.StoreInvitation
+// @Router /api/v1/stores/{id}/invitations [post]
+func (h *StoreMemberHandler) InviteMember(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	var req services.InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	invitation, err := h.memberService.InviteMember(storeID, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListInvitations returns the pending invitations of a store
+// @Summary List store invitations
+// @Tags stores
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/stores/{id}/invitations [get]
+func (h *StoreMemberHandler) ListInvitations(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	invitations, err := h.memberService.ListInvitations(storeID, userID, role)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// RevokeInvitation cancels a pending store invitation
+// @Summary Revoke store invitation
+// @Tags stores
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param invitation_id path string true "Invitation ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/stores/{id}/invitations/{invitation_id} [delete]
+func (h *StoreMemberHandler) RevokeInvitation(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+	invitationID, err := uuid.Parse(c.Param("invitation_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	if err := h.memberService.RevokeInvitation(storeID, invitationID, userID, role); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// AcceptInvitation joins the store of an invitation sent to the user's email address
+// @Summary Accept store invitation
+// @Description Customers who join a store become store users and must sign in again
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.AcceptInvitationRequest true "Token received by email"
+// @Success 201 {object} models.StoreMember
+// @Router /api/v1/store-invitations/accept [post]
+func (h *StoreMemberHandler) AcceptInvitation(c *gin.Context) {
+	var req services.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	member, err := h.memberService.AcceptInvitation(userID, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// ListMyMemberships returns the stores the current user works at
+// @Summary List my store memberships
+// @Tags stores
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/store-memberships [get]
+func (h *StoreMemberHandler) ListMyMemberships(c *gin.Context) {
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+
+	memberships, err := h.memberService.ListUserMemberships(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"memberships": memberships})
+}
+
+// respondError maps store member service errors to HTTP responses
+func (h *StoreMemberHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "store not found" || err.Error() == "store member not found" || err.Error() == "invitation not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrLastOwner) || err.Error() == "user is already a member of the store":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err.Error() == "failed to send invitation email":
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// TimeSlotHandler handles scheduled order time slots of stores
+type TimeSlotHandler struct {
+	timeSlotService *services.TimeSlotService
+}
+
+// NewTimeSlotHandler creates a new time slot handler
+func NewTimeSlotHandler(timeSlotService *services.TimeSlotService) *TimeSlotHandler {
+	return &TimeSlotHandler{
+		timeSlotService: timeSlotService,
+	}
+}
+
+// ListSlots returns the 15-minute time slots of a store on a date
+// @Summary List store time slots
+// @Description Slots follow the store opening hours. Use starts_at as scheduled_for when creating the order.
+// @Tags stores
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param date query string false "Date in the store time zone (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/stores/{id}/slots [get]
+func (h *TimeSlotHandler) ListSlots(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	slots, err := h.timeSlotService.ListSlots(storeID, c.Query("date"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"slots": slots})
+}
+
+// GetSettings returns the scheduled order settings of a store
+// @Summary Get store time slot settings
+// @Tags stores
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Success 200 {object} models.TimeSlotSettings
+// @Router /api/v1/stores/{id}/slot-settings [get]
+func (h *TimeSlotHandler) GetSettings(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	settings, err := h.timeSlotService.GetSettings(storeID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// SetSettings configures scheduled orders of a store
+// @Summary Set store time slot settings
+// @Tags stores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Store ID"
+// @Param request body services.TimeSlotSettingsRequest true "Capacity per slot and booking window"
+// @Success 200 {object} models.TimeSlotSettings
+// @Router /api/v1/stores/{id}/slot-settings [put]
+func (h *TimeSlotHandler) SetSettings(c *gin.Context) {
+	storeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		return
+	}
+
+	var req services.TimeSlotSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDStr, _ := c.Get("user_id")
+	userID := userIDStr.(uuid.UUID)
+	roleStr, _ := c.Get("user_role")
+	role := roleStr.(models.Role)
+
+	settings, err := h.timeSlotService.SetSettings(storeID, userID, role, &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// respondError maps time slot service errors to HTTP responses
+func (h *TimeSlotHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "store not found" || errors.Is(err, services.ErrSchedulingNotOffered):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
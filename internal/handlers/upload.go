@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// multipartOverhead is the room an upload request has beyond its file, for the
+	// multipart framing and the other form fields
+	multipartOverhead = 1 << 20
+	// maxMultipartMemory is how much of a multipart form is kept in memory, the rest goes to temporary files
+	maxMultipartMemory = 8 << 20
+)
+
+// parseUpload parses a multipart upload whose file may be at most maxFileSize bytes.
+// The body is capped while it is read, so oversized uploads are rejected before they are
+// spooled to memory or disk. It responds with an error and returns false on failure.
+func parseUpload(c *gin.Context, maxFileSize int64) bool {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFileSize+multipartOverhead)
+	err := c.Request.ParseMultipartForm(maxMultipartMemory)
+	if err == nil {
+		return true
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("upload is larger than %d MB", maxFileSize>>20)})
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart form"})
+	return false
+}
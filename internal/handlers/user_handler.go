@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/ruranjo/unientrega/internal/models"
 	"github.com/ruranjo/unientrega/internal/services"
+	"github.com/ruranjo/unientrega/internal/utils"
 )
 
 // UserHandler handles user management requests
@@ -24,31 +25,52 @@ func NewUserHandler(userService *services.UserService) *UserHandler {
 
 // ListUsers returns a list of users (admin only)
 // @Summary List users
+// @Description Filters: field=value or field[op]=value with op eq, ne, gt, gte, lt, lte, in (comma separated values) or contains. Sort: sort=-created_at,name (minus for descending; cursors only follow the default order). Fields: fields=id,name. Filter and sort fields: email, first_name, last_name, role, is_active, created_at, updated_at.
 // @Tags users
 // @Produce json
 // @Security BearerAuth
-// @Param limit query int false "Limit"
-// @Param offset query int false "Offset"
+// @Param limit query int false "Limit" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Param cursor query string false "Cursor from next_cursor of the previous page; replaces offset"
 // @Param role query string false "Filter by role"
-// @Success 200 {array} models.User
+// @Param sort query string false "Sort fields"
+// @Param fields query string false "Fields to return"
+// @Success 200 {object} map[string]interface{}
 // @Router /api/v1/users [get]
 func (h *UserHandler) ListUsers(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	roleStr := c.Query("role")
+	cursor := c.Query("cursor")
 
-	var role models.Role
-	if roleStr != "" {
-		role = models.Role(roleStr)
+	list, err := utils.ParseListQuery(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	users, err := h.userService.ListUsers(limit, offset, role)
+	users, next, err := h.userService.ListUsers(limit, offset, cursor, list)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondListError(c, err)
+		return
+	}
+
+	// Get total count for pagination, only needed in offset mode
+	var total int64
+	if cursor == "" {
+		total, err = h.userService.CountUsers(list)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	items, err := utils.SelectFields(users, list.Fields)
+	if err != nil {
+		respondListError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	c.JSON(http.StatusOK, pageResponse("users", items, total, limit, offset, cursor, next))
 }
 
 // GetUser returns a user by ID
@@ -175,6 +197,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 
 // ChangePassword changes a user's password
 // @Summary Change password
+// @Description Every other session of the user is signed out.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -193,6 +216,8 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 
 	// Get current user from context
 	currentUserID, _ := c.Get("user_id")
+	sessionIDStr, _ := c.Get("session_id")
+	sessionID := sessionIDStr.(uuid.UUID)
 
 	// Users can only change their own password
 	if currentUserID != id {
@@ -222,8 +247,8 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	// Update password
-	err = h.userService.UpdatePassword(id, req.NewPassword)
+	// Update password, signing out the other sessions
+	err = h.userService.UpdatePassword(id, req.NewPassword, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,17 @@
+package mailer
+
+import "log"
+
+// LogMailer writes emails to the server log instead of sending them, for development
+type LogMailer struct{}
+
+// NewLogMailer creates a new log mailer
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send logs the email
+func (m *LogMailer) Send(msg Message) error {
+	log.Printf("Email to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"errors"
+
+	"github.com/ruranjo/unientrega/internal/config"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New returns the mailer selected in the configuration
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return NewLogMailer(), nil
+	case "smtp":
+		return NewSMTPMailer(cfg)
+	}
+	return nil, errors.New("unknown mail driver: " + cfg.Driver)
+}
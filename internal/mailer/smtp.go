@@ -0,0 +1,55 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/ruranjo/unientrega/internal/config"
+)
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	addr     string
+	from     string // From header, may include a display name
+	envelope string // Bare sender address
+	auth     smtp.Auth
+}
+
+// NewSMTPMailer creates a new SMTP mailer
+func NewSMTPMailer(cfg config.MailConfig) (*SMTPMailer, error) {
+	if cfg.SMTPHost == "" || cfg.From == "" {
+		return nil, errors.New("SMTP mail driver requires SMTP_HOST and MAIL_FROM")
+	}
+
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAIL_FROM: %w", err)
+	}
+
+	mailer := &SMTPMailer{
+		addr:     net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		from:     from.String(),
+		envelope: from.Address,
+	}
+	if cfg.SMTPUsername != "" {
+		mailer.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return mailer, nil
+}
+
+// Send sends the email
+func (m *SMTPMailer) Send(msg Message) error {
+	// Header values must not contain line breaks
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return errors.New("invalid email header")
+	}
+
+	body := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		m.from, msg.To, msg.Subject, strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return smtp.SendMail(m.addr, m.auth, m.envelope, []string{msg.To}, []byte(body))
+}
@@ -5,11 +5,34 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/ruranjo/unientrega/internal/models"
 	"github.com/ruranjo/unientrega/internal/utils"
 )
 
-// AuthRequired middleware validates JWT token and sets user info in context
+// SessionChecker tells whether the session an access token was issued to is still active
+type SessionChecker interface {
+	IsSessionActive(userID, sessionID uuid.UUID) (bool, error)
+}
+
+// sessionChecker rejects access tokens of ended sessions; nil skips the check
+var sessionChecker SessionChecker
+
+// SetSessionChecker sets the checker of the sessions of access tokens
+func SetSessionChecker(checker SessionChecker) {
+	sessionChecker = checker
+}
+
+// sessionActive checks the session of validated token claims
+func sessionActive(claims *utils.Claims) (bool, error) {
+	if sessionChecker == nil {
+		return true, nil
+	}
+	return sessionChecker.IsSessionActive(claims.UserID, claims.SessionID)
+}
+
+// AuthRequired middleware validates JWT token and sets user info in context.
+// Tokens of revoked or expired sessions are rejected.
 func AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from Authorization header
@@ -38,10 +61,24 @@ func AuthRequired() gin.HandlerFunc {
 			return
 		}
 
+		// The session may have ended since the token was issued
+		active, err := sessionActive(claims)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session"})
+			c.Abort()
+			return
+		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has expired or been revoked"})
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
+		c.Set("session_id", claims.SessionID)
 
 		c.Next()
 	}
@@ -77,7 +114,8 @@ func RoleRequired(roles ...models.Role) gin.HandlerFunc {
 	}
 }
 
-// OptionalAuth middleware parses token if present but doesn't fail if missing
+// OptionalAuth middleware parses token if present but doesn't fail if missing.
+// Tokens of ended sessions are ignored.
 func OptionalAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -95,9 +133,14 @@ func OptionalAuth() gin.HandlerFunc {
 		token := parts[1]
 		claims, err := utils.ValidateToken(token)
 		if err == nil {
+			if active, err := sessionActive(claims); err != nil || !active {
+				c.Next()
+				return
+			}
 			c.Set("user_id", claims.UserID)
 			c.Set("user_email", claims.Email)
 			c.Set("user_role", claims.Role)
+			c.Set("session_id", claims.SessionID)
 		}
 
 		c.Next()
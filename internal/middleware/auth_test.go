@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/utils"
+)
+
+// fakeSessions is an in-memory SessionChecker
+type fakeSessions struct {
+	owners  map[uuid.UUID]uuid.UUID // Session ID -> user ID
+	revoked map[uuid.UUID]bool
+}
+
+func (f *fakeSessions) IsSessionActive(userID, sessionID uuid.UUID) (bool, error) {
+	owner, ok := f.owners[sessionID]
+	return ok && owner == userID && !f.revoked[sessionID], nil
+}
+
+func TestAuthRequiredRejectsTokensOfRevokedSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID, sessionID, otherSessionID := uuid.New(), uuid.New(), uuid.New()
+	sessions := &fakeSessions{
+		owners:  map[uuid.UUID]uuid.UUID{sessionID: userID, otherSessionID: uuid.New()},
+		revoked: map[uuid.UUID]bool{},
+	}
+	SetSessionChecker(sessions)
+	t.Cleanup(func() { SetSessionChecker(nil) })
+
+	r := gin.New()
+	r.GET("/me", AuthRequired(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(tokenSessionID uuid.UUID) int {
+		token, err := utils.GenerateToken(userID, "user@example.com", models.RoleClient, tokenSessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		name      string
+		sessionID uuid.UUID
+		revoke    bool
+		want      int
+	}{
+		{name: "active session", sessionID: sessionID, want: http.StatusOK},
+		{name: "session of another user", sessionID: otherSessionID, want: http.StatusUnauthorized},
+		{name: "unknown session", sessionID: uuid.New(), want: http.StatusUnauthorized},
+		{name: "token without session", sessionID: uuid.Nil, want: http.StatusUnauthorized},
+		{name: "revoked session", sessionID: sessionID, revoke: true, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.revoke {
+				sessions.revoked[tt.sessionID] = true
+			}
+			if got := request(tt.sessionID); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/services"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client generated idempotency key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayHeader is set on responses replayed from an earlier request
+const IdempotentReplayHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength is the longest accepted idempotency key
+const maxIdempotencyKeyLength = 255
+
+// Idempotency middleware makes a route safe to retry. When a request carries an
+// Idempotency-Key header, the response is stored and returned again for repeated
+// requests with the same key and body; reusing the key with a different body is
+// rejected with 422. Requests without the header are processed normally.
+// Must run after AuthRequired, keys are scoped to the authenticated user.
+func Idempotency(idempotencyService *services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			c.Abort()
+			return
+		}
+
+		userIDValue, exists := c.Get("user_id")
+		userID, ok := userIDValue.(uuid.UUID)
+		if !exists || !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		path := c.Request.URL.Path
+		hash := sha256.Sum256(append([]byte(c.Request.Method+" "+path+"\n"), body...))
+
+		record, err := idempotencyService.Begin(userID, key, c.Request.Method, path, hex.EncodeToString(hash[:]))
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrIdempotencyKeyMismatch):
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			case errors.Is(err, services.ErrIdempotencyKeyInProgress):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			c.Abort()
+			return
+		}
+
+		// Seen before: replay the stored response
+		if record.Status == models.IdempotencyCompleted {
+			c.Header(IdempotentReplayHeader, "true")
+			c.Data(record.ResponseCode, record.ContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		completed := false
+		defer func() {
+			// The handler panicked: forget the key so the request can be retried
+			if !completed {
+				idempotencyService.Abort(record)
+			}
+		}()
+
+		c.Next()
+
+		completed = true
+		if err := idempotencyService.Complete(record, recorder.Status(), recorder.body.Bytes(), recorder.Header().Get("Content-Type")); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// responseRecorder copies the response body while it is written to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write writes the data to the client and keeps a copy
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes the string to the client and keeps a copy
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
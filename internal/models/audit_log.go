@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditAction identifies an audited action
+type AuditAction string
+
+const (
+	AuditProductCreate    AuditAction = "product.create"
+	AuditProductUpdate    AuditAction = "product.update"
+	AuditProductDelete    AuditAction = "product.delete"
+	AuditProductStock     AuditAction = "product.stock"
+	AuditProductBulkStock AuditAction = "product.bulk_stock"
+	AuditProductImport    AuditAction = "product.import"
+)
+
+// AuditOutcome represents the result of an audited action
+type AuditOutcome string
+
+const (
+	AuditOutcomeDenied AuditOutcome = "denied" // The actor was not allowed to perform the action
+)
+
+// AuditLog records a security relevant action, such as a denied attempt to change
+// another store's catalog. Entries are append-only.
+type AuditLog struct {
+	ID           uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ActorID      uuid.UUID    `gorm:"type:uuid;not null;index" json:"actor_id"`
+	ActorRole    Role         `gorm:"type:varchar(20);not null" json:"actor_role"`
+	Action       AuditAction  `gorm:"type:varchar(50);not null;index" json:"action"`
+	Outcome      AuditOutcome `gorm:"type:varchar(20);not null" json:"outcome"`
+	ResourceType string       `gorm:"size:50;not null" json:"resource_type"`
+	ResourceID   *uuid.UUID   `gorm:"type:uuid" json:"resource_id,omitempty"`
+	StoreID      *uuid.UUID   `gorm:"type:uuid;index" json:"store_id,omitempty"`
+	Reason       string       `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt    time.Time    `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// BeforeCreate is a GORM hook that runs before creating an audit log entry
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
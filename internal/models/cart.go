@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Cart is the shopping cart of a user at a store.
+// A user has at most one cart per store; carts expire when left untouched.
+type Cart struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_carts_user_store" json:"user_id"`
+	StoreID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_carts_user_store" json:"store_id"`
+	Items     []CartItem `gorm:"foreignKey:CartID;constraint:OnDelete:CASCADE" json:"items"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"` // Extended on every change
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for Cart model
+func (Cart) TableName() string {
+	return "carts"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a cart
+func (c *Cart) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// CartItem is a configured product and quantity in a cart.
+// A cart has one item per product, variant and set of modifiers.
+type CartItem struct {
+	ID               uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CartID           uuid.UUID   `gorm:"type:uuid;not null;uniqueIndex:idx_cart_items_cart_configuration" json:"cart_id"`
+	ProductID        uuid.UUID   `gorm:"type:uuid;not null;index" json:"product_id"`
+	VariantID        *uuid.UUID  `gorm:"type:uuid" json:"variant_id,omitempty"`
+	ModifierIDs      []uuid.UUID `gorm:"type:jsonb;serializer:json;not null;default:'[]'" json:"modifier_ids"`
+	ConfigurationKey string      `gorm:"not null;uniqueIndex:idx_cart_items_cart_configuration" json:"-"` // Product, variant and sorted modifiers
+	Quantity         int         `gorm:"not null" json:"quantity"`
+	AddedPrice       Money       `gorm:"embedded;embeddedPrefix:added_price_" json:"added_price"` // Unit price when the item was added
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+// TableName specifies the table name for CartItem model
+func (CartItem) TableName() string {
+	return "cart_items"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a cart item
+func (ci *CartItem) BeforeCreate(tx *gorm.DB) error {
+	if ci.ID == uuid.Nil {
+		ci.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CourierStatus represents the availability of a delivery person
+type CourierStatus string
+
+const (
+	CourierStatusOnline  CourierStatus = "online"
+	CourierStatusOffline CourierStatus = "offline"
+	CourierStatusBusy    CourierStatus = "busy"
+)
+
+// IsValid checks if the courier status is valid
+func (cs CourierStatus) IsValid() bool {
+	switch cs {
+	case CourierStatusOnline, CourierStatusOffline, CourierStatusBusy:
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the courier status
+func (cs CourierStatus) String() string {
+	return string(cs)
+}
+
+// CourierAvailability tracks whether a delivery person can receive delivery offers
+type CourierAvailability struct {
+	ID         uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Status     CourierStatus `gorm:"type:varchar(20);not null;default:'offline';index" json:"status"`
+	Zone       string        `gorm:"size:50" json:"zone,omitempty"` // Campus zone the courier is currently in
+	LastSeenAt time.Time     `json:"last_seen_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// TableName specifies the table name for CourierAvailability model
+func (CourierAvailability) TableName() string {
+	return "courier_availability"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a courier availability record
+func (ca *CourierAvailability) BeforeCreate(tx *gorm.DB) error {
+	if ca.ID == uuid.Nil {
+		ca.ID = uuid.New()
+	}
+	return nil
+}
+
+// DeliveryOfferStatus represents the state of a delivery offer
+type DeliveryOfferStatus string
+
+const (
+	DeliveryOfferPending   DeliveryOfferStatus = "pending"
+	DeliveryOfferAccepted  DeliveryOfferStatus = "accepted"
+	DeliveryOfferDeclined  DeliveryOfferStatus = "declined"
+	DeliveryOfferExpired   DeliveryOfferStatus = "expired"
+	DeliveryOfferWithdrawn DeliveryOfferStatus = "withdrawn" // Order was claimed or cancelled meanwhile
+)
+
+// String returns the string representation of the delivery offer status
+func (s DeliveryOfferStatus) String() string {
+	return string(s)
+}
+
+// DeliveryOffer is an order offered to a single courier, who must accept it before ExpiresAt
+type DeliveryOffer struct {
+	ID          uuid.UUID           `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID     uuid.UUID           `gorm:"type:uuid;not null;index" json:"order_id"`
+	CourierID   uuid.UUID           `gorm:"type:uuid;not null;index" json:"courier_id"`
+	Status      DeliveryOfferStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Strategy    string              `gorm:"size:50" json:"strategy"` // Strategy that selected the courier
+	ExpiresAt   time.Time           `gorm:"not null" json:"expires_at"`
+	RespondedAt *time.Time          `json:"responded_at,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+
+	// Relationships
+	Order *Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+}
+
+// TableName specifies the table name for DeliveryOffer model
+func (DeliveryOffer) TableName() string {
+	return "delivery_offers"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a delivery offer
+func (o *DeliveryOffer) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
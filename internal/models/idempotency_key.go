@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IdempotencyStatus represents the state of a request made with an idempotency key
+type IdempotencyStatus string
+
+const (
+	IdempotencyInProgress IdempotencyStatus = "in_progress"
+	IdempotencyCompleted  IdempotencyStatus = "completed"
+)
+
+// IdempotencyKey stores the outcome of a request sent with an Idempotency-Key header,
+// so that retries of the same request get the original response instead of repeating it.
+// Keys are scoped to the user that sent them.
+type IdempotencyKey struct {
+	ID           uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID       uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_idempotency_user_key" json:"user_id"`
+	Key          string            `gorm:"size:255;not null;uniqueIndex:idx_idempotency_user_key" json:"key"`
+	Method       string            `gorm:"size:10;not null" json:"method"`
+	Path         string            `gorm:"size:500;not null" json:"path"`
+	RequestHash  string            `gorm:"size:64;not null" json:"request_hash"` // SHA-256 of method, path and body
+	Status       IdempotencyStatus `gorm:"type:varchar(20);not null" json:"status"`
+	ResponseCode int               `json:"response_code"`
+	ResponseBody []byte            `json:"-"`
+	ContentType  string            `gorm:"size:100" json:"content_type"`
+	ExpiresAt    time.Time         `gorm:"not null;index" json:"expires_at"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// TableName specifies the table name for IdempotencyKey model
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// BeforeCreate is a GORM hook that runs before creating an idempotency key
+func (k *IdempotencyKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
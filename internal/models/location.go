@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CampusLocation represents a deliverable place on campus (a building, floor or room)
+type CampusLocation struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string         `gorm:"size:200;not null" json:"name"`
+	Building    string         `gorm:"size:100;not null;index" json:"building"`
+	Floor       string         `gorm:"size:50" json:"floor,omitempty"`
+	Room        string         `gorm:"size:50" json:"room,omitempty"`
+	Zone        string         `gorm:"size:50;index" json:"zone,omitempty"` // Campus zone, used for dispatch and delivery pricing
+	Description string         `gorm:"type:text" json:"description,omitempty"`
+	Latitude    *float64       `gorm:"type:decimal(9,6)" json:"latitude,omitempty"`
+	Longitude   *float64       `gorm:"type:decimal(9,6)" json:"longitude,omitempty"`
+	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete
+}
+
+// TableName specifies the table name for CampusLocation model
+func (CampusLocation) TableName() string {
+	return "campus_locations"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a campus location
+func (l *CampusLocation) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// DeliveryAddress is a campus location saved by a user for future orders
+type DeliveryAddress struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID       uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	LocationID   uuid.UUID      `gorm:"type:uuid;not null" json:"location_id"`
+	Label        string         `gorm:"size:100;not null" json:"label"`          // e.g. "My lab", "Library"
+	Instructions string         `gorm:"type:text" json:"instructions,omitempty"` // e.g. "Second desk by the window"
+	IsDefault    bool           `gorm:"default:false" json:"is_default"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete
+
+	// Relationships
+	Location *CampusLocation `gorm:"foreignKey:LocationID" json:"location,omitempty"`
+}
+
+// TableName specifies the table name for DeliveryAddress model
+func (DeliveryAddress) TableName() string {
+	return "delivery_addresses"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a delivery address
+func (a *DeliveryAddress) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultCurrency is the ISO 4217 code used when an amount does not specify one
+var DefaultCurrency = "USD"
+
+// SetDefaultCurrency sets the default currency from configuration
+func SetDefaultCurrency(code string) {
+	if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+		DefaultCurrency = code
+	}
+}
+
+// Money is an amount of money in minor units (cents) of a currency.
+// Amounts are always whole minor units; converting from a decimal value rounds
+// half away from zero (1.005 -> 1.01, -1.005 -> -1.01).
+// Embed it in models with `gorm:"embedded;embeddedPrefix:<column>_"`. The currency column has
+// no database default, since the default currency is configurable: create amounts with NewMoney.
+type Money struct {
+	Amount   int64  `gorm:"not null;default:0" json:"amount"`
+	Currency string `gorm:"size:3;not null" json:"currency"`
+}
+
+// NewMoney creates an amount in minor units, using the default currency if none is given
+func NewMoney(amount int64, currency string) Money {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// MoneyFromDecimal converts a decimal amount (e.g. 12.345) to minor units, rounding half away from zero
+func MoneyFromDecimal(value float64, currency string) Money {
+	return NewMoney(int64(math.Round(value*100)), currency)
+}
+
+// Add returns the sum of two amounts. Both amounts must use the same currency.
+func (m Money) Add(other Money) Money {
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}
+}
+
+// Multiply returns the amount multiplied by a whole quantity
+func (m Money) Multiply(quantity int) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Currency}
+}
+
+// SameCurrency reports whether two amounts use the same currency
+func (m Money) SameCurrency(other Money) bool {
+	return m.Currency == other.Currency
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsValidCurrency checks that the currency is a three letter ISO 4217 code
+func (m Money) IsValidCurrency() bool {
+	if len(m.Currency) != 3 {
+		return false
+	}
+	for _, r := range m.Currency {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Decimal returns the amount as a decimal without its currency, e.g. "12.50"
+func (m Money) Decimal() string {
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// String returns the amount as a decimal with its currency, e.g. "12.50 USD"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
@@ -11,18 +11,19 @@ import (
 type OrderStatus string
 
 const (
-	OrderStatusPending   OrderStatus = "pending"
-	OrderStatusConfirmed OrderStatus = "confirmed"
-	OrderStatusPreparing OrderStatus = "preparing"
-	OrderStatusReady     OrderStatus = "ready"
-	OrderStatusCompleted OrderStatus = "completed"
-	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusPending    OrderStatus = "pending"
+	OrderStatusConfirmed  OrderStatus = "confirmed"
+	OrderStatusPreparing  OrderStatus = "preparing"
+	OrderStatusReady      OrderStatus = "ready"
+	OrderStatusInDelivery OrderStatus = "in_delivery"
+	OrderStatusCompleted  OrderStatus = "completed"
+	OrderStatusCancelled  OrderStatus = "cancelled"
 )
 
 // IsValid checks if the order status is valid
 func (os OrderStatus) IsValid() bool {
 	switch os {
-	case OrderStatusPending, OrderStatusConfirmed, OrderStatusPreparing, OrderStatusReady, OrderStatusCompleted, OrderStatusCancelled:
+	case OrderStatusPending, OrderStatusConfirmed, OrderStatusPreparing, OrderStatusReady, OrderStatusInDelivery, OrderStatusCompleted, OrderStatusCancelled:
 		return true
 	}
 	return false
@@ -33,18 +34,99 @@ func (os OrderStatus) String() string {
 	return string(os)
 }
 
+// PaymentStatus represents the payment state of an order
+type PaymentStatus string
+
+const (
+	PaymentStatusUnpaid        PaymentStatus = "unpaid"
+	PaymentStatusPaid          PaymentStatus = "paid"
+	PaymentStatusRefundPending PaymentStatus = "refund_pending"
+	PaymentStatusRefunded      PaymentStatus = "refunded"
+)
+
+// IsValid checks if the payment status is valid
+func (ps PaymentStatus) IsValid() bool {
+	switch ps {
+	case PaymentStatusUnpaid, PaymentStatusPaid, PaymentStatusRefundPending, PaymentStatusRefunded:
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the payment status
+func (ps PaymentStatus) String() string {
+	return string(ps)
+}
+
+// CancellationReason is the reason code given when an order is cancelled
+type CancellationReason string
+
+const (
+	CancellationCustomerRequest  CancellationReason = "customer_request"
+	CancellationOrderedByMistake CancellationReason = "ordered_by_mistake"
+	CancellationTakingTooLong    CancellationReason = "taking_too_long"
+	CancellationOutOfStock       CancellationReason = "out_of_stock"
+	CancellationStoreClosed      CancellationReason = "store_closed"
+	CancellationUnableToDeliver  CancellationReason = "unable_to_deliver"
+	CancellationPaymentIssue     CancellationReason = "payment_issue"
+	CancellationOther            CancellationReason = "other" // Requires a note
+)
+
+// IsValid checks if the cancellation reason is valid
+func (cr CancellationReason) IsValid() bool {
+	switch cr {
+	case CancellationCustomerRequest, CancellationOrderedByMistake, CancellationTakingTooLong,
+		CancellationOutOfStock, CancellationStoreClosed, CancellationUnableToDeliver,
+		CancellationPaymentIssue, CancellationOther:
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the cancellation reason
+func (cr CancellationReason) String() string {
+	return string(cr)
+}
+
 // Order represents a customer order
 type Order struct {
-	ID               uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID           uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
-	StoreID          uuid.UUID      `gorm:"type:uuid;not null" json:"store_id"`
-	DeliveryPersonID *uuid.UUID     `gorm:"type:uuid" json:"delivery_person_id"` // Nullable if not assigned
-	Status           OrderStatus    `gorm:"type:varchar(50);not null;default:'pending'" json:"status"`
-	Total            float64        `gorm:"type:decimal(10,2);not null" json:"total"`
-	Items            []OrderItem    `gorm:"foreignKey:OrderID" json:"items"`
-	CreatedAt        time.Time      `json:"created_at"`
-	UpdatedAt        time.Time      `json:"updated_at"`
-	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+	ID               uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID           uuid.UUID   `gorm:"type:uuid;not null;index:idx_orders_user_created" json:"user_id"`
+	StoreID          uuid.UUID   `gorm:"type:uuid;not null;index:idx_orders_store_created" json:"store_id"`
+	DeliveryPersonID *uuid.UUID  `gorm:"type:uuid" json:"delivery_person_id"` // Nullable if not assigned
+	Status           OrderStatus `gorm:"type:varchar(50);not null;default:'pending'" json:"status"`
+	Subtotal         Money       `gorm:"embedded;embeddedPrefix:subtotal_" json:"subtotal"` // Sum of the items
+	DeliveryFee      Money       `gorm:"embedded;embeddedPrefix:delivery_fee_" json:"delivery_fee"`
+	DeliveryFeeBasis string      `gorm:"size:20" json:"delivery_fee_basis,omitempty"` // Rule that produced the delivery fee
+	SmallOrderFee    Money       `gorm:"embedded;embeddedPrefix:small_order_fee_" json:"small_order_fee"`
+	Total            Money       `gorm:"embedded;embeddedPrefix:total_" json:"total"` // Subtotal plus fees
+	Items            []OrderItem `gorm:"foreignKey:OrderID" json:"items"`
+	PrintJobs        []PrintJob  `gorm:"foreignKey:OrderID" json:"print_jobs,omitempty"` // Documents printed by a copy center
+
+	// Destination: either picked up at the store or delivered to a campus location
+	PickupAtStore        bool            `gorm:"default:false" json:"pickup_at_store"`
+	DeliveryLocationID   *uuid.UUID      `gorm:"type:uuid" json:"delivery_location_id,omitempty"`
+	DeliveryAddressID    *uuid.UUID      `gorm:"type:uuid" json:"delivery_address_id,omitempty"` // Saved address the destination came from, if any
+	DeliveryInstructions string          `gorm:"type:text" json:"delivery_instructions,omitempty"`
+	DeliveryLocation     *CampusLocation `gorm:"foreignKey:DeliveryLocationID" json:"delivery_location,omitempty"`
+	ScheduledFor         *time.Time      `gorm:"index" json:"scheduled_for,omitempty"` // Start of the booked time slot, nil for as soon as possible
+
+	// Payment
+	PaymentStatus PaymentStatus `gorm:"type:varchar(20);not null;default:'unpaid'" json:"payment_status"`
+	PaidAt        *time.Time    `json:"paid_at,omitempty"`
+	Refunds       []Refund      `gorm:"foreignKey:OrderID" json:"refunds,omitempty"`
+
+	// Cancellation, set when the order is cancelled
+	CancellationReason CancellationReason `gorm:"type:varchar(50)" json:"cancellation_reason,omitempty"`
+	CancellationNote   string             `gorm:"type:text" json:"cancellation_note,omitempty"`
+	CancelledBy        *uuid.UUID         `gorm:"type:uuid" json:"cancelled_by,omitempty"`
+	CancelledAt        *time.Time         `json:"cancelled_at,omitempty"`
+
+	PickedUpAt  *time.Time     `json:"picked_up_at,omitempty"` // Set when the courier collects the order
+	DeliveredAt *time.Time     `json:"delivered_at,omitempty"` // Set when the courier hands the order over
+	CreatedAt   time.Time      `gorm:"index:idx_orders_user_created;index:idx_orders_store_created" json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName specifies the table name for Order model
@@ -60,19 +142,27 @@ func (o *Order) BeforeCreate(tx *gorm.DB) error {
 	if o.Status == "" {
 		o.Status = OrderStatusPending
 	}
+	if o.PaymentStatus == "" {
+		o.PaymentStatus = PaymentStatusUnpaid
+	}
 	return nil
 }
 
-// OrderItem represents an item within an order
+// OrderItem represents an item within an order.
+// The chosen variant and modifiers are copied so later catalog changes do not alter the order.
 type OrderItem struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	OrderID   uuid.UUID      `gorm:"type:uuid;not null" json:"order_id"`
-	ProductID uuid.UUID      `gorm:"type:uuid;not null" json:"product_id"`
-	Quantity  int            `gorm:"not null" json:"quantity"`
-	Price     float64        `gorm:"type:decimal(10,2);not null" json:"price"` // Snapshot price at time of order
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID          uuid.UUID           `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID     uuid.UUID           `gorm:"type:uuid;not null" json:"order_id"`
+	ProductID   uuid.UUID           `gorm:"type:uuid;not null" json:"product_id"`
+	VariantID   *uuid.UUID          `gorm:"type:uuid" json:"variant_id,omitempty"`
+	VariantName string              `gorm:"size:100" json:"variant_name,omitempty"` // Snapshot of the variant name
+	SKU         string              `gorm:"size:100" json:"sku,omitempty"`          // Snapshot of the product or variant SKU
+	Quantity    int                 `gorm:"not null" json:"quantity"`
+	Price       Money               `gorm:"embedded;embeddedPrefix:price_" json:"price"` // Snapshot unit price at time of order, modifiers included
+	Modifiers   []OrderItemModifier `gorm:"foreignKey:OrderItemID;constraint:OnDelete:CASCADE" json:"modifiers,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt      `gorm:"index" json:"-"`
 }
 
 // TableName specifies the table name for OrderItem model
@@ -87,3 +177,27 @@ func (oi *OrderItem) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// OrderItemModifier is a snapshot of a modifier chosen for an order item
+type OrderItemModifier struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderItemID uuid.UUID `gorm:"type:uuid;not null;index" json:"order_item_id"`
+	ModifierID  uuid.UUID `gorm:"type:uuid;not null" json:"modifier_id"`
+	GroupName   string    `gorm:"size:100;not null" json:"group_name"`
+	Name        string    `gorm:"size:100;not null" json:"name"`
+	PriceDelta  Money     `gorm:"embedded;embeddedPrefix:price_delta_" json:"price_delta"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for OrderItemModifier model
+func (OrderItemModifier) TableName() string {
+	return "order_item_modifiers"
+}
+
+// BeforeCreate is a GORM hook that runs before creating an order item modifier
+func (m *OrderItemModifier) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrderStatusEvent records a single status change of an order.
+// Events are append-only and form the order timeline.
+type OrderStatusEvent struct {
+	ID            uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID       uuid.UUID   `gorm:"type:uuid;not null;index" json:"order_id"`
+	FromStatus    OrderStatus `gorm:"type:varchar(50)" json:"from_status,omitempty"` // Empty for the creation event
+	ToStatus      OrderStatus `gorm:"type:varchar(50);not null" json:"to_status"`
+	ChangedBy     uuid.UUID   `gorm:"type:uuid;not null" json:"changed_by"`
+	ChangedByRole Role        `gorm:"type:varchar(20);not null" json:"changed_by_role"`
+	Reason        string      `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for OrderStatusEvent model
+func (OrderStatusEvent) TableName() string {
+	return "order_status_events"
+}
+
+// BeforeCreate is a GORM hook that runs before creating an order status event
+func (e *OrderStatusEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
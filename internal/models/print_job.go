@@ -0,0 +1,183 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PrintJobStatus represents the status of a print job
+type PrintJobStatus string
+
+const (
+	PrintJobStatusUploaded  PrintJobStatus = "uploaded" // Uploaded, not ordered yet
+	PrintJobStatusQueued    PrintJobStatus = "queued"   // Ordered, waiting to be printed
+	PrintJobStatusPrinting  PrintJobStatus = "printing"
+	PrintJobStatusPrinted   PrintJobStatus = "printed"
+	PrintJobStatusProblem   PrintJobStatus = "problem" // Flagged by the copy center, see ProblemReason
+	PrintJobStatusCancelled PrintJobStatus = "cancelled"
+)
+
+// IsValid checks if the print job status is valid
+func (ps PrintJobStatus) IsValid() bool {
+	switch ps {
+	case PrintJobStatusUploaded, PrintJobStatusQueued, PrintJobStatusPrinting, PrintJobStatusPrinted,
+		PrintJobStatusProblem, PrintJobStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the print job status
+func (ps PrintJobStatus) String() string {
+	return string(ps)
+}
+
+// PrintProblemReason is the reason code given when a print job cannot be printed
+type PrintProblemReason string
+
+const (
+	PrintProblemCorruptFile  PrintProblemReason = "corrupt_file"
+	PrintProblemOutOfToner   PrintProblemReason = "out_of_toner"
+	PrintProblemOutOfPaper   PrintProblemReason = "out_of_paper"
+	PrintProblemPrinterError PrintProblemReason = "printer_error"
+	PrintProblemOther        PrintProblemReason = "other" // Requires a note
+)
+
+// IsValid checks if the problem reason is valid
+func (pr PrintProblemReason) IsValid() bool {
+	switch pr {
+	case PrintProblemCorruptFile, PrintProblemOutOfToner, PrintProblemOutOfPaper, PrintProblemPrinterError, PrintProblemOther:
+		return true
+	}
+	return false
+}
+
+// PrintColorMode represents whether a document is printed in color or black and white
+type PrintColorMode string
+
+const (
+	PrintColorBW    PrintColorMode = "bw"
+	PrintColorColor PrintColorMode = "color"
+)
+
+// IsValid checks if the color mode is valid
+func (cm PrintColorMode) IsValid() bool {
+	return cm == PrintColorBW || cm == PrintColorColor
+}
+
+// PrintBinding represents how the printed copies are bound
+type PrintBinding string
+
+const (
+	PrintBindingNone    PrintBinding = "none"
+	PrintBindingStaple  PrintBinding = "staple"
+	PrintBindingSpiral  PrintBinding = "spiral"
+	PrintBindingThermal PrintBinding = "thermal"
+)
+
+// IsValid checks if the binding is valid
+func (pb PrintBinding) IsValid() bool {
+	switch pb {
+	case PrintBindingNone, PrintBindingStaple, PrintBindingSpiral, PrintBindingThermal:
+		return true
+	}
+	return false
+}
+
+// PrintJob is an uploaded document to be printed by a copy center.
+// It is created when the document is uploaded and becomes part of an order at checkout.
+type PrintJob struct {
+	ID      uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	StoreID uuid.UUID      `gorm:"type:uuid;not null;index" json:"store_id"`
+	OrderID *uuid.UUID     `gorm:"type:uuid;index" json:"order_id,omitempty"` // Set once the job is ordered
+	Status  PrintJobStatus `gorm:"type:varchar(20);not null;default:'uploaded'" json:"status"`
+
+	// Document
+	FileName   string `gorm:"size:255;not null" json:"file_name"`
+	StorageKey string `gorm:"size:500;not null" json:"-"`
+	FileSize   int64  `gorm:"not null" json:"file_size"`
+	PageCount  int    `gorm:"not null" json:"page_count"` // Pages in the document
+
+	// Options
+	ColorMode  PrintColorMode `gorm:"type:varchar(10);not null;default:'bw'" json:"color_mode"`
+	Duplex     bool           `gorm:"default:false" json:"duplex"`
+	Copies     int            `gorm:"not null;default:1" json:"copies"`
+	PageRanges string         `gorm:"size:200" json:"page_ranges,omitempty"` // e.g. "1-3,5"; empty prints every page
+	Binding    PrintBinding   `gorm:"type:varchar(20);not null;default:'none'" json:"binding"`
+	Notes      string         `gorm:"type:text" json:"notes,omitempty"`
+
+	// Pricing
+	SelectedPages int   `gorm:"not null" json:"selected_pages"` // Pages printed per copy
+	Price         Money `gorm:"embedded;embeddedPrefix:price_" json:"price"`
+
+	// Copy center queue
+	PromisedAt    *time.Time         `gorm:"index" json:"promised_at,omitempty"` // When the copies should be ready, set when ordered
+	StartedAt     *time.Time         `json:"started_at,omitempty"`
+	PrintedAt     *time.Time         `json:"printed_at,omitempty"`
+	ProblemReason PrintProblemReason `gorm:"type:varchar(30)" json:"problem_reason,omitempty"`
+	ProblemNote   string             `gorm:"type:text" json:"problem_note,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for PrintJob model
+func (PrintJob) TableName() string {
+	return "print_jobs"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a print job
+func (pj *PrintJob) BeforeCreate(tx *gorm.DB) error {
+	if pj.ID == uuid.Nil {
+		pj.ID = uuid.New()
+	}
+	if pj.Status == "" {
+		pj.Status = PrintJobStatusUploaded
+	}
+	return nil
+}
+
+// PrintPricing holds the printing rates of a copy center store.
+// Page prices are per printed page side; binding prices are per copy.
+type PrintPricing struct {
+	ID                  uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	StoreID             uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"store_id"`
+	BWPagePrice         Money     `gorm:"embedded;embeddedPrefix:bw_page_price_" json:"bw_page_price"`
+	ColorPagePrice      Money     `gorm:"embedded;embeddedPrefix:color_page_price_" json:"color_page_price"`
+	StapleBindingPrice  Money     `gorm:"embedded;embeddedPrefix:staple_binding_price_" json:"staple_binding_price"`
+	SpiralBindingPrice  Money     `gorm:"embedded;embeddedPrefix:spiral_binding_price_" json:"spiral_binding_price"`
+	ThermalBindingPrice Money     `gorm:"embedded;embeddedPrefix:thermal_binding_price_" json:"thermal_binding_price"`
+	TurnaroundMinutes   int       `gorm:"not null;default:60" json:"turnaround_minutes"` // Promised time after ordering
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for PrintPricing model
+func (PrintPricing) TableName() string {
+	return "print_pricing"
+}
+
+// BeforeCreate is a GORM hook that runs before creating print pricing
+func (pp *PrintPricing) BeforeCreate(tx *gorm.DB) error {
+	if pp.ID == uuid.Nil {
+		pp.ID = uuid.New()
+	}
+	return nil
+}
+
+// BindingPrice returns the price per copy of a binding
+func (pp *PrintPricing) BindingPrice(binding PrintBinding) Money {
+	switch binding {
+	case PrintBindingStaple:
+		return pp.StapleBindingPrice
+	case PrintBindingSpiral:
+		return pp.SpiralBindingPrice
+	case PrintBindingThermal:
+		return pp.ThermalBindingPrice
+	}
+	return NewMoney(0, pp.BWPagePrice.Currency)
+}
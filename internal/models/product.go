@@ -36,19 +36,27 @@ func (pc ProductCategory) String() string {
 
 // Product represents a product in the store
 type Product struct {
-	ID          uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Name        string          `gorm:"size:200;not null" json:"name"`
-	Description string          `gorm:"type:text" json:"description"`
-	Category    ProductCategory `gorm:"type:varchar(50);not null" json:"category"`
-	Price       float64         `gorm:"type:decimal(10,2);not null" json:"price"`
-	Stock       int             `gorm:"not null;default:0" json:"stock"`
-	StoreID     uuid.UUID       `gorm:"type:uuid;not null" json:"store_id"` // Foreign key to Store
-	SKU         string          `gorm:"size:100;uniqueIndex" json:"sku,omitempty"`
-	ImageURL    string          `gorm:"size:500" json:"image_url,omitempty"`
-	IsActive    bool            `gorm:"default:true" json:"is_active"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt  `gorm:"index" json:"-"` // Soft delete
+	ID                uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name              string          `gorm:"size:200;not null" json:"name"`
+	Description       string          `gorm:"type:text" json:"description"`
+	Tags              []string        `gorm:"type:jsonb;serializer:json;not null;default:'[]'" json:"tags"` // Extra search terms
+	Category          ProductCategory `gorm:"type:varchar(50);not null" json:"category"`
+	Price             Money           `gorm:"embedded;embeddedPrefix:price_" json:"price"`
+	Stock             int             `gorm:"not null;default:0" json:"stock"`
+	StoreID           uuid.UUID       `gorm:"type:uuid;not null" json:"store_id"` // Foreign key to Store
+	SKU               string          `gorm:"size:100;uniqueIndex" json:"sku,omitempty"`
+	ImageURL          string          `gorm:"size:500" json:"image_url,omitempty"`
+	IsActive          bool            `gorm:"default:true" json:"is_active"`
+	ReorderThreshold  int             `gorm:"not null;default:0" json:"reorder_threshold"` // Store members are alerted when stock drops to this level, 0 disables alerts
+	AutoHide          bool            `gorm:"not null;default:false" json:"auto_hide"`     // Hidden from product listings while out of stock
+	LowStockAlertedAt *time.Time      `json:"low_stock_alerted_at,omitempty"`              // Set once the alert of the current shortage was sent
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt  `gorm:"index" json:"-"` // Soft delete
+
+	// Relationships, managed through their own endpoints
+	Variants     []ProductVariant     `gorm:"foreignKey:ProductID" json:"variants,omitempty"`
+	OptionGroups []ProductOptionGroup `gorm:"foreignKey:ProductID" json:"option_groups,omitempty"`
 }
 
 // TableName specifies the table name for Product model
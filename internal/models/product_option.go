@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductVariant is a version of a product that is sold on its own, such as a size or a color.
+// Each variant has its own SKU, price and stock; products with active variants are ordered by variant.
+type ProductVariant struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID uuid.UUID      `gorm:"type:uuid;not null;index" json:"product_id"`
+	Name      string         `gorm:"size:100;not null" json:"name"` // e.g. "Large" or "Blue"
+	SKU       string         `gorm:"size:100;not null;uniqueIndex" json:"sku"`
+	Price     Money          `gorm:"embedded;embeddedPrefix:price_" json:"price"`
+	Stock     int            `gorm:"not null;default:0" json:"stock"`
+	IsActive  bool           `gorm:"default:true" json:"is_active"`
+	SortOrder int            `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete, ordered variants stay referenced
+}
+
+// TableName specifies the table name for ProductVariant model
+func (ProductVariant) TableName() string {
+	return "product_variants"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a product variant
+func (v *ProductVariant) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// ProductOptionGroup is a set of modifiers customers choose from, such as milk options of a coffee.
+// Customers pick between MinSelections and MaxSelections modifiers of the group.
+type ProductOptionGroup struct {
+	ID            uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID     uuid.UUID         `gorm:"type:uuid;not null;index" json:"product_id"`
+	Name          string            `gorm:"size:100;not null" json:"name"`
+	Required      bool              `gorm:"default:false" json:"required"`            // At least one modifier must be chosen
+	MinSelections int               `gorm:"not null;default:0" json:"min_selections"` // At least 1 for required groups
+	MaxSelections int               `gorm:"not null;default:0" json:"max_selections"` // 0 means no limit
+	SortOrder     int               `gorm:"not null;default:0" json:"sort_order"`
+	Modifiers     []ProductModifier `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE" json:"modifiers"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// TableName specifies the table name for ProductOptionGroup model
+func (ProductOptionGroup) TableName() string {
+	return "product_option_groups"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a product option group
+func (g *ProductOptionGroup) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	return nil
+}
+
+// ProductModifier is a choice within an option group that changes the item price by PriceDelta
+type ProductModifier struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	GroupID    uuid.UUID `gorm:"type:uuid;not null;index" json:"group_id"`
+	Name       string    `gorm:"size:100;not null" json:"name"`                           // e.g. "Oat milk"
+	PriceDelta Money     `gorm:"embedded;embeddedPrefix:price_delta_" json:"price_delta"` // May be negative
+	IsActive   bool      `gorm:"default:true" json:"is_active"`
+	SortOrder  int       `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for ProductModifier model
+func (ProductModifier) TableName() string {
+	return "product_modifiers"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a product modifier
+func (m *ProductModifier) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefundStatus represents the processing state of a refund
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "pending"
+	RefundStatusProcessed RefundStatus = "processed"
+	RefundStatusFailed    RefundStatus = "failed"
+)
+
+// IsValid checks if the refund status is valid
+func (rs RefundStatus) IsValid() bool {
+	switch rs {
+	case RefundStatusPending, RefundStatusProcessed, RefundStatusFailed:
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the refund status
+func (rs RefundStatus) String() string {
+	return string(rs)
+}
+
+// Refund records money owed back to the customer of a paid order.
+// Refunds are created pending and settled by the payment processing.
+type Refund struct {
+	ID          uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID     uuid.UUID          `gorm:"type:uuid;not null;index" json:"order_id"`
+	Amount      Money              `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	Status      RefundStatus       `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Reason      CancellationReason `gorm:"type:varchar(50);not null" json:"reason"`
+	Note        string             `gorm:"type:text" json:"note,omitempty"`
+	RequestedBy uuid.UUID          `gorm:"type:uuid;not null" json:"requested_by"`
+	ProcessedAt *time.Time         `json:"processed_at,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// TableName specifies the table name for Refund model
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a refund
+func (r *Refund) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	if r.Status == "" {
+		r.Status = RefundStatusPending
+	}
+	return nil
+}
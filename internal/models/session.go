@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SessionRevocation is the reason a session was ended before it expired
+type SessionRevocation string
+
+const (
+	SessionLogout         SessionRevocation = "logout"
+	SessionPasswordChange SessionRevocation = "password_change"
+	SessionPasswordReset  SessionRevocation = "password_reset"
+	SessionTokenReuse     SessionRevocation = "token_reuse"  // A rotated refresh token was presented again
+	SessionAdminRevoke    SessionRevocation = "admin_revoke" // Ended by a superuser
+)
+
+// UserSession is a signed-in device of a user. It lasts as long as its refresh tokens keep
+// being rotated and ends when it expires or is revoked.
+type UserSession struct {
+	ID               uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID           uuid.UUID         `gorm:"type:uuid;not null;index" json:"user_id"`
+	UserAgent        string            `gorm:"size:500" json:"user_agent"`
+	IPAddress        string            `gorm:"size:45" json:"ip_address"`
+	LastUsedAt       time.Time         `gorm:"not null" json:"last_used_at"` // Last sign in or refresh
+	ExpiresAt        time.Time         `gorm:"not null;index" json:"expires_at"`
+	RevokedAt        *time.Time        `json:"revoked_at,omitempty"`
+	RevocationReason SessionRevocation `gorm:"type:varchar(20)" json:"revocation_reason,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for UserSession model
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a session
+func (s *UserSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive checks if the session is neither revoked nor expired
+func (s *UserSession) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// RefreshToken is a refresh token issued to a session. Only its SHA-256 hash is stored.
+// Tokens are single use: a refresh marks the token used and issues the next one of the session,
+// so a used token presented again means it was stolen.
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SessionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"session_id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"` // Set when the token was rotated
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relationships
+	Session UserSession `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for RefreshToken model
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a refresh token
+func (t *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockMovementType represents why the stock of a product changed
+type StockMovementType string
+
+const (
+	StockMovementSale       StockMovementType = "sale"       // Sold through an order
+	StockMovementRestock    StockMovementType = "restock"    // New goods received
+	StockMovementAdjustment StockMovementType = "adjustment" // Correction, e.g. after a stock count
+	StockMovementReturn     StockMovementType = "return"     // Returned to stock, e.g. by a cancelled order
+	StockMovementSpoilage   StockMovementType = "spoilage"   // Waste, damage or expiry
+)
+
+// IsValid checks if the stock movement type is valid
+func (t StockMovementType) IsValid() bool {
+	switch t {
+	case StockMovementSale, StockMovementRestock, StockMovementAdjustment, StockMovementReturn, StockMovementSpoilage:
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the stock movement type
+func (t StockMovementType) String() string {
+	return string(t)
+}
+
+// StockMovement is an entry of the append-only inventory ledger.
+// The stock of a product or variant is the sum of its movements; the stock column
+// of products and variants caches that sum and is updated with every movement.
+type StockMovement struct {
+	ID         uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID  uuid.UUID         `gorm:"type:uuid;not null;index:idx_stock_movements_product" json:"product_id"`
+	VariantID  *uuid.UUID        `gorm:"type:uuid;index" json:"variant_id,omitempty"` // Set for movements of variant stock
+	Type       StockMovementType `gorm:"type:varchar(20);not null" json:"type"`
+	Quantity   int               `gorm:"not null" json:"quantity"`    // Signed change, negative when stock leaves
+	StockAfter int               `gorm:"not null" json:"stock_after"` // Stock once the movement was applied
+	OrderID    *uuid.UUID        `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	ActorID    *uuid.UUID        `gorm:"type:uuid" json:"actor_id,omitempty"` // Empty for entries created by migrations
+	ActorRole  Role              `gorm:"type:varchar(20)" json:"actor_role,omitempty"`
+	Reason     string            `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt  time.Time         `gorm:"index:idx_stock_movements_product" json:"created_at"`
+}
+
+// TableName specifies the table name for StockMovement model
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a stock movement
+func (m *StockMovement) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -13,8 +13,14 @@ type Store struct {
 	Name        string         `gorm:"size:200;not null" json:"name"`
 	Description string         `gorm:"type:text" json:"description"`
 	Location    string         `gorm:"size:200" json:"location"`
-	OwnerID     uuid.UUID      `gorm:"type:uuid;not null" json:"owner_id"` // User who manages this store
+	LocationID  *uuid.UUID     `gorm:"type:uuid" json:"location_id,omitempty"` // Campus location catalog entry
+	Zone        string         `gorm:"size:50;index" json:"zone,omitempty"`    // Campus zone used to dispatch nearby couriers
+	OwnerID     uuid.UUID      `gorm:"type:uuid;not null" json:"owner_id"`     // User who created the store; access is granted through store members
 	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	TimeZone    string         `gorm:"size:64;not null;default:'UTC'" json:"time_zone"` // IANA zone of the opening hours, e.g. America/Caracas
+	IsPaused    bool           `gorm:"default:false" json:"is_paused"`                  // Temporarily not accepting orders
+	PausedUntil *time.Time     `json:"paused_until,omitempty"`                          // Pause ends automatically at this time, if set
+	PauseReason string         `gorm:"size:200" json:"pause_reason,omitempty"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete
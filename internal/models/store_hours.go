@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StoreOpeningHours is a weekly opening period of a store, in the store time zone.
+// Times use the HH:MM format; a period that closes at or before its opening time ends on the next day.
+type StoreOpeningHours struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	StoreID   uuid.UUID `gorm:"type:uuid;not null;index" json:"store_id"`
+	Weekday   int       `gorm:"not null" json:"weekday"` // 0 = Sunday ... 6 = Saturday
+	OpensAt   string    `gorm:"size:5;not null" json:"opens_at"`
+	ClosesAt  string    `gorm:"size:5;not null" json:"closes_at"` // 24:00 closes at midnight
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for StoreOpeningHours model
+func (StoreOpeningHours) TableName() string {
+	return "store_opening_hours"
+}
+
+// BeforeCreate is a GORM hook that runs before creating an opening period
+func (h *StoreOpeningHours) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
+
+// StoreSpecialHours overrides the weekly schedule of a store for a range of dates,
+// e.g. a holiday closure or reduced hours during exam week.
+// Dates use the YYYY-MM-DD format in the store time zone and include both ends.
+type StoreSpecialHours struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	StoreID   uuid.UUID `gorm:"type:uuid;not null;index" json:"store_id"`
+	StartDate string    `gorm:"size:10;not null;index" json:"start_date"`
+	EndDate   string    `gorm:"size:10;not null;index" json:"end_date"`
+	Closed    bool      `gorm:"default:false" json:"closed"`      // Closed for the whole day
+	OpensAt   string    `gorm:"size:5" json:"opens_at,omitempty"` // Hours for each day when not closed
+	ClosesAt  string    `gorm:"size:5" json:"closes_at,omitempty"`
+	Reason    string    `gorm:"size:200" json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for StoreSpecialHours model
+func (StoreSpecialHours) TableName() string {
+	return "store_special_hours"
+}
+
+// BeforeCreate is a GORM hook that runs before creating special hours
+func (h *StoreSpecialHours) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
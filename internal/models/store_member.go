@@ -0,0 +1,158 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StoreMemberRole represents the role of a user within a store
+type StoreMemberRole string
+
+const (
+	StoreMemberOwner   StoreMemberRole = "owner"   // Full control, including members and deleting the store
+	StoreMemberManager StoreMemberRole = "manager" // Runs the store: catalog, schedule and staff
+	StoreMemberStaff   StoreMemberRole = "staff"   // Handles orders and the print queue, e.g. cashiers
+)
+
+// IsValid checks if the store member role is valid
+func (r StoreMemberRole) IsValid() bool {
+	switch r {
+	case StoreMemberOwner, StoreMemberManager, StoreMemberStaff:
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the store member role
+func (r StoreMemberRole) String() string {
+	return string(r)
+}
+
+// Rank orders the roles from staff (1) to owner (3)
+func (r StoreMemberRole) Rank() int {
+	switch r {
+	case StoreMemberOwner:
+		return 3
+	case StoreMemberManager:
+		return 2
+	case StoreMemberStaff:
+		return 1
+	}
+	return 0
+}
+
+// StorePermission is an action store members may be allowed to perform
+type StorePermission string
+
+const (
+	PermissionManageStore    StorePermission = "manage_store"    // Edit or delete the store
+	PermissionManageMembers  StorePermission = "manage_members"  // Invite, change and remove members
+	PermissionManageSchedule StorePermission = "manage_schedule" // Opening hours, pause mode and time slots
+	PermissionManageProducts StorePermission = "manage_products" // Catalog, stock and print pricing
+	PermissionManageOrders   StorePermission = "manage_orders"   // View and progress store orders
+	PermissionManagePrinting StorePermission = "manage_printing" // Work the print queue
+)
+
+// storeRolePermissions lists the permissions granted by each store member role
+var storeRolePermissions = map[StoreMemberRole][]StorePermission{
+	StoreMemberOwner: {
+		PermissionManageStore, PermissionManageMembers, PermissionManageSchedule,
+		PermissionManageProducts, PermissionManageOrders, PermissionManagePrinting,
+	},
+	StoreMemberManager: {
+		PermissionManageMembers, PermissionManageSchedule,
+		PermissionManageProducts, PermissionManageOrders, PermissionManagePrinting,
+	},
+	StoreMemberStaff: {
+		PermissionManageOrders, PermissionManagePrinting,
+	},
+}
+
+// Can reports whether the role grants a permission
+func (r StoreMemberRole) Can(permission StorePermission) bool {
+	for _, granted := range storeRolePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns the permissions granted by the role
+func (r StoreMemberRole) Permissions() []StorePermission {
+	return storeRolePermissions[r]
+}
+
+// StoreMember links a user to a store they work at
+type StoreMember struct {
+	ID        uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	StoreID   uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_store_member_store_user" json:"store_id"`
+	UserID    uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_store_member_store_user;index" json:"user_id"`
+	Role      StoreMemberRole `gorm:"type:varchar(20);not null" json:"role"`
+	InvitedBy *uuid.UUID      `gorm:"type:uuid" json:"invited_by,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+
+	// Relationships
+	User  *User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Store *Store `gorm:"foreignKey:StoreID" json:"store,omitempty"`
+}
+
+// TableName specifies the table name for StoreMember model
+func (StoreMember) TableName() string {
+	return "store_members"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a store member
+func (m *StoreMember) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// StoreInvitationStatus represents the state of a store invitation
+type StoreInvitationStatus string
+
+const (
+	StoreInvitationPending  StoreInvitationStatus = "pending"
+	StoreInvitationAccepted StoreInvitationStatus = "accepted"
+	StoreInvitationRevoked  StoreInvitationStatus = "revoked"
+)
+
+// StoreInvitation invites a person by email to join a store with a role.
+// Only the SHA-256 hash of the acceptance token is stored; the token itself is emailed.
+type StoreInvitation struct {
+	ID         uuid.UUID             `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	StoreID    uuid.UUID             `gorm:"type:uuid;not null;index" json:"store_id"`
+	Email      string                `gorm:"size:255;not null;index" json:"email"`
+	Role       StoreMemberRole       `gorm:"type:varchar(20);not null" json:"role"`
+	TokenHash  string                `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Status     StoreInvitationStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	InvitedBy  uuid.UUID             `gorm:"type:uuid;not null" json:"invited_by"`
+	ExpiresAt  time.Time             `gorm:"not null" json:"expires_at"`
+	AcceptedBy *uuid.UUID            `gorm:"type:uuid" json:"accepted_by,omitempty"`
+	AcceptedAt *time.Time            `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+}
+
+// TableName specifies the table name for StoreInvitation model
+func (StoreInvitation) TableName() string {
+	return "store_invitations"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a store invitation
+func (i *StoreInvitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired checks if the invitation can no longer be accepted
+func (i *StoreInvitation) IsExpired() bool {
+	return time.Now().After(i.ExpiresAt)
+}
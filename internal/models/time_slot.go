@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SlotMinutes is the length of the time slots orders can be scheduled in
+const SlotMinutes = 15
+
+// TimeSlotSettings configures scheduled pickup and delivery orders of a store.
+// Slots follow the store opening hours; a capacity of 0 disables scheduled orders.
+type TimeSlotSettings struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	StoreID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"store_id"`
+	Capacity     int       `gorm:"not null;default:0" json:"capacity"`       // Maximum orders per slot
+	LeadMinutes  int       `gorm:"not null;default:15" json:"lead_minutes"`  // Minimum time between ordering and the slot
+	MaxDaysAhead int       `gorm:"not null;default:7" json:"max_days_ahead"` // How far ahead orders can be scheduled
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for TimeSlotSettings model
+func (TimeSlotSettings) TableName() string {
+	return "time_slot_settings"
+}
+
+// BeforeCreate is a GORM hook that runs before creating time slot settings
+func (ts *TimeSlotSettings) BeforeCreate(tx *gorm.DB) error {
+	if ts.ID == uuid.Nil {
+		ts.ID = uuid.New()
+	}
+	return nil
+}
+
+// TimeSlotBooking counts the orders scheduled in a time slot of a store
+type TimeSlotBooking struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	StoreID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_time_slot_booking_store_start" json:"store_id"`
+	StartsAt  time.Time `gorm:"not null;uniqueIndex:idx_time_slot_booking_store_start" json:"starts_at"`
+	Booked    int       `gorm:"not null;default:0" json:"booked"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for TimeSlotBooking model
+func (TimeSlotBooking) TableName() string {
+	return "time_slot_bookings"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a time slot booking
+func (b *TimeSlotBooking) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
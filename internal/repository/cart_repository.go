@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// CartRepository handles database operations for shopping carts
+type CartRepository struct {
+	db *gorm.DB
+}
+
+// NewCartRepository creates a new cart repository
+func NewCartRepository(db *gorm.DB) *CartRepository {
+	return &CartRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *CartRepository) WithTx(tx *gorm.DB) *CartRepository {
+	return &CartRepository{db: tx}
+}
+
+// GetOrCreate returns the cart of a user at a store, creating an empty one if needed
+func (r *CartRepository) GetOrCreate(userID, storeID uuid.UUID, expiresAt time.Time) (*models.Cart, error) {
+	cart := &models.Cart{UserID: userID, StoreID: storeID, ExpiresAt: expiresAt}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "store_id"}},
+		DoNothing: true,
+	}).Create(cart).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByUserAndStore(userID, storeID)
+}
+
+// GetByUserAndStore finds the cart of a user at a store with its items
+func (r *CartRepository) GetByUserAndStore(userID, storeID uuid.UUID) (*models.Cart, error) {
+	var cart models.Cart
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at asc")
+	}).Where("user_id = ? AND store_id = ?", userID, storeID).First(&cart).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cart not found")
+		}
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// ListByUser retrieves the carts of a user that have not expired, most recently updated first
+func (r *CartRepository) ListByUser(userID uuid.UUID, now time.Time) ([]models.Cart, error) {
+	var carts []models.Cart
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at asc")
+	}).Where("user_id = ? AND expires_at > ?", userID, now).Order("updated_at desc").Find(&carts).Error
+	return carts, err
+}
+
+// Touch extends the expiry of a cart
+func (r *CartRepository) Touch(id uuid.UUID, expiresAt time.Time) error {
+	return r.db.Model(&models.Cart{}).Where("id = ?", id).Update("expires_at", expiresAt).Error
+}
+
+// SaveItem creates a cart item or replaces the quantity and price of the item with the
+// same configuration
+func (r *CartRepository) SaveItem(item *models.CartItem) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cart_id"}, {Name: "configuration_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "added_price_amount", "added_price_currency", "updated_at"}),
+	}).Create(item).Error
+}
+
+// DeleteItem removes an item from a cart.
+// It returns false when the item was not in the cart.
+func (r *CartRepository) DeleteItem(cartID, itemID uuid.UUID) (bool, error) {
+	result := r.db.Where("cart_id = ? AND id = ?", cartID, itemID).Delete(&models.CartItem{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Delete removes a cart and its items
+func (r *CartRepository) Delete(id uuid.UUID) error {
+	if err := r.db.Where("cart_id = ?", id).Delete(&models.CartItem{}).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(&models.Cart{}, "id = ?", id).Error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// CourierRepository handles database operations for courier availability
+type CourierRepository struct {
+	db *gorm.DB
+}
+
+// NewCourierRepository creates a new courier repository
+func NewCourierRepository(db *gorm.DB) *CourierRepository {
+	return &CourierRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *CourierRepository) WithTx(tx *gorm.DB) *CourierRepository {
+	return &CourierRepository{db: tx}
+}
+
+// Upsert creates or updates the availability record of a courier
+func (r *CourierRepository) Upsert(availability *models.CourierAvailability) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "zone", "last_seen_at", "updated_at"}),
+	}).Create(availability).Error
+}
+
+// GetByUserID finds the availability record of a courier
+func (r *CourierRepository) GetByUserID(userID uuid.UUID) (*models.CourierAvailability, error) {
+	var availability models.CourierAvailability
+	err := r.db.Where("user_id = ?", userID).First(&availability).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("courier availability not found")
+		}
+		return nil, err
+	}
+	return &availability, nil
+}
+
+// ListByStatus returns all couriers with the given status
+func (r *CourierRepository) ListByStatus(status models.CourierStatus) ([]models.CourierAvailability, error) {
+	var couriers []models.CourierAvailability
+	err := r.db.Where("status = ?", status).Order("user_id").Find(&couriers).Error
+	return couriers, err
+}
+
+// UpdateStatus changes the status of a courier only if it currently has the expected status
+func (r *CourierRepository) UpdateStatus(userID uuid.UUID, from, to models.CourierStatus) error {
+	return r.db.Model(&models.CourierAvailability{}).
+		Where("user_id = ? AND status = ?", userID, from).
+		Update("status", to).Error
+}
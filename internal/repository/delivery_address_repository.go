@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// DeliveryAddressRepository handles database operations for saved delivery addresses
+type DeliveryAddressRepository struct {
+	db *gorm.DB
+}
+
+// NewDeliveryAddressRepository creates a new delivery address repository
+func NewDeliveryAddressRepository(db *gorm.DB) *DeliveryAddressRepository {
+	return &DeliveryAddressRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *DeliveryAddressRepository) WithTx(tx *gorm.DB) *DeliveryAddressRepository {
+	return &DeliveryAddressRepository{db: tx}
+}
+
+// Create creates a new delivery address
+func (r *DeliveryAddressRepository) Create(address *models.DeliveryAddress) error {
+	return r.db.Create(address).Error
+}
+
+// GetByID finds a delivery address by ID with its location
+func (r *DeliveryAddressRepository) GetByID(id uuid.UUID) (*models.DeliveryAddress, error) {
+	var address models.DeliveryAddress
+	err := r.db.Preload("Location").Where("id = ?", id).First(&address).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("address not found")
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+// ListByUser returns the saved addresses of a user, default first
+func (r *DeliveryAddressRepository) ListByUser(userID uuid.UUID) ([]*models.DeliveryAddress, error) {
+	var addresses []*models.DeliveryAddress
+	err := r.db.Preload("Location").
+		Where("user_id = ?", userID).
+		Order("is_default DESC, label ASC").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+// Update updates a delivery address
+func (r *DeliveryAddressRepository) Update(address *models.DeliveryAddress) error {
+	return r.db.Omit("Location").Save(address).Error
+}
+
+// Delete soft deletes a delivery address
+func (r *DeliveryAddressRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.DeliveryAddress{}, id).Error
+}
+
+// ClearDefault unsets the default flag on every address of a user
+func (r *DeliveryAddressRepository) ClearDefault(userID uuid.UUID) error {
+	return r.db.Model(&models.DeliveryAddress{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
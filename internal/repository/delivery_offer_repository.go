@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// DeliveryOfferRepository handles database operations for delivery offers
+type DeliveryOfferRepository struct {
+	db *gorm.DB
+}
+
+// NewDeliveryOfferRepository creates a new delivery offer repository
+func NewDeliveryOfferRepository(db *gorm.DB) *DeliveryOfferRepository {
+	return &DeliveryOfferRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *DeliveryOfferRepository) WithTx(tx *gorm.DB) *DeliveryOfferRepository {
+	return &DeliveryOfferRepository{db: tx}
+}
+
+// Create creates a new delivery offer
+func (r *DeliveryOfferRepository) Create(offer *models.DeliveryOffer) error {
+	return r.db.Create(offer).Error
+}
+
+// GetByID finds a delivery offer by ID
+func (r *DeliveryOfferRepository) GetByID(id uuid.UUID) (*models.DeliveryOffer, error) {
+	var offer models.DeliveryOffer
+	err := r.db.Where("id = ?", id).First(&offer).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("offer not found")
+		}
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// ListPendingByCourier returns the open offers of a courier with their orders
+func (r *DeliveryOfferRepository) ListPendingByCourier(courierID uuid.UUID) ([]models.DeliveryOffer, error) {
+	var offers []models.DeliveryOffer
+	err := r.db.Preload("Order.Items").
+		Where("courier_id = ? AND status = ?", courierID, models.DeliveryOfferPending).
+		Order("expires_at asc").
+		Find(&offers).Error
+	return offers, err
+}
+
+// ListExpired returns pending offers whose acceptance window has passed
+func (r *DeliveryOfferRepository) ListExpired(now time.Time) ([]models.DeliveryOffer, error) {
+	var offers []models.DeliveryOffer
+	err := r.db.Where("status = ? AND expires_at <= ?", models.DeliveryOfferPending, now).Find(&offers).Error
+	return offers, err
+}
+
+// HasPendingForOrder checks if an order currently has an open offer
+func (r *DeliveryOfferRepository) HasPendingForOrder(orderID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.DeliveryOffer{}).
+		Where("order_id = ? AND status = ?", orderID, models.DeliveryOfferPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListOfferedCourierIDs returns the couriers that were offered an order after the given time
+func (r *DeliveryOfferRepository) ListOfferedCourierIDs(orderID uuid.UUID, since time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.DeliveryOffer{}).
+		Where("order_id = ? AND created_at > ?", orderID, since).
+		Distinct().
+		Pluck("courier_id", &ids).Error
+	return ids, err
+}
+
+// ListCouriersWithPendingOffers returns the couriers that currently hold an open offer
+func (r *DeliveryOfferRepository) ListCouriersWithPendingOffers() ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.DeliveryOffer{}).
+		Where("status = ?", models.DeliveryOfferPending).
+		Distinct().
+		Pluck("courier_id", &ids).Error
+	return ids, err
+}
+
+// Resolve moves a pending offer to its final status.
+// It returns false when the offer was no longer pending.
+func (r *DeliveryOfferRepository) Resolve(id uuid.UUID, status models.DeliveryOfferStatus, at time.Time) (bool, error) {
+	result := r.db.Model(&models.DeliveryOffer{}).
+		Where("id = ? AND status = ?", id, models.DeliveryOfferPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": at})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// WithdrawPendingForOrder withdraws every open offer of an order
+func (r *DeliveryOfferRepository) WithdrawPendingForOrder(orderID uuid.UUID) error {
+	return r.db.Model(&models.DeliveryOffer{}).
+		Where("order_id = ? AND status = ?", orderID, models.DeliveryOfferPending).
+		Update("status", models.DeliveryOfferWithdrawn).Error
+}
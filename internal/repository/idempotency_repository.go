@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// IdempotencyRepository handles database operations for idempotency keys
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency key repository
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve stores a new key unless the user already used it.
+// It returns false when the key exists.
+func (r *IdempotencyRepository) Reserve(key *models.IdempotencyKey) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "key"}},
+		DoNothing: true,
+	}).Create(key)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetByUserAndKey finds a key sent by a user
+func (r *IdempotencyRepository) GetByUserAndKey(userID uuid.UUID, key string) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	err := r.db.Where("user_id = ? AND key = ?", userID, key).First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("idempotency key not found")
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Complete stores the response of the request made with the key
+func (r *IdempotencyRepository) Complete(id uuid.UUID, code int, body []byte, contentType string) error {
+	return r.db.Model(&models.IdempotencyKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":        models.IdempotencyCompleted,
+		"response_code": code,
+		"response_body": body,
+		"content_type":  contentType,
+	}).Error
+}
+
+// Delete removes a key so that the request can be retried
+func (r *IdempotencyRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.IdempotencyKey{}, "id = ?", id).Error
+}
+
+// DeleteExpired removes the keys that expired before the given time
+func (r *IdempotencyRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
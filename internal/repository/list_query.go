@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/utils"
+)
+
+// fieldKind is the type of the values of a list field
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	enumField
+	integerField
+	boolField
+	timeField
+	uuidField
+)
+
+// listField is a field a list can be filtered and sorted by
+type listField struct {
+	column string
+	kind   fieldKind
+	valid  func(string) bool // Accepted values of enum fields
+}
+
+// listSchema whitelists the fields of a list by their query name
+type listSchema map[string]listField
+
+// Fields of the lists, named after their JSON fields. Money fields compare amounts in minor units.
+var (
+	orderListSchema = listSchema{
+		"status":             {column: "orders.status", kind: enumField, valid: func(v string) bool { return models.OrderStatus(v).IsValid() }},
+		"payment_status":     {column: "orders.payment_status", kind: enumField, valid: func(v string) bool { return models.PaymentStatus(v).IsValid() }},
+		"user_id":            {column: "orders.user_id", kind: uuidField},
+		"delivery_person_id": {column: "orders.delivery_person_id", kind: uuidField},
+		"subtotal":           {column: "orders.subtotal_amount", kind: integerField},
+		"total":              {column: "orders.total_amount", kind: integerField},
+		"pickup_at_store":    {column: "orders.pickup_at_store", kind: boolField},
+		"scheduled_for":      {column: "orders.scheduled_for", kind: timeField},
+		"created_at":         {column: "orders.created_at", kind: timeField},
+		"updated_at":         {column: "orders.updated_at", kind: timeField},
+	}
+
+	productListSchema = listSchema{
+		"name":       {column: "products.name", kind: textField},
+		"sku":        {column: "products.sku", kind: textField},
+		"category":   {column: "products.category", kind: enumField, valid: func(v string) bool { return models.ProductCategory(v).IsValid() }},
+		"store_id":   {column: "products.store_id", kind: uuidField},
+		"price":      {column: "products.price_amount", kind: integerField},
+		"stock":      {column: "products.stock", kind: integerField},
+		"is_active":  {column: "products.is_active", kind: boolField},
+		"auto_hide":  {column: "products.auto_hide", kind: boolField},
+		"created_at": {column: "products.created_at", kind: timeField},
+		"updated_at": {column: "products.updated_at", kind: timeField},
+	}
+
+	storeListSchema = listSchema{
+		"name":       {column: "stores.name", kind: textField},
+		"zone":       {column: "stores.zone", kind: textField},
+		"owner_id":   {column: "stores.owner_id", kind: uuidField},
+		"is_active":  {column: "stores.is_active", kind: boolField},
+		"is_paused":  {column: "stores.is_paused", kind: boolField},
+		"created_at": {column: "stores.created_at", kind: timeField},
+		"updated_at": {column: "stores.updated_at", kind: timeField},
+	}
+
+	userListSchema = listSchema{
+		"email":      {column: "users.email", kind: textField},
+		"first_name": {column: "users.first_name", kind: textField},
+		"last_name":  {column: "users.last_name", kind: textField},
+		"role":       {column: "users.role", kind: enumField, valid: func(v string) bool { return models.Role(v).IsValid() }},
+		"is_active":  {column: "users.is_active", kind: boolField},
+		"created_at": {column: "users.created_at", kind: timeField},
+		"updated_at": {column: "users.updated_at", kind: timeField},
+	}
+)
+
+// filter adds the filters of a list query to a query
+func (s listSchema) filter(query *gorm.DB, list utils.ListQuery) (*gorm.DB, error) {
+	for _, filter := range list.Filters {
+		field, ok := s[filter.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown filter field %q", utils.ErrInvalidListQuery, filter.Field)
+		}
+		if !field.supports(filter.Operator) {
+			return nil, fmt.Errorf("%w: operator %q is not supported by field %q", utils.ErrInvalidListQuery, filter.Operator, filter.Field)
+		}
+
+		values := make([]interface{}, 0, len(filter.Values))
+		for _, raw := range filter.Values {
+			value, err := field.parse(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid value %q for field %q", utils.ErrInvalidListQuery, raw, filter.Field)
+			}
+			values = append(values, value)
+		}
+
+		switch filter.Operator {
+		case utils.FilterEq:
+			query = query.Where(field.column+" = ?", values[0])
+		case utils.FilterNe:
+			query = query.Where(field.column+" IS DISTINCT FROM ?", values[0])
+		case utils.FilterGt:
+			query = query.Where(field.column+" > ?", values[0])
+		case utils.FilterGte:
+			query = query.Where(field.column+" >= ?", values[0])
+		case utils.FilterLt:
+			query = query.Where(field.column+" < ?", values[0])
+		case utils.FilterLte:
+			query = query.Where(field.column+" <= ?", values[0])
+		case utils.FilterIn:
+			query = query.Where(field.column+" IN ?", values)
+		case utils.FilterContains:
+			query = query.Where(field.column+" ILIKE ?", "%"+escapeLike(filter.Values[0])+"%")
+		}
+	}
+	return query, nil
+}
+
+// sort orders a query by the sort fields of a list query. Sorted lists are paged by offset only,
+// since cursors follow the default order.
+func (s listSchema) sort(query *gorm.DB, list utils.ListQuery, page *Page) (*gorm.DB, error) {
+	if len(list.Sort) == 0 {
+		return query, nil
+	}
+	if page.After != nil {
+		return nil, fmt.Errorf("%w: cursors can't be combined with sort, use offset instead", utils.ErrInvalidListQuery)
+	}
+
+	for _, sort := range list.Sort {
+		field, ok := s[sort.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown sort field %q", utils.ErrInvalidListQuery, sort.Field)
+		}
+		if sort.Desc {
+			query = query.Order(field.column + " DESC")
+		} else {
+			query = query.Order(field.column + " ASC")
+		}
+	}
+	page.sorted = true
+	return query, nil
+}
+
+// supports checks if a field can be compared with an operator
+func (f listField) supports(operator utils.FilterOperator) bool {
+	switch operator {
+	case utils.FilterEq, utils.FilterNe:
+		return true
+	case utils.FilterIn:
+		return f.kind != boolField
+	case utils.FilterContains:
+		return f.kind == textField
+	default:
+		return f.kind == textField || f.kind == integerField || f.kind == timeField
+	}
+}
+
+// parse converts a filter value to the type of the field
+func (f listField) parse(raw string) (interface{}, error) {
+	raw = strings.TrimSpace(raw)
+	switch f.kind {
+	case enumField:
+		if !f.valid(raw) {
+			return nil, errors.New("invalid value")
+		}
+		return raw, nil
+	case integerField:
+		return strconv.ParseInt(raw, 10, 64)
+	case boolField:
+		return strconv.ParseBool(raw)
+	case timeField:
+		if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", raw)
+	case uuidField:
+		return uuid.Parse(raw)
+	}
+	return raw, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
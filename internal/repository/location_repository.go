@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// LocationRepository handles database operations for campus locations
+type LocationRepository struct {
+	db *gorm.DB
+}
+
+// NewLocationRepository creates a new location repository
+func NewLocationRepository(db *gorm.DB) *LocationRepository {
+	return &LocationRepository{db: db}
+}
+
+// Create creates a new campus location
+func (r *LocationRepository) Create(location *models.CampusLocation) error {
+	return r.db.Create(location).Error
+}
+
+// GetByID finds a campus location by ID
+func (r *LocationRepository) GetByID(id uuid.UUID) (*models.CampusLocation, error) {
+	var location models.CampusLocation
+	err := r.db.Where("id = ?", id).First(&location).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("location not found")
+		}
+		return nil, err
+	}
+	return &location, nil
+}
+
+// Update updates a campus location
+func (r *LocationRepository) Update(location *models.CampusLocation) error {
+	return r.db.Save(location).Error
+}
+
+// Delete soft deletes a campus location
+func (r *LocationRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.CampusLocation{}, id).Error
+}
+
+// List returns campus locations, optionally filtered by building and active flag
+func (r *LocationRepository) List(building string, activeOnly bool) ([]*models.CampusLocation, error) {
+	var locations []*models.CampusLocation
+	query := r.db.Model(&models.CampusLocation{})
+
+	if building != "" {
+		query = query.Where("building = ?", building)
+	}
+
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	// Order by building, then floor and room
+	err := query.Order("building ASC, floor ASC, room ASC").Find(&locations).Error
+	return locations, err
+}
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/utils"
 	"gorm.io/gorm"
 )
 
@@ -16,6 +19,11 @@ func NewOrderRepository(db *gorm.DB) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *OrderRepository) WithTx(tx *gorm.DB) *OrderRepository {
+	return &OrderRepository{db: tx}
+}
+
 // Create creates a new order with items
 func (r *OrderRepository) Create(order *models.Order) error {
 	return r.db.Create(order).Error
@@ -24,52 +32,171 @@ func (r *OrderRepository) Create(order *models.Order) error {
 // GetByID retrieves an order by ID with its items
 func (r *OrderRepository) GetByID(id uuid.UUID) (*models.Order, error) {
 	var order models.Order
-	err := r.db.Preload("Items").First(&order, "id = ?", id).Error
+	err := r.db.Preload("Items.Modifiers").Preload("DeliveryLocation").Preload("Refunds").Preload("PrintJobs").First(&order, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &order, nil
 }
 
-// ListByUser retrieves orders for a specific user
-func (r *OrderRepository) ListByUser(userID uuid.UUID, limit, offset int) ([]models.Order, int64, error) {
-	var orders []models.Order
-	var total int64
-
+// ListByUser retrieves a page of the orders of a user matching a list query, newest first unless
+// sorted otherwise, with the cursor of the next page. The total is only counted in offset mode.
+func (r *OrderRepository) ListByUser(userID uuid.UUID, page Page, list utils.ListQuery) ([]models.Order, string, int64, error) {
 	query := r.db.Model(&models.Order{}).Where("user_id = ?", userID)
+	return r.listPage(query, page, list, false, "Items.Modifiers", "PrintJobs")
+}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
+// ListByStore retrieves a page of the orders of a store matching a list query, newest first unless
+// sorted otherwise, with the cursor of the next page. The total is only counted in offset mode.
+func (r *OrderRepository) ListByStore(storeID uuid.UUID, page Page, list utils.ListQuery) ([]models.Order, string, int64, error) {
+	query := r.db.Model(&models.Order{}).Where("store_id = ?", storeID)
+	return r.listPage(query, page, list, false, "Items.Modifiers", "PrintJobs")
+}
 
-	err := query.Preload("Items").Limit(limit).Offset(offset).Order("created_at desc").Find(&orders).Error
+// listPage retrieves a page of the orders selected by a query with the given associations,
+// counting them in offset mode
+func (r *OrderRepository) listPage(query *gorm.DB, page Page, list utils.ListQuery, ascending bool, preloads ...string) ([]models.Order, string, int64, error) {
+	query, err := orderListSchema.filter(query, list)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", 0, err
 	}
 
-	return orders, total, nil
-}
-
-// ListByStore retrieves orders for a specific store
-func (r *OrderRepository) ListByStore(storeID uuid.UUID, limit, offset int) ([]models.Order, int64, error) {
-	var orders []models.Order
 	var total int64
+	if page.After == nil {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, "", 0, err
+		}
+	}
 
-	query := r.db.Model(&models.Order{}).Where("store_id = ?", storeID)
+	query, err = orderListSchema.sort(query, list, &page)
+	if err != nil {
+		return nil, "", 0, err
+	}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	for _, preload := range preloads {
+		query = query.Preload(preload)
 	}
 
-	err := query.Preload("Items").Limit(limit).Offset(offset).Order("created_at desc").Find(&orders).Error
-	if err != nil {
-		return nil, 0, err
+	var orders []models.Order
+	if err := paginate(query, "orders", page, ascending).Find(&orders).Error; err != nil {
+		return nil, "", 0, err
 	}
 
-	return orders, total, nil
+	orders, next := nextCursor(orders, page, func(order models.Order) Cursor {
+		return Cursor{CreatedAt: order.CreatedAt, ID: order.ID}
+	})
+	return orders, next, total, nil
 }
 
 // UpdateStatus updates the status of an order
 func (r *OrderRepository) UpdateStatus(id uuid.UUID, status models.OrderStatus) error {
 	return r.db.Model(&models.Order{}).Where("id = ?", id).Update("status", status).Error
 }
+
+// TransitionStatus changes the status of an order only if it still has the expected current status.
+// It returns false when the order was changed by someone else in the meantime.
+func (r *OrderRepository) TransitionStatus(id uuid.UUID, from, to models.OrderStatus) (bool, error) {
+	result := r.db.Model(&models.Order{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ListAwaitingDelivery retrieves a page of the ready delivery orders that no courier has claimed yet, oldest first
+func (r *OrderRepository) ListAwaitingDelivery(page Page) ([]models.Order, string, int64, error) {
+	query := r.db.Model(&models.Order{}).
+		Where("status = ? AND delivery_person_id IS NULL AND pickup_at_store = ?", models.OrderStatusReady, false)
+	return r.listPage(query, page, utils.ListQuery{}, true, "Items.Modifiers", "DeliveryLocation")
+}
+
+// ListByDeliveryPerson retrieves a page of the orders assigned to a courier with one of the given statuses, newest first
+func (r *OrderRepository) ListByDeliveryPerson(deliveryPersonID uuid.UUID, statuses []models.OrderStatus, page Page) ([]models.Order, string, int64, error) {
+	query := r.db.Model(&models.Order{}).
+		Where("delivery_person_id = ? AND status IN ?", deliveryPersonID, statuses)
+	return r.listPage(query, page, utils.ListQuery{}, false, "Items.Modifiers", "DeliveryLocation")
+}
+
+// AssignDeliveryPerson assigns a courier to a ready delivery order that has no courier yet.
+// It returns false when the order is not ready, is picked up at the store or was already claimed.
+func (r *OrderRepository) AssignDeliveryPerson(id, deliveryPersonID uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.Order{}).
+		Where("id = ? AND status = ? AND delivery_person_id IS NULL AND pickup_at_store = ?", id, models.OrderStatusReady, false).
+		Update("delivery_person_id", deliveryPersonID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UnassignDeliveryPerson removes the courier from a ready order they had claimed.
+// It returns false when the order is no longer ready or belongs to another courier.
+func (r *OrderRepository) UnassignDeliveryPerson(id, deliveryPersonID uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.Order{}).
+		Where("id = ? AND status = ? AND delivery_person_id = ?", id, models.OrderStatusReady, deliveryPersonID).
+		Update("delivery_person_id", nil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// SetPickedUpAt records when the courier collected the order
+func (r *OrderRepository) SetPickedUpAt(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.Order{}).Where("id = ?", id).Update("picked_up_at", at).Error
+}
+
+// SetDeliveredAt records when the order was delivered
+func (r *OrderRepository) SetDeliveredAt(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.Order{}).Where("id = ?", id).Update("delivered_at", at).Error
+}
+
+// SetCancellation records who cancelled the order, when and why
+func (r *OrderRepository) SetCancellation(id uuid.UUID, reason models.CancellationReason, note string, cancelledBy uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.Order{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"cancellation_reason": reason,
+		"cancellation_note":   note,
+		"cancelled_by":        cancelledBy,
+		"cancelled_at":        at,
+	}).Error
+}
+
+// SetPaymentStatus updates the payment status of an order
+func (r *OrderRepository) SetPaymentStatus(id uuid.UUID, status models.PaymentStatus) error {
+	return r.db.Model(&models.Order{}).Where("id = ?", id).Update("payment_status", status).Error
+}
+
+// ListAwaitingDeliveryIDs returns the IDs of ready delivery orders without a courier, oldest first
+func (r *OrderRepository) ListAwaitingDeliveryIDs() ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.Order{}).
+		Where("status = ? AND delivery_person_id IS NULL AND pickup_at_store = ?", models.OrderStatusReady, false).
+		Order("created_at asc").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// CountActiveDeliveries counts the claimed or in-transit orders of each given courier
+func (r *OrderRepository) CountActiveDeliveries(deliveryPersonIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		DeliveryPersonID uuid.UUID
+		Count            int
+	}
+	err := r.db.Model(&models.Order{}).
+		Select("delivery_person_id, count(*) as count").
+		Where("delivery_person_id IN ? AND status IN ?", deliveryPersonIDs,
+			[]models.OrderStatus{models.OrderStatusReady, models.OrderStatusInDelivery}).
+		Group("delivery_person_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.DeliveryPersonID] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeTable is the result of every query that selects from a table
+type fakeTable struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeDB is a database/sql driver that answers SELECT queries with canned rows by table,
+// recording the queries it receives
+type fakeDB struct {
+	mu      sync.Mutex
+	tables  map[string]fakeTable
+	queries []string
+}
+
+func (d *fakeDB) Open(string) (driver.Conn, error) { return &fakeConn{db: d}, nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.queries = append(c.db.queries, query)
+
+	for name, table := range c.db.tables {
+		if strings.Contains(query, `FROM "`+name+`"`) {
+			return &fakeRows{columns: table.columns, rows: table.rows}, nil
+		}
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// newFakeGormDB opens a GORM Postgres connection backed by a fake driver
+func newFakeGormDB(t *testing.T, fake *fakeDB) *gorm.DB {
+	t.Helper()
+	name := "fake-" + uuid.NewString()
+	sql.Register(name, fake)
+	conn, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestOrderRepositoryGetByIDLoadsItemsAndDeliveryLocation(t *testing.T) {
+	orderID, itemID, locationID := uuid.New(), uuid.New(), uuid.New()
+	fake := &fakeDB{tables: map[string]fakeTable{
+		"orders": {
+			columns: []string{"id", "delivery_location_id"},
+			rows:    [][]driver.Value{{orderID.String(), locationID.String()}},
+		},
+		"order_items": {
+			columns: []string{"id", "order_id"},
+			rows:    [][]driver.Value{{itemID.String(), orderID.String()}},
+		},
+		"campus_locations": {
+			columns: []string{"id", "name"},
+			rows:    [][]driver.Value{{locationID.String(), "Library"}},
+		},
+	}}
+	repo := NewOrderRepository(newFakeGormDB(t, fake))
+
+	order, err := repo.GetByID(orderID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+
+	if len(order.Items) != 1 || order.Items[0].ID != itemID {
+		t.Errorf("items = %+v, want the order item", order.Items)
+	}
+	if order.DeliveryLocation == nil || order.DeliveryLocation.Name != "Library" {
+		t.Errorf("delivery location = %+v, want the campus location", order.DeliveryLocation)
+	}
+	for _, query := range fake.queries {
+		if strings.Contains(query, `FROM "order_item_modifiers"`) && strings.Contains(query, "DeliveryLocation") {
+			t.Errorf("modifiers preload has a stray condition: %s", query)
+		}
+	}
+}
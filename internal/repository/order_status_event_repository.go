@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// OrderStatusEventRepository handles database operations for order status events
+type OrderStatusEventRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderStatusEventRepository creates a new order status event repository
+func NewOrderStatusEventRepository(db *gorm.DB) *OrderStatusEventRepository {
+	return &OrderStatusEventRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *OrderStatusEventRepository) WithTx(tx *gorm.DB) *OrderStatusEventRepository {
+	return &OrderStatusEventRepository{db: tx}
+}
+
+// Create records a new status event
+func (r *OrderStatusEventRepository) Create(event *models.OrderStatusEvent) error {
+	return r.db.Create(event).Error
+}
+
+// ListByOrder returns the status events of an order, oldest first
+func (r *OrderStatusEventRepository) ListByOrder(orderID uuid.UUID) ([]models.OrderStatusEvent, error) {
+	var events []models.OrderStatusEvent
+	err := r.db.Where("order_id = ?", orderID).Order("created_at asc").Find(&events).Error
+	return events, err
+}
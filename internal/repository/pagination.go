@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned for page cursors that were not issued by a list
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of the last row of a page in a list ordered by creation time and ID
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Page selects the rows of a list page. With a cursor the page starts right after the
+// cursor row (keyset pagination), so rows added while paging are neither skipped nor
+// repeated; without one it skips Offset rows.
+type Page struct {
+	Limit  int
+	Offset int
+	After  *Cursor
+	sorted bool // Ordered by other fields first, so the page has no next cursor
+}
+
+// Encode returns the opaque form of the cursor given to clients
+func (c Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor returned by Encode; cursors in any other form are rejected
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	createdAtStr, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor := &Cursor{CreatedAt: createdAt, ID: id}
+	if cursor.Encode() != s {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// paginate orders a query of a table by creation time and ID, newest first unless ascending,
+// and selects the rows of the page plus one more that tells whether there is a next page
+func paginate(query *gorm.DB, table string, page Page, ascending bool) *gorm.DB {
+	operator, direction := "<", "DESC"
+	if ascending {
+		operator, direction = ">", "ASC"
+	}
+
+	if page.After != nil {
+		query = query.Where("("+table+".created_at, "+table+".id) "+operator+" (?, ?)", page.After.CreatedAt, page.After.ID)
+	} else {
+		query = query.Offset(page.Offset)
+	}
+
+	return query.
+		Order(table + ".created_at " + direction + ", " + table + ".id " + direction).
+		Limit(page.Limit + 1)
+}
+
+// nextCursor drops the extra row selected by paginate and returns the cursor of the next
+// page, which is empty on the last page and for lists with a custom sort order
+func nextCursor[T any](rows []T, page Page, position func(T) Cursor) ([]T, string) {
+	if len(rows) <= page.Limit {
+		return rows, ""
+	}
+	rows = rows[:page.Limit]
+	if page.sorted {
+		return rows, ""
+	}
+	return rows, position(rows[len(rows)-1]).Encode()
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	cursor := Cursor{
+		CreatedAt: time.Date(2026, 10, 17, 9, 30, 15, 123456000, time.UTC),
+		ID:        uuid.MustParse("6f1c2b3a-4d5e-4f60-8a7b-9c0d1e2f3a4b"),
+	}
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	valid := cursor.Encode()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "issued cursor", value: valid},
+		{name: "empty", value: "", wantErr: true},
+		{name: "not base64", value: "not a cursor!", wantErr: true},
+		{name: "padded base64", value: base64.URLEncoding.EncodeToString([]byte("2026-10-17T09:30:15.123456Z|6f1c2b3a-4d5e-4f60-8a7b-9c0d1e2f3a4b")), wantErr: true},
+		{name: "standard base64", value: base64.RawStdEncoding.EncodeToString([]byte("2026-10-17T09:30:15.123456Z|" + uuid.Nil.String() + "??")), wantErr: true},
+		{name: "tampered time", value: encode("2026-13-17T09:30:15.123456Z|" + cursor.ID.String()), wantErr: true},
+		{name: "tampered ID", value: encode("2026-10-17T09:30:15.123456Z|" + cursor.ID.String()[:35]), wantErr: true},
+		{name: "trailing zeros in time", value: encode("2026-10-17T09:30:15.123456000Z|" + cursor.ID.String()), wantErr: true},
+		{name: "upper case ID", value: encode("2026-10-17T09:30:15.123456Z|6F1C2B3A-4D5E-4F60-8A7B-9C0D1E2F3A4B"), wantErr: true},
+		{name: "truncated", value: valid[:len(valid)-4], wantErr: true},
+		{name: "no separator", value: encode("2026-10-17T09:30:15Z"), wantErr: true},
+		{name: "invalid time", value: encode("yesterday|" + cursor.ID.String()), wantErr: true},
+		{name: "time without zone", value: encode("2026-10-17T09:30:15|" + cursor.ID.String()), wantErr: true},
+		{name: "time in another zone", value: encode("2026-10-17T11:30:15.123456+02:00|" + cursor.ID.String()), wantErr: true},
+		{name: "invalid ID", value: encode("2026-10-17T09:30:15.123456Z|42"), wantErr: true},
+		{name: "ID in another format", value: encode("2026-10-17T09:30:15.123456Z|urn:uuid:" + cursor.ID.String()), wantErr: true},
+		{name: "extra field", value: encode("2026-10-17T09:30:15.123456Z|" + cursor.ID.String() + "|1"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCursor) {
+					t.Fatalf("DecodeCursor(%q) = %+v, %v, want ErrInvalidCursor", tt.value, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeCursor(%q) error = %v", tt.value, err)
+			}
+			if !got.CreatedAt.Equal(cursor.CreatedAt) || got.ID != cursor.ID {
+				t.Errorf("DecodeCursor(%q) = %+v, want %+v", tt.value, got, cursor)
+			}
+		})
+	}
+}
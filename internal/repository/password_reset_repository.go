@@ -19,6 +19,11 @@ func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
 	return &PasswordResetRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *PasswordResetRepository) WithTx(tx *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: tx}
+}
+
 // Create creates a new password reset token
 func (r *PasswordResetRepository) Create(reset *models.PasswordReset) error {
 	return r.db.Create(reset).Error
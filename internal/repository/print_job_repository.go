@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// PrintJobRepository handles database operations for print jobs and store print pricing
+type PrintJobRepository struct {
+	db *gorm.DB
+}
+
+// NewPrintJobRepository creates a new print job repository
+func NewPrintJobRepository(db *gorm.DB) *PrintJobRepository {
+	return &PrintJobRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *PrintJobRepository) WithTx(tx *gorm.DB) *PrintJobRepository {
+	return &PrintJobRepository{db: tx}
+}
+
+// Create creates a new print job
+func (r *PrintJobRepository) Create(job *models.PrintJob) error {
+	return r.db.Create(job).Error
+}
+
+// GetByID finds a print job by ID
+func (r *PrintJobRepository) GetByID(id uuid.UUID) (*models.PrintJob, error) {
+	var job models.PrintJob
+	err := r.db.Where("id = ?", id).First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("print job not found")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetByIDsForUpdate finds print jobs by ID and locks their rows until the transaction ends
+func (r *PrintJobRepository) GetByIDsForUpdate(ids []uuid.UUID) ([]*models.PrintJob, error) {
+	var jobs []*models.PrintJob
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).
+		Order("id").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// ListByUser retrieves a page of the print jobs of a user, newest first, with the cursor of the next page.
+// The total is only counted in offset mode.
+func (r *PrintJobRepository) ListByUser(userID uuid.UUID, page Page) ([]models.PrintJob, string, int64, error) {
+	var jobs []models.PrintJob
+	var total int64
+
+	query := r.db.Model(&models.PrintJob{}).Where("user_id = ?", userID)
+
+	if page.After == nil {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, "", 0, err
+		}
+	}
+
+	if err := paginate(query, "print_jobs", page, false).Find(&jobs).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	jobs, next := nextCursor(jobs, page, func(job models.PrintJob) Cursor {
+		return Cursor{CreatedAt: job.CreatedAt, ID: job.ID}
+	})
+	return jobs, next, total, nil
+}
+
+// Update saves the options and price of a print job
+func (r *PrintJobRepository) Update(job *models.PrintJob) error {
+	return r.db.Save(job).Error
+}
+
+// Delete soft deletes a print job
+func (r *PrintJobRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.PrintJob{}, "id = ?", id).Error
+}
+
+// AttachToOrder links uploaded print jobs to an order and queues them for printing.
+// It returns false when one of the jobs was ordered or removed in the meantime.
+func (r *PrintJobRepository) AttachToOrder(ids []uuid.UUID, orderID uuid.UUID, promisedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.PrintJob{}).
+		Where("id IN ? AND order_id IS NULL AND status = ?", ids, models.PrintJobStatusUploaded).
+		Updates(map[string]interface{}{
+			"order_id":    orderID,
+			"status":      models.PrintJobStatusQueued,
+			"promised_at": promisedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == int64(len(ids)), nil
+}
+
+// CancelByOrder cancels the print jobs of an order that were not printed yet
+func (r *PrintJobRepository) CancelByOrder(orderID uuid.UUID) error {
+	return r.db.Model(&models.PrintJob{}).
+		Where("order_id = ? AND status <> ?", orderID, models.PrintJobStatusPrinted).
+		Update("status", models.PrintJobStatusCancelled).Error
+}
+
+// ListByOrder retrieves the print jobs of an order
+func (r *PrintJobRepository) ListByOrder(orderID uuid.UUID) ([]models.PrintJob, error) {
+	var jobs []models.PrintJob
+	err := r.db.Where("order_id = ?", orderID).Order("created_at asc").Find(&jobs).Error
+	return jobs, err
+}
+
+// ListQueue retrieves the ordered print jobs of a store with one of the given statuses,
+// the earliest promised first
+func (r *PrintJobRepository) ListQueue(storeID uuid.UUID, statuses []models.PrintJobStatus, limit, offset int) ([]models.PrintJob, int64, error) {
+	var jobs []models.PrintJob
+	var total int64
+
+	query := r.db.Model(&models.PrintJob{}).
+		Where("store_id = ? AND order_id IS NOT NULL AND status IN ?", storeID, statuses)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Limit(limit).Offset(offset).Order("promised_at asc nulls last, created_at asc").Find(&jobs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return jobs, total, nil
+}
+
+// TransitionStatus changes the status of a print job only if it is in one of the expected statuses,
+// together with the given extra columns. It returns false when the job was changed in the meantime.
+func (r *PrintJobRepository) TransitionStatus(id uuid.UUID, from []models.PrintJobStatus, to models.PrintJobStatus, fields map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": to}
+	for column, value := range fields {
+		updates[column] = value
+	}
+	result := r.db.Model(&models.PrintJob{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetPricing finds the print pricing of a store
+func (r *PrintJobRepository) GetPricing(storeID uuid.UUID) (*models.PrintPricing, error) {
+	var pricing models.PrintPricing
+	err := r.db.Where("store_id = ?", storeID).First(&pricing).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("print pricing not found")
+		}
+		return nil, err
+	}
+	return &pricing, nil
+}
+
+// SavePricing creates or replaces the print pricing of a store
+func (r *PrintJobRepository) SavePricing(pricing *models.PrintPricing) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "store_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"bw_page_price_amount", "bw_page_price_currency",
+			"color_page_price_amount", "color_page_price_currency",
+			"staple_binding_price_amount", "staple_binding_price_currency",
+			"spiral_binding_price_amount", "spiral_binding_price_currency",
+			"thermal_binding_price_amount", "thermal_binding_price_currency",
+			"turnaround_minutes", "updated_at",
+		}),
+	}).Create(pricing).Error
+}
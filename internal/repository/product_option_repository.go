@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// ProductOptionRepository handles database operations for product variants, option groups and modifiers
+type ProductOptionRepository struct {
+	db *gorm.DB
+}
+
+// NewProductOptionRepository creates a new product option repository
+func NewProductOptionRepository(db *gorm.DB) *ProductOptionRepository {
+	return &ProductOptionRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *ProductOptionRepository) WithTx(tx *gorm.DB) *ProductOptionRepository {
+	return &ProductOptionRepository{db: tx}
+}
+
+// CreateVariant creates a new product variant
+func (r *ProductOptionRepository) CreateVariant(variant *models.ProductVariant) error {
+	return r.db.Create(variant).Error
+}
+
+// GetVariant finds a product variant by ID
+func (r *ProductOptionRepository) GetVariant(id uuid.UUID) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := r.db.Where("id = ?", id).First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product variant not found")
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// UpdateVariant updates a product variant, leaving its stock untouched
+func (r *ProductOptionRepository) UpdateVariant(variant *models.ProductVariant) error {
+	return r.db.Omit("stock").Save(variant).Error
+}
+
+// DeleteVariant soft deletes a product variant
+func (r *ProductOptionRepository) DeleteVariant(id uuid.UUID) error {
+	return r.db.Delete(&models.ProductVariant{}, id).Error
+}
+
+// ExistsVariantBySKU checks if a variant other than excludeID uses the given SKU.
+// Deleted variants keep their SKU reserved.
+func (r *ProductOptionRepository) ExistsVariantBySKU(sku string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.ProductVariant{}).
+		Where("sku = ? AND id <> ?", sku, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListUsedVariantSKUs returns which of the given SKUs are used by variants, deleted ones included
+func (r *ProductOptionRepository) ListUsedVariantSKUs(skus []string) ([]string, error) {
+	var used []string
+	err := r.db.Unscoped().Model(&models.ProductVariant{}).
+		Where("sku IN ?", skus).
+		Pluck("sku", &used).Error
+	return used, err
+}
+
+// GetVariantsByProductIDsForUpdate finds the variants of products and locks their rows until
+// the transaction ends. Must be called on a repository bound to a transaction.
+func (r *ProductOptionRepository) GetVariantsByProductIDsForUpdate(productIDs []uuid.UUID) ([]*models.ProductVariant, error) {
+	var variants []*models.ProductVariant
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id IN ?", productIDs).
+		Order("id").
+		Find(&variants).Error
+	return variants, err
+}
+
+// AdjustVariantStock adds delta (negative to remove) to the cached stock of a variant unless the
+// stock would drop below zero. It returns the new stock and false when there is not enough stock.
+// Deleted rows are updated too, so returns of deleted items stay balanced.
+// Use InventoryService so that every change is recorded in the stock ledger.
+func (r *ProductOptionRepository) AdjustVariantStock(id uuid.UUID, delta int) (int, bool, error) {
+	variant := models.ProductVariant{ID: id}
+	result := r.db.Unscoped().Model(&variant).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "stock"}}}).
+		Where("stock + ? >= 0", delta).
+		Update("stock", gorm.Expr("stock + ?", delta))
+	if result.Error != nil {
+		return 0, false, result.Error
+	}
+	return variant.Stock, result.RowsAffected == 1, nil
+}
+
+// CreateOptionGroup creates an option group with its modifiers
+func (r *ProductOptionRepository) CreateOptionGroup(group *models.ProductOptionGroup) error {
+	return r.db.Create(group).Error
+}
+
+// GetOptionGroup finds an option group by ID with its modifiers
+func (r *ProductOptionRepository) GetOptionGroup(id uuid.UUID) (*models.ProductOptionGroup, error) {
+	var group models.ProductOptionGroup
+	err := r.db.Preload("Modifiers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order asc, name asc")
+	}).Where("id = ?", id).First(&group).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("option group not found")
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// ReplaceOptionGroup updates an option group and replaces all its modifiers
+func (r *ProductOptionRepository) ReplaceOptionGroup(group *models.ProductOptionGroup) error {
+	if err := r.db.Omit(clause.Associations).Save(group).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("group_id = ?", group.ID).Delete(&models.ProductModifier{}).Error; err != nil {
+		return err
+	}
+	if len(group.Modifiers) == 0 {
+		return nil
+	}
+	for i := range group.Modifiers {
+		group.Modifiers[i].GroupID = group.ID
+	}
+	return r.db.Create(&group.Modifiers).Error
+}
+
+// DeleteOptionGroup deletes an option group and its modifiers
+func (r *ProductOptionRepository) DeleteOptionGroup(id uuid.UUID) error {
+	if err := r.db.Where("group_id = ?", id).Delete(&models.ProductModifier{}).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(&models.ProductOptionGroup{}, id).Error
+}
+
+// ListOptionGroupsByProducts returns the option groups of products with their modifiers
+func (r *ProductOptionRepository) ListOptionGroupsByProducts(productIDs []uuid.UUID) ([]models.ProductOptionGroup, error) {
+	var groups []models.ProductOptionGroup
+	err := r.db.Preload("Modifiers").
+		Where("product_id IN ?", productIDs).
+		Order("sort_order asc, name asc").
+		Find(&groups).Error
+	return groups, err
+}
@@ -2,11 +2,14 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/utils"
 )
 
 // ProductRepository handles database operations for products
@@ -19,15 +22,20 @@ func NewProductRepository(db *gorm.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-// Create creates a new product
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *ProductRepository) WithTx(tx *gorm.DB) *ProductRepository {
+	return &ProductRepository{db: tx}
+}
+
+// Create creates a new product. Variants and option groups are created separately.
 func (r *ProductRepository) Create(product *models.Product) error {
-	return r.db.Create(product).Error
+	return r.db.Omit(clause.Associations).Create(product).Error
 }
 
-// GetByID finds a product by ID
+// GetByID finds a product by ID with its variants and option groups
 func (r *ProductRepository) GetByID(id uuid.UUID) (*models.Product, error) {
 	var product models.Product
-	err := r.db.Where("id = ?", id).First(&product).Error
+	err := withOptions(r.db).Where("id = ?", id).First(&product).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("product not found")
@@ -50,9 +58,9 @@ func (r *ProductRepository) GetBySKU(sku string) (*models.Product, error) {
 	return &product, nil
 }
 
-// Update updates a product
+// Update updates a product, leaving its stock, low-stock alert state, variants and option groups untouched
 func (r *ProductRepository) Update(product *models.Product) error {
-	return r.db.Save(product).Error
+	return r.db.Omit(clause.Associations, "stock", "low_stock_alerted_at").Save(product).Error
 }
 
 // Delete soft deletes a product
@@ -60,54 +68,47 @@ func (r *ProductRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.Product{}, id).Error
 }
 
-// List returns a list of products with optional filters
-func (r *ProductRepository) List(limit, offset int, category models.ProductCategory, storeID uuid.UUID, activeOnly bool) ([]*models.Product, error) {
+// List returns a page of products matching a list query, newest first unless sorted otherwise,
+// with the cursor of the next page. Products set to auto hide are left out while they are out of stock.
+func (r *ProductRepository) List(page Page, list utils.ListQuery) ([]*models.Product, string, error) {
 	var products []*models.Product
-	query := r.db.Limit(limit).Offset(offset)
 
-	// Filter by category if provided
-	if category != "" && category.IsValid() {
-		query = query.Where("category = ?", category)
+	query, err := productListSchema.filter(withOptions(r.db), list)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Filter by store if provided
-	if storeID != uuid.Nil {
-		query = query.Where("store_id = ?", storeID)
-	}
+	// Hide sold out products until they are restocked
+	query = query.Where("NOT (auto_hide AND " + availableStockSQL + " <= 0)")
 
-	// Filter by active status if requested
-	if activeOnly {
-		query = query.Where("is_active = ?", true)
+	query, err = productListSchema.sort(query, list, &page)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Order by created_at descending (newest first)
-	query = query.Order("created_at DESC")
+	if err := paginate(query, "products", page, false).Find(&products).Error; err != nil {
+		return nil, "", err
+	}
 
-	err := query.Find(&products).Error
-	return products, err
+	products, next := nextCursor(products, page, func(product *models.Product) Cursor {
+		return Cursor{CreatedAt: product.CreatedAt, ID: product.ID}
+	})
+	return products, next, nil
 }
 
-// Count returns the total number of products with optional filters
-func (r *ProductRepository) Count(category models.ProductCategory, storeID uuid.UUID, activeOnly bool) (int64, error) {
+// Count returns the total number of products matching the filters of a list query, leaving out hidden products
+func (r *ProductRepository) Count(list utils.ListQuery) (int64, error) {
 	var count int64
-	query := r.db.Model(&models.Product{})
-
-	// Filter by category if provided
-	if category != "" && category.IsValid() {
-		query = query.Where("category = ?", category)
-	}
 
-	// Filter by store if provided
-	if storeID != uuid.Nil {
-		query = query.Where("store_id = ?", storeID)
+	query, err := productListSchema.filter(r.db.Model(&models.Product{}), list)
+	if err != nil {
+		return 0, err
 	}
 
-	// Filter by active status if requested
-	if activeOnly {
-		query = query.Where("is_active = ?", true)
-	}
+	// Hide sold out products until they are restocked
+	query = query.Where("NOT (auto_hide AND " + availableStockSQL + " <= 0)")
 
-	err := query.Count(&count).Error
+	err = query.Count(&count).Error
 	return count, err
 }
 
@@ -121,7 +122,109 @@ func (r *ProductRepository) ExistsBySKU(sku string) (bool, error) {
 	return count > 0, err
 }
 
-// UpdateStock updates the stock quantity of a product
-func (r *ProductRepository) UpdateStock(id uuid.UUID, quantity int) error {
-	return r.db.Model(&models.Product{}).Where("id = ?", id).Update("stock", quantity).Error
+// GetByIDsForUpdate finds products by ID and locks their rows until the transaction ends.
+// Rows are locked in ID order so that concurrent orders cannot deadlock each other.
+// Must be called on a repository bound to a transaction.
+func (r *ProductRepository) GetByIDsForUpdate(ids []uuid.UUID) ([]*models.Product, error) {
+	var products []*models.Product
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).
+		Order("id").
+		Find(&products).Error
+	return products, err
+}
+
+// AdjustStock adds delta (negative to remove) to the cached stock of a product unless the
+// stock would drop below zero. It returns the new stock and false when there is not enough stock.
+// Deleted rows are updated too, so returns of deleted items stay balanced.
+// Use InventoryService so that every change is recorded in the stock ledger.
+func (r *ProductRepository) AdjustStock(id uuid.UUID, delta int) (int, bool, error) {
+	product := models.Product{ID: id}
+	result := r.db.Unscoped().Model(&product).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "stock"}}}).
+		Where("stock + ? >= 0", delta).
+		Update("stock", gorm.Expr("stock + ?", delta))
+	if result.Error != nil {
+		return 0, false, result.Error
+	}
+	return product.Stock, result.RowsAffected == 1, nil
+}
+
+// ListByStore returns every product of a store, hidden and inactive ones included, ordered by name
+func (r *ProductRepository) ListByStore(storeID uuid.UUID) ([]*models.Product, error) {
+	var products []*models.Product
+	err := r.db.Where("store_id = ?", storeID).Order("name asc, id asc").Find(&products).Error
+	return products, err
+}
+
+// GetBySKUs finds the products using the given SKUs, deleted ones included
+func (r *ProductRepository) GetBySKUs(skus []string) ([]*models.Product, error) {
+	var products []*models.Product
+	err := r.db.Unscoped().Where("sku IN ?", skus).Find(&products).Error
+	return products, err
+}
+
+// ListLowStock returns up to limit active products at or below their reorder threshold whose
+// alert has not been sent yet, with their variants
+func (r *ProductRepository) ListLowStock(limit int) ([]*models.Product, error) {
+	var products []*models.Product
+	err := r.db.Preload("Variants").
+		Where("reorder_threshold > 0 AND low_stock_alerted_at IS NULL AND is_active = ?", true).
+		Where(availableStockSQL + " <= reorder_threshold").
+		Order("store_id, name").
+		Limit(limit).
+		Find(&products).Error
+	return products, err
+}
+
+// ClaimLowStockAlert marks the low-stock alert of a product as sent.
+// It returns false when the alert was already claimed, e.g. by another server instance.
+func (r *ProductRepository) ClaimLowStockAlert(id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.Model(&models.Product{}).
+		Where("id = ? AND low_stock_alerted_at IS NULL", id).
+		UpdateColumn("low_stock_alerted_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ResetRestockedAlerts clears the alert state of products restocked above their reorder
+// threshold, so that the next shortage is alerted again
+func (r *ProductRepository) ResetRestockedAlerts() (int64, error) {
+	result := r.db.Model(&models.Product{}).
+		Where("low_stock_alerted_at IS NOT NULL").
+		Where(availableStockSQL+" > reorder_threshold").
+		UpdateColumn("low_stock_alerted_at", nil)
+	return result.RowsAffected, result.Error
+}
+
+// GetByIDs finds the products with the given IDs; missing or deleted products are skipped
+func (r *ProductRepository) GetByIDs(ids []uuid.UUID) ([]*models.Product, error) {
+	var products []*models.Product
+	err := r.db.Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
+// GetByIDsWithOptions finds products by IDs with their variants and option groups
+func (r *ProductRepository) GetByIDsWithOptions(ids []uuid.UUID) ([]*models.Product, error) {
+	var products []*models.Product
+	err := withOptions(r.db).Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
+// availableStockSQL is the stock a product can be sold from: the total of its active variants,
+// or its own stock when it has none
+const availableStockSQL = "COALESCE((SELECT SUM(v.stock) FROM product_variants v" +
+	" WHERE v.product_id = products.id AND v.is_active AND v.deleted_at IS NULL), products.stock)"
+
+// withOptions preloads the variants and option groups of products in display order
+func withOptions(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order asc, name asc")
+		}).
+		Preload("OptionGroups", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order asc, name asc")
+		}).
+		Preload("OptionGroups.Modifiers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order asc, name asc")
+		})
 }
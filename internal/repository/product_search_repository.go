@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// searchQueryCTE parses the search text once: the full-text query in both languages and the
+// accent-free text compared by trigram similarity
+const searchQueryCTE = `WITH input AS (
+	SELECT CAST(@q AS text) AS q
+), search AS (
+	SELECT websearch_to_tsquery('spanish_unaccent', input.q) || websearch_to_tsquery('english_unaccent', input.q) AS query,
+		immutable_unaccent(lower(input.q)) AS text
+	FROM input
+)`
+
+// searchFromSQL joins the products to the parsed search and to their stores
+const searchFromSQL = `FROM products
+	CROSS JOIN search
+	JOIN stores ON stores.id = products.store_id`
+
+// searchMatchSQL matches listed products of open stores by full text or, for typos and
+// partial words, by trigram similarity of the name
+const searchMatchSQL = `products.deleted_at IS NULL AND products.is_active
+	AND stores.deleted_at IS NULL AND stores.is_active
+	AND NOT (products.auto_hide AND ` + availableStockSQL + ` <= 0)
+	AND (products.search_vector @@ search.query OR search.text <% immutable_unaccent(lower(products.name)))`
+
+// searchRankSQL ranks full-text matches above fuzzy ones
+const searchRankSQL = `CASE WHEN products.search_vector @@ search.query
+	THEN 1 + ts_rank(products.search_vector, search.query)
+	ELSE word_similarity(search.text, immutable_unaccent(lower(products.name))) END`
+
+// Highlighted matches are wrapped in these private use characters, which product texts do not
+// contain, so that the texts can be escaped before the markup is added
+const (
+	HighlightStart = "\uE000"
+	HighlightStop  = "\uE001"
+)
+
+// ProductSearchFilter narrows a product search
+type ProductSearchFilter struct {
+	Query    string
+	Category models.ProductCategory // Empty for every category
+	StoreID  uuid.UUID              // uuid.Nil for every store
+}
+
+// ProductSearchHit is a matching product ID with its rank and highlighted fields
+type ProductSearchHit struct {
+	ProductID            uuid.UUID
+	Rank                 float64
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+// FacetCount is the number of matching products with a facet value
+type FacetCount struct {
+	Value string
+	Label string
+	Count int64
+}
+
+// ProductSearchRepository runs full-text product searches
+type ProductSearchRepository struct {
+	db *gorm.DB
+}
+
+// NewProductSearchRepository creates a new product search repository
+func NewProductSearchRepository(db *gorm.DB) *ProductSearchRepository {
+	return &ProductSearchRepository{db: db}
+}
+
+// Search returns a page of matching products, best match first
+func (r *ProductSearchRepository) Search(filter ProductSearchFilter, limit, offset int) ([]ProductSearchHit, error) {
+	args := searchArgs(filter)
+	args["limit"] = limit
+	args["offset"] = offset
+
+	var hits []ProductSearchHit
+	err := r.db.Raw(searchQueryCTE+`
+		SELECT products.id AS product_id,
+			`+searchRankSQL+` AS rank,
+			ts_headline('spanish_unaccent', products.name, search.query,
+				'HighlightAll=true, StartSel="`+HighlightStart+`", StopSel="`+HighlightStop+`"') AS name_highlight,
+			ts_headline('spanish_unaccent', coalesce(products.description, ''), search.query,
+				'MaxFragments=2, MinWords=5, MaxWords=20, StartSel="`+HighlightStart+`", StopSel="`+HighlightStop+`"') AS description_highlight
+		`+searchFromSQL+`
+		WHERE `+searchMatchSQL+searchFilterSQL(filter, true, true)+`
+		ORDER BY rank DESC, products.name ASC, products.id ASC
+		LIMIT @limit OFFSET @offset`, args).
+		Scan(&hits).Error
+	return hits, err
+}
+
+// Count returns the number of matching products
+func (r *ProductSearchRepository) Count(filter ProductSearchFilter) (int64, error) {
+	var count int64
+	err := r.db.Raw(searchQueryCTE+`
+		SELECT count(*) `+searchFromSQL+`
+		WHERE `+searchMatchSQL+searchFilterSQL(filter, true, true), searchArgs(filter)).
+		Scan(&count).Error
+	return count, err
+}
+
+// CategoryFacets counts the matching products per category. The category filter is ignored
+// so that the counts show how many results each category would give.
+func (r *ProductSearchRepository) CategoryFacets(filter ProductSearchFilter) ([]FacetCount, error) {
+	var facets []FacetCount
+	err := r.db.Raw(searchQueryCTE+`
+		SELECT products.category::text AS value, products.category::text AS label, count(*) AS count
+		`+searchFromSQL+`
+		WHERE `+searchMatchSQL+searchFilterSQL(filter, false, true)+`
+		GROUP BY products.category
+		ORDER BY count DESC, value ASC`, searchArgs(filter)).
+		Scan(&facets).Error
+	return facets, err
+}
+
+// StoreFacets counts the matching products per store, ignoring the store filter
+func (r *ProductSearchRepository) StoreFacets(filter ProductSearchFilter) ([]FacetCount, error) {
+	var facets []FacetCount
+	err := r.db.Raw(searchQueryCTE+`
+		SELECT products.store_id::text AS value, stores.name AS label, count(*) AS count
+		`+searchFromSQL+`
+		WHERE `+searchMatchSQL+searchFilterSQL(filter, true, false)+`
+		GROUP BY products.store_id, stores.name
+		ORDER BY count DESC, label ASC`, searchArgs(filter)).
+		Scan(&facets).Error
+	return facets, err
+}
+
+// searchArgs returns the named arguments of the search queries
+func searchArgs(filter ProductSearchFilter) map[string]interface{} {
+	return map[string]interface{}{
+		"q":        filter.Query,
+		"category": filter.Category,
+		"store_id": filter.StoreID,
+	}
+}
+
+// searchFilterSQL returns the category and store conditions of a search
+func searchFilterSQL(filter ProductSearchFilter, byCategory, byStore bool) string {
+	sql := ""
+	if byCategory && filter.Category != "" {
+		sql += " AND products.category = @category"
+	}
+	if byStore && filter.StoreID != uuid.Nil {
+		sql += " AND products.store_id = @store_id"
+	}
+	return sql
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/ruranjo/unientrega/internal/models"
+	"gorm.io/gorm"
+)
+
+// RefundRepository handles database operations for refunds
+type RefundRepository struct {
+	db *gorm.DB
+}
+
+// NewRefundRepository creates a new refund repository
+func NewRefundRepository(db *gorm.DB) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *RefundRepository) WithTx(tx *gorm.DB) *RefundRepository {
+	return &RefundRepository{db: tx}
+}
+
+// Create creates a new refund
+func (r *RefundRepository) Create(refund *models.Refund) error {
+	return r.db.Create(refund).Error
+}
+
+// ListByOrder retrieves the refunds of an order, oldest first
+func (r *RefundRepository) ListByOrder(orderID uuid.UUID) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := r.db.Where("order_id = ?", orderID).Order("created_at asc").Find(&refunds).Error
+	return refunds, err
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// SessionRepository handles database operations for user sessions and their refresh tokens
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *SessionRepository) WithTx(tx *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: tx}
+}
+
+// Create creates a new session
+func (r *SessionRepository) Create(session *models.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+// GetByID finds a session by ID
+func (r *SessionRepository) GetByID(id uuid.UUID) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := r.db.First(&session, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// CreateRefreshToken stores a refresh token issued to a session
+func (r *SessionRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetRefreshTokenByHash finds a refresh token by its hash, with its session
+func (r *SessionRepository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.Preload("Session").Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListActiveByUser lists the sessions of a user that are neither revoked nor expired,
+// most recently used first
+func (r *SessionRepository) ListActiveByUser(userID uuid.UUID) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// MarkRefreshTokenUsed marks a refresh token as rotated unless it already was.
+// It returns false when the token had been used before.
+func (r *SessionRepository) MarkRefreshTokenUsed(id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Touch records the use of an active session by a device and extends it until the given time.
+// It returns false when the session has been revoked.
+func (r *SessionRepository) Touch(id uuid.UUID, userAgent, ipAddress string, at, expiresAt time.Time) (bool, error) {
+	result := r.db.Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"user_agent":   userAgent,
+			"ip_address":   ipAddress,
+			"last_used_at": at,
+			"expires_at":   expiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Revoke ends an active session of a user. It returns false when the user has no such active session.
+func (r *SessionRepository) Revoke(id, userID uuid.UUID, reason models.SessionRevocation) (bool, error) {
+	result := r.db.Model(&models.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Updates(map[string]interface{}{
+			"revoked_at":        time.Now(),
+			"revocation_reason": reason,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeByUser ends every active session of a user except the given one, which may be uuid.Nil.
+// It returns the number of sessions revoked.
+func (r *SessionRepository) RevokeByUser(userID, exceptID uuid.UUID, reason models.SessionRevocation) (int64, error) {
+	result := r.db.Model(&models.UserSession{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptID).
+		Updates(map[string]interface{}{
+			"revoked_at":        time.Now(),
+			"revocation_reason": reason,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteExpired removes the refresh tokens and sessions that expired before the given time,
+// and the sessions revoked before it. Refresh tokens of removed sessions go with them.
+func (r *SessionRepository) DeleteExpired(before time.Time) (int64, error) {
+	if err := r.db.Where("expires_at < ?", before).Delete(&models.RefreshToken{}).Error; err != nil {
+		return 0, err
+	}
+	result := r.db.Where("expires_at < ? OR revoked_at < ?", before, before).Delete(&models.UserSession{})
+	return result.RowsAffected, result.Error
+}
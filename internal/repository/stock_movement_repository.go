@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// StockMovementRepository handles database operations for the inventory ledger.
+// Movements are only ever inserted, never updated or deleted.
+type StockMovementRepository struct {
+	db *gorm.DB
+}
+
+// NewStockMovementRepository creates a new stock movement repository
+func NewStockMovementRepository(db *gorm.DB) *StockMovementRepository {
+	return &StockMovementRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *StockMovementRepository) WithTx(tx *gorm.DB) *StockMovementRepository {
+	return &StockMovementRepository{db: tx}
+}
+
+// Create appends a movement to the ledger
+func (r *StockMovementRepository) Create(movement *models.StockMovement) error {
+	return r.db.Create(movement).Error
+}
+
+// ListByProduct returns a page of the movements of a product, newest first, with the cursor of
+// the next page. A variant ID limits the list to the movements of that variant.
+// The total is only counted in offset mode.
+func (r *StockMovementRepository) ListByProduct(productID uuid.UUID, variantID *uuid.UUID, page Page) ([]models.StockMovement, string, int64, error) {
+	query := r.db.Model(&models.StockMovement{}).Where("product_id = ?", productID)
+	if variantID != nil {
+		query = query.Where("variant_id = ?", *variantID)
+	}
+
+	var total int64
+	if page.After == nil {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, "", 0, err
+		}
+	}
+
+	var movements []models.StockMovement
+	if err := paginate(query, "stock_movements", page, false).Find(&movements).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	movements, next := nextCursor(movements, page, func(movement models.StockMovement) Cursor {
+		return Cursor{CreatedAt: movement.CreatedAt, ID: movement.ID}
+	})
+	return movements, next, total, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// StoreHoursRepository handles database operations for store opening hours and special hours
+type StoreHoursRepository struct {
+	db *gorm.DB
+}
+
+// NewStoreHoursRepository creates a new store hours repository
+func NewStoreHoursRepository(db *gorm.DB) *StoreHoursRepository {
+	return &StoreHoursRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *StoreHoursRepository) WithTx(tx *gorm.DB) *StoreHoursRepository {
+	return &StoreHoursRepository{db: tx}
+}
+
+// ListOpeningHours retrieves the weekly schedule of a store ordered by day and time
+func (r *StoreHoursRepository) ListOpeningHours(storeID uuid.UUID) ([]models.StoreOpeningHours, error) {
+	var hours []models.StoreOpeningHours
+	err := r.db.Where("store_id = ?", storeID).Order("weekday asc, opens_at asc").Find(&hours).Error
+	return hours, err
+}
+
+// ReplaceOpeningHours replaces the weekly schedule of a store.
+// It should run inside a transaction.
+func (r *StoreHoursRepository) ReplaceOpeningHours(storeID uuid.UUID, hours []models.StoreOpeningHours) error {
+	if err := r.db.Where("store_id = ?", storeID).Delete(&models.StoreOpeningHours{}).Error; err != nil {
+		return err
+	}
+	if len(hours) == 0 {
+		return nil
+	}
+	return r.db.Create(&hours).Error
+}
+
+// CreateSpecialHours creates special hours for a range of dates
+func (r *StoreHoursRepository) CreateSpecialHours(special *models.StoreSpecialHours) error {
+	return r.db.Create(special).Error
+}
+
+// GetSpecialHours finds special hours by ID
+func (r *StoreHoursRepository) GetSpecialHours(id uuid.UUID) (*models.StoreSpecialHours, error) {
+	var special models.StoreSpecialHours
+	err := r.db.Where("id = ?", id).First(&special).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("special hours not found")
+		}
+		return nil, err
+	}
+	return &special, nil
+}
+
+// ListSpecialHours retrieves the special hours of a store that end on or after a date (YYYY-MM-DD),
+// most recently created first
+func (r *StoreHoursRepository) ListSpecialHours(storeID uuid.UUID, fromDate string) ([]models.StoreSpecialHours, error) {
+	var specials []models.StoreSpecialHours
+	err := r.db.Where("store_id = ? AND end_date >= ?", storeID, fromDate).
+		Order("created_at desc").
+		Find(&specials).Error
+	return specials, err
+}
+
+// DeleteSpecialHours deletes special hours
+func (r *StoreHoursRepository) DeleteSpecialHours(id uuid.UUID) error {
+	return r.db.Delete(&models.StoreSpecialHours{}, "id = ?", id).Error
+}
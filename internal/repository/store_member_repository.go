@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// StoreMemberRepository handles database operations for store members and invitations
+type StoreMemberRepository struct {
+	db *gorm.DB
+}
+
+// NewStoreMemberRepository creates a new store member repository
+func NewStoreMemberRepository(db *gorm.DB) *StoreMemberRepository {
+	return &StoreMemberRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *StoreMemberRepository) WithTx(tx *gorm.DB) *StoreMemberRepository {
+	return &StoreMemberRepository{db: tx}
+}
+
+// Create creates a new store member
+func (r *StoreMemberRepository) Create(member *models.StoreMember) error {
+	return r.db.Create(member).Error
+}
+
+// GetByStoreAndUser finds the membership of a user at a store
+func (r *StoreMemberRepository) GetByStoreAndUser(storeID, userID uuid.UUID) (*models.StoreMember, error) {
+	var member models.StoreMember
+	err := r.db.Where("store_id = ? AND user_id = ?", storeID, userID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("store member not found")
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListByStore retrieves the members of a store with their users, highest role first
+func (r *StoreMemberRepository) ListByStore(storeID uuid.UUID) ([]models.StoreMember, error) {
+	var members []models.StoreMember
+	err := r.db.Preload("User").
+		Where("store_id = ?", storeID).
+		Order("CASE role WHEN 'owner' THEN 1 WHEN 'manager' THEN 2 ELSE 3 END, created_at asc").
+		Find(&members).Error
+	return members, err
+}
+
+// ListByUser retrieves the store memberships of a user with their stores
+func (r *StoreMemberRepository) ListByUser(userID uuid.UUID) ([]models.StoreMember, error) {
+	var members []models.StoreMember
+	err := r.db.Preload("Store").
+		Where("user_id = ?", userID).
+		Order("created_at asc").
+		Find(&members).Error
+	return members, err
+}
+
+// CountByRole counts the members of a store with a role
+func (r *StoreMemberRepository) CountByRole(storeID uuid.UUID, role models.StoreMemberRole) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.StoreMember{}).Where("store_id = ? AND role = ?", storeID, role).Count(&count).Error
+	return count, err
+}
+
+// UpdateRole changes the role of a store member
+func (r *StoreMemberRepository) UpdateRole(id uuid.UUID, role models.StoreMemberRole) error {
+	return r.db.Model(&models.StoreMember{}).Where("id = ?", id).Update("role", role).Error
+}
+
+// Delete removes a store member
+func (r *StoreMemberRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.StoreMember{}, "id = ?", id).Error
+}
+
+// CreateInvitation creates a new store invitation
+func (r *StoreMemberRepository) CreateInvitation(invitation *models.StoreInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+// GetInvitation finds a store invitation by ID
+func (r *StoreMemberRepository) GetInvitation(id uuid.UUID) (*models.StoreInvitation, error) {
+	var invitation models.StoreInvitation
+	err := r.db.Where("id = ?", id).First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invitation not found")
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetInvitationByTokenHash finds a store invitation by the hash of its token
+func (r *StoreMemberRepository) GetInvitationByTokenHash(tokenHash string) (*models.StoreInvitation, error) {
+	var invitation models.StoreInvitation
+	err := r.db.Where("token_hash = ?", tokenHash).First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invitation not found")
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// ListPendingInvitations retrieves the pending invitations of a store, newest first
+func (r *StoreMemberRepository) ListPendingInvitations(storeID uuid.UUID) ([]models.StoreInvitation, error) {
+	var invitations []models.StoreInvitation
+	err := r.db.Where("store_id = ? AND status = ?", storeID, models.StoreInvitationPending).
+		Order("created_at desc").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// RevokePendingByEmail revokes the pending invitations of an email address at a store
+func (r *StoreMemberRepository) RevokePendingByEmail(storeID uuid.UUID, email string) error {
+	return r.db.Model(&models.StoreInvitation{}).
+		Where("store_id = ? AND email = ? AND status = ?", storeID, email, models.StoreInvitationPending).
+		Update("status", models.StoreInvitationRevoked).Error
+}
+
+// RevokeInvitation revokes a pending invitation.
+// It returns false if the invitation is no longer pending.
+func (r *StoreMemberRepository) RevokeInvitation(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.StoreInvitation{}).
+		Where("id = ? AND status = ?", id, models.StoreInvitationPending).
+		Update("status", models.StoreInvitationRevoked)
+	return result.RowsAffected == 1, result.Error
+}
+
+// AcceptInvitation marks a pending invitation as accepted.
+// It returns false if the invitation is no longer pending.
+func (r *StoreMemberRepository) AcceptInvitation(id, userID uuid.UUID, acceptedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.StoreInvitation{}).
+		Where("id = ? AND status = ?", id, models.StoreInvitationPending).
+		Updates(map[string]interface{}{
+			"status":      models.StoreInvitationAccepted,
+			"accepted_by": userID,
+			"accepted_at": acceptedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
@@ -2,11 +2,13 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/utils"
 )
 
 // StoreRepository handles database operations for stores
@@ -19,6 +21,11 @@ func NewStoreRepository(db *gorm.DB) *StoreRepository {
 	return &StoreRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *StoreRepository) WithTx(tx *gorm.DB) *StoreRepository {
+	return &StoreRepository{db: tx}
+}
+
 // Create creates a new store
 func (r *StoreRepository) Create(store *models.Store) error {
 	return r.db.Create(store).Error
@@ -37,10 +44,13 @@ func (r *StoreRepository) GetByID(id uuid.UUID) (*models.Store, error) {
 	return &store, nil
 }
 
-// GetByOwnerID finds stores managed by a specific user
+// GetByOwnerID finds stores where a user is an owner member
 func (r *StoreRepository) GetByOwnerID(ownerID uuid.UUID) ([]*models.Store, error) {
 	var stores []*models.Store
-	err := r.db.Where("owner_id = ?", ownerID).Find(&stores).Error
+	err := r.db.
+		Joins("JOIN store_members ON store_members.store_id = stores.id").
+		Where("store_members.user_id = ? AND store_members.role = ?", ownerID, models.StoreMemberOwner).
+		Find(&stores).Error
 	return stores, err
 }
 
@@ -54,31 +64,53 @@ func (r *StoreRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.Store{}, id).Error
 }
 
-// List returns a list of stores with optional filters
-func (r *StoreRepository) List(limit, offset int, activeOnly bool) ([]*models.Store, error) {
+// List returns a page of stores matching a list query, newest first unless sorted otherwise,
+// with the cursor of the next page
+func (r *StoreRepository) List(page Page, list utils.ListQuery) ([]*models.Store, string, error) {
 	var stores []*models.Store
-	query := r.db.Limit(limit).Offset(offset)
 
-	if activeOnly {
-		query = query.Where("is_active = ?", true)
+	query, err := storeListSchema.filter(r.db.Model(&models.Store{}), list)
+	if err != nil {
+		return nil, "", err
+	}
+	query, err = storeListSchema.sort(query, list, &page)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Order by name
-	query = query.Order("name ASC")
+	if err := paginate(query, "stores", page, false).Find(&stores).Error; err != nil {
+		return nil, "", err
+	}
 
-	err := query.Find(&stores).Error
-	return stores, err
+	stores, next := nextCursor(stores, page, func(store *models.Store) Cursor {
+		return Cursor{CreatedAt: store.CreatedAt, ID: store.ID}
+	})
+	return stores, next, nil
 }
 
-// Count returns the total number of stores
-func (r *StoreRepository) Count(activeOnly bool) (int64, error) {
+// Count returns the total number of stores matching the filters of a list query
+func (r *StoreRepository) Count(list utils.ListQuery) (int64, error) {
 	var count int64
-	query := r.db.Model(&models.Store{})
 
-	if activeOnly {
-		query = query.Where("is_active = ?", true)
+	query, err := storeListSchema.filter(r.db.Model(&models.Store{}), list)
+	if err != nil {
+		return 0, err
 	}
 
-	err := query.Count(&count).Error
+	err = query.Count(&count).Error
 	return count, err
 }
+
+// SetTimeZone changes the time zone of the store opening hours
+func (r *StoreRepository) SetTimeZone(id uuid.UUID, timeZone string) error {
+	return r.db.Model(&models.Store{}).Where("id = ?", id).Update("time_zone", timeZone).Error
+}
+
+// SetPause pauses or resumes order intake of a store
+func (r *StoreRepository) SetPause(id uuid.UUID, paused bool, until *time.Time, reason string) error {
+	return r.db.Model(&models.Store{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"is_paused":    paused,
+		"paused_until": until,
+		"pause_reason": reason,
+	}).Error
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// TimeSlotRepository handles database operations for scheduled order time slots
+type TimeSlotRepository struct {
+	db *gorm.DB
+}
+
+// NewTimeSlotRepository creates a new time slot repository
+func NewTimeSlotRepository(db *gorm.DB) *TimeSlotRepository {
+	return &TimeSlotRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *TimeSlotRepository) WithTx(tx *gorm.DB) *TimeSlotRepository {
+	return &TimeSlotRepository{db: tx}
+}
+
+// GetSettings finds the time slot settings of a store
+func (r *TimeSlotRepository) GetSettings(storeID uuid.UUID) (*models.TimeSlotSettings, error) {
+	var settings models.TimeSlotSettings
+	err := r.db.Where("store_id = ?", storeID).First(&settings).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("time slot settings not found")
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SaveSettings creates or replaces the time slot settings of a store
+func (r *TimeSlotRepository) SaveSettings(settings *models.TimeSlotSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "store_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"capacity", "lead_minutes", "max_days_ahead", "updated_at"}),
+	}).Create(settings).Error
+}
+
+// ListBookings retrieves the bookings of a store for slots starting in [from, to)
+func (r *TimeSlotRepository) ListBookings(storeID uuid.UUID, from, to time.Time) ([]models.TimeSlotBooking, error) {
+	var bookings []models.TimeSlotBooking
+	err := r.db.Where("store_id = ? AND starts_at >= ? AND starts_at < ?", storeID, from, to).
+		Order("starts_at asc").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// Reserve books one order in a slot if it has capacity left.
+// It returns false if the slot is full.
+func (r *TimeSlotRepository) Reserve(storeID uuid.UUID, startsAt time.Time, capacity int) (bool, error) {
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.TimeSlotBooking{
+		StoreID:  storeID,
+		StartsAt: startsAt,
+	}).Error
+	if err != nil {
+		return false, err
+	}
+
+	result := r.db.Model(&models.TimeSlotBooking{}).
+		Where("store_id = ? AND starts_at = ? AND booked < ?", storeID, startsAt, capacity).
+		Updates(map[string]interface{}{"booked": gorm.Expr("booked + 1"), "updated_at": time.Now()})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Release frees the capacity booked by one order in a slot
+func (r *TimeSlotRepository) Release(storeID uuid.UUID, startsAt time.Time) error {
+	return r.db.Model(&models.TimeSlotBooking{}).
+		Where("store_id = ? AND starts_at = ? AND booked > 0", storeID, startsAt).
+		Updates(map[string]interface{}{"booked": gorm.Expr("booked - 1"), "updated_at": time.Now()}).Error
+}
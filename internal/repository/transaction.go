@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// TxManager runs repository operations inside a single database transaction
+type TxManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager creates a new transaction manager
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTransaction executes fn inside a transaction.
+// The transaction is committed if fn returns nil and rolled back otherwise.
+// Repositories used inside fn must be bound to tx with their WithTx method.
+func (m *TxManager) WithinTransaction(fn func(tx *gorm.DB) error) error {
+	return m.db.Transaction(fn)
+}
@@ -7,6 +7,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/utils"
 )
 
 // UserRepository handles database operations for users
@@ -19,6 +20,11 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *UserRepository) WithTx(tx *gorm.DB) *UserRepository {
+	return &UserRepository{db: tx}
+}
+
 // Create creates a new user
 func (r *UserRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
@@ -55,28 +61,50 @@ func (r *UserRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+// UpdateRole changes the role of a user
+func (r *UserRepository) UpdateRole(id uuid.UUID, role models.Role) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
 // Delete soft deletes a user
 func (r *UserRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.User{}, id).Error
 }
 
-// List returns a list of users with optional filters
-func (r *UserRepository) List(limit, offset int, role models.Role) ([]*models.User, error) {
+// List returns a page of users matching a list query, newest first unless sorted otherwise,
+// with the cursor of the next page
+func (r *UserRepository) List(page Page, list utils.ListQuery) ([]*models.User, string, error) {
 	var users []*models.User
-	query := r.db.Limit(limit).Offset(offset)
 
-	if role != "" && role.IsValid() {
-		query = query.Where("role = ?", role)
+	query, err := userListSchema.filter(r.db.Model(&models.User{}), list)
+	if err != nil {
+		return nil, "", err
+	}
+	query, err = userListSchema.sort(query, list, &page)
+	if err != nil {
+		return nil, "", err
 	}
 
-	err := query.Find(&users).Error
-	return users, err
+	if err := paginate(query, "users", page, false).Find(&users).Error; err != nil {
+		return nil, "", err
+	}
+
+	users, next := nextCursor(users, page, func(user *models.User) Cursor {
+		return Cursor{CreatedAt: user.CreatedAt, ID: user.ID}
+	})
+	return users, next, nil
 }
 
-// Count returns the total number of users
-func (r *UserRepository) Count() (int64, error) {
+// Count returns the total number of users matching the filters of a list query
+func (r *UserRepository) Count(list utils.ListQuery) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.User{}).Count(&count).Error
+
+	query, err := userListSchema.filter(r.db.Model(&models.User{}), list)
+	if err != nil {
+		return 0, err
+	}
+
+	err = query.Count(&count).Error
 	return count, err
 }
 
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/middleware"
+)
+
+// SetupAddressRoutes configures saved delivery address routes
+func SetupAddressRoutes(v1 *gin.RouterGroup, addressHandler *handlers.AddressHandler) {
+	addresses := v1.Group("/addresses")
+	addresses.Use(middleware.AuthRequired())
+	{
+		// Users manage their own addresses only
+		addresses.GET("", addressHandler.ListAddresses)
+		addresses.POST("", addressHandler.CreateAddress)
+		addresses.PUT("/:id", addressHandler.UpdateAddress)
+		addresses.DELETE("/:id", addressHandler.DeleteAddress)
+	}
+}
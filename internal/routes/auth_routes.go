@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/ruranjo/unientrega/internal/handlers"
 	"github.com/ruranjo/unientrega/internal/middleware"
+	"github.com/ruranjo/unientrega/internal/models"
 )
 
 // SetupAuthRoutes configures authentication routes
@@ -26,6 +27,20 @@ func SetupAuthRoutes(v1 *gin.RouterGroup, authHandler *handlers.AuthHandler) {
 		{
 			authProtected.GET("/me", authHandler.GetMe)
 			authProtected.POST("/logout", authHandler.Logout)
+
+			// Sessions of the current user
+			authProtected.GET("/sessions", authHandler.ListSessions)
+			authProtected.DELETE("/sessions", authHandler.LogoutEverywhere)
+			authProtected.DELETE("/sessions/:id", authHandler.RevokeSession)
 		}
 	}
+
+	// Sessions of any user (admin only)
+	users := v1.Group("/users")
+	users.Use(middleware.AuthRequired(), middleware.RoleRequired(models.RoleSuperUser))
+	{
+		users.GET("/:id/sessions", authHandler.ListUserSessions)
+		users.DELETE("/:id/sessions", authHandler.RevokeUserSessions)
+		users.DELETE("/:id/sessions/:session_id", authHandler.RevokeUserSession)
+	}
 }
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/middleware"
+)
+
+// SetupCartRoutes configures shopping cart routes.
+// Checkout honors the Idempotency-Key header like order creation.
+func SetupCartRoutes(v1 *gin.RouterGroup, cartHandler *handlers.CartHandler, idempotency gin.HandlerFunc) {
+	cart := v1.Group("/cart")
+	cart.Use(middleware.AuthRequired())
+	{
+		// Users manage their own carts only, one per store
+		cart.GET("", cartHandler.ListCarts)
+		cart.GET("/:store_id", cartHandler.GetCart)
+		cart.DELETE("/:store_id", cartHandler.ClearCart)
+		cart.POST("/:store_id/items", cartHandler.AddItem)
+		cart.PUT("/:store_id/items/:item_id", cartHandler.UpdateItem)
+		cart.DELETE("/:store_id/items/:item_id", cartHandler.RemoveItem)
+		cart.POST("/:store_id/checkout", idempotency, cartHandler.Checkout)
+	}
+}
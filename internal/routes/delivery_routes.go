@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/middleware"
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// SetupDeliveryRoutes configures courier delivery routes
+func SetupDeliveryRoutes(v1 *gin.RouterGroup, deliveryHandler *handlers.DeliveryHandler, dispatchHandler *handlers.DispatchHandler) {
+	deliveries := v1.Group("/deliveries")
+	deliveries.Use(middleware.AuthRequired(), middleware.RoleRequired(models.RoleDelivery))
+	{
+		// List own active or past deliveries
+		deliveries.GET("", deliveryHandler.ListMyDeliveries)
+
+		// List ready orders that still need a courier
+		deliveries.GET("/available", deliveryHandler.ListAvailable)
+
+		// Courier availability for automatic dispatch
+		deliveries.GET("/availability", dispatchHandler.GetAvailability)
+		deliveries.PUT("/availability", dispatchHandler.SetAvailability)
+
+		// Delivery offers made by the dispatcher
+		deliveries.GET("/offers", dispatchHandler.ListOffers)
+		deliveries.POST("/offers/:id/accept", dispatchHandler.AcceptOffer)
+		deliveries.POST("/offers/:id/decline", dispatchHandler.DeclineOffer)
+
+		// Claim, release, pick up and deliver an order
+		deliveries.POST("/:id/claim", deliveryHandler.ClaimOrder)
+		deliveries.POST("/:id/release", deliveryHandler.ReleaseOrder)
+		deliveries.POST("/:id/pickup", deliveryHandler.MarkPickedUp)
+		deliveries.POST("/:id/deliver", deliveryHandler.MarkDelivered)
+	}
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/middleware"
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// SetupLocationRoutes configures campus location catalog routes
+func SetupLocationRoutes(v1 *gin.RouterGroup, locationHandler *handlers.LocationHandler) {
+	locations := v1.Group("/locations")
+	locations.Use(middleware.AuthRequired())
+	{
+		// List and view locations (all authenticated users)
+		locations.GET("", locationHandler.ListLocations)
+		locations.GET("/:id", locationHandler.GetLocation)
+
+		// Manage the catalog (superuser only)
+		locations.POST("", middleware.RoleRequired(models.RoleSuperUser), locationHandler.CreateLocation)
+		locations.PUT("/:id", middleware.RoleRequired(models.RoleSuperUser), locationHandler.UpdateLocation)
+		locations.DELETE("/:id", middleware.RoleRequired(models.RoleSuperUser), locationHandler.DeleteLocation)
+	}
+}
@@ -7,13 +7,14 @@ import (
 	"github.com/ruranjo/unientrega/internal/models"
 )
 
-// SetupOrderRoutes configures order management routes
-func SetupOrderRoutes(v1 *gin.RouterGroup, orderHandler *handlers.OrderHandler) {
+// SetupOrderRoutes configures order management routes.
+// Order creation honors the Idempotency-Key header so that retried requests do not create duplicate orders.
+func SetupOrderRoutes(v1 *gin.RouterGroup, orderHandler *handlers.OrderHandler, idempotency gin.HandlerFunc) {
 	orders := v1.Group("/orders")
 	orders.Use(middleware.AuthRequired())
 	{
 		// Create order (authenticated users)
-		orders.POST("", orderHandler.CreateOrder)
+		orders.POST("", idempotency, orderHandler.CreateOrder)
 
 		// List orders (authenticated users - logic in handler)
 		orders.GET("", orderHandler.ListOrders)
@@ -21,7 +22,13 @@ func SetupOrderRoutes(v1 *gin.RouterGroup, orderHandler *handlers.OrderHandler)
 		// Get order by ID (authenticated users - logic in handler)
 		orders.GET("/:id", orderHandler.GetOrder)
 
-		// Update order status (store owner or superuser)
+		// Get order status history (same visibility as the order itself)
+		orders.GET("/:id/timeline", orderHandler.GetOrderTimeline)
+
+		// Cancel order (customer while pending, store staff until completed, superuser)
+		orders.POST("/:id/cancel", orderHandler.CancelOrder)
+
+		// Update order status (store members or superuser)
 		orders.PATCH("/:id/status", middleware.RoleRequired(models.RoleSuperUser, models.RoleStore), orderHandler.UpdateOrderStatus)
 	}
 }
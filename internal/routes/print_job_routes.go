@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/middleware"
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// SetupPrintJobRoutes configures print job upload routes, the print pricing and the print queue of copy center stores
+func SetupPrintJobRoutes(v1 *gin.RouterGroup, printJobHandler *handlers.PrintJobHandler, printQueueHandler *handlers.PrintQueueHandler) {
+	// Document downloads are authorized by the signed URL so printers and browsers can open them directly
+	v1.GET("/print-jobs/:id/download", printQueueHandler.Download)
+
+	printJobs := v1.Group("/print-jobs")
+	printJobs.Use(middleware.AuthRequired())
+	{
+		// Users manage their own print jobs until they are ordered
+		printJobs.POST("", printJobHandler.CreatePrintJob)
+		printJobs.GET("", printJobHandler.ListPrintJobs)
+		printJobs.GET("/:id", printJobHandler.GetPrintJob)
+		printJobs.PUT("/:id", printJobHandler.UpdatePrintJob)
+		printJobs.DELETE("/:id", printJobHandler.DeletePrintJob)
+	}
+
+	stores := v1.Group("/stores")
+	stores.Use(middleware.AuthRequired())
+	{
+		// Print pricing (all authenticated users can view, store owners and managers, or superuser can change)
+		stores.GET("/:id/print-pricing", printJobHandler.GetPricing)
+		stores.PUT("/:id/print-pricing", middleware.RoleRequired(models.RoleSuperUser, models.RoleStore), printJobHandler.SetPricing)
+
+		// Print queue (store members or superuser)
+		queue := stores.Group("/:id/print-queue")
+		queue.Use(middleware.RoleRequired(models.RoleSuperUser, models.RoleStore))
+		{
+			queue.GET("", printQueueHandler.ListQueue)
+			queue.POST("/:job_id/start", printQueueHandler.StartJob)
+			queue.POST("/:job_id/printed", printQueueHandler.MarkPrinted)
+			queue.POST("/:job_id/problem", printQueueHandler.ReportProblem)
+			queue.POST("/:job_id/download-url", printQueueHandler.CreateDownloadURL)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/middleware"
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// SetupProductImportRoutes configures bulk product import and export routes
+func SetupProductImportRoutes(v1 *gin.RouterGroup, importHandler *handlers.ProductImportHandler) {
+	stores := v1.Group("/stores")
+	stores.Use(middleware.AuthRequired(), middleware.RoleRequired(models.RoleSuperUser, models.RoleStore))
+	{
+		// Import and export the store catalog (store owners and managers, or superuser)
+		stores.POST("/:id/products/import", importHandler.ImportProducts)
+		stores.GET("/:id/products/export", importHandler.ExportProducts)
+	}
+}
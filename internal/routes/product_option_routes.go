@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/middleware"
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// SetupProductOptionRoutes configures product variant and option group routes.
+// Variants and option groups are returned with the product.
+func SetupProductOptionRoutes(v1 *gin.RouterGroup, optionHandler *handlers.ProductOptionHandler) {
+	products := v1.Group("/products")
+	products.Use(middleware.AuthRequired(), middleware.RoleRequired(models.RoleSuperUser, models.RoleStore))
+	{
+		// Variants (store owners and managers, or superuser)
+		products.POST("/:id/variants", optionHandler.CreateVariant)
+		products.PUT("/:id/variants/:variant_id", optionHandler.UpdateVariant)
+		products.DELETE("/:id/variants/:variant_id", optionHandler.DeleteVariant)
+
+		// Option groups and their modifiers (store owners and managers, or superuser)
+		products.POST("/:id/option-groups", optionHandler.CreateOptionGroup)
+		products.PUT("/:id/option-groups/:group_id", optionHandler.UpdateOptionGroup)
+		products.DELETE("/:id/option-groups/:group_id", optionHandler.DeleteOptionGroup)
+	}
+}
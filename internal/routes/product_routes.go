@@ -27,7 +27,13 @@ func SetupProductRoutes(v1 *gin.RouterGroup, productHandler *handlers.ProductHan
 		// Delete product (store and superuser only)
 		products.DELETE("/:id", middleware.RoleRequired(models.RoleSuperUser, models.RoleStore), productHandler.DeleteProduct)
 
-		// Update stock (store and superuser only)
+		// Update stock of several products (store and superuser only)
+		products.PATCH("/stock", middleware.RoleRequired(models.RoleSuperUser, models.RoleStore), productHandler.BulkUpdateStock)
+
+		// Record a stock movement (store and superuser only)
 		products.PATCH("/:id/stock", middleware.RoleRequired(models.RoleSuperUser, models.RoleStore), productHandler.UpdateStock)
+
+		// Stock movement history (store and superuser only)
+		products.GET("/:id/stock-movements", middleware.RoleRequired(models.RoleSuperUser, models.RoleStore), productHandler.ListStockMovements)
 	}
 }
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/middleware"
+)
+
+// SetupProductSearchRoutes configures product search routes
+func SetupProductSearchRoutes(v1 *gin.RouterGroup, searchHandler *handlers.ProductSearchHandler) {
+	products := v1.Group("/products")
+	products.Use(middleware.AuthRequired())
+	{
+		// Search products (all authenticated users can search)
+		products.GET("/search", searchHandler.SearchProducts)
+	}
+}
@@ -1,12 +1,17 @@
 package routes
 
 import (
+	"log"
+
 	"github.com/gin-gonic/gin"
 	"github.com/ruranjo/unientrega/internal/config"
 	"github.com/ruranjo/unientrega/internal/database"
 	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/mailer"
+	"github.com/ruranjo/unientrega/internal/middleware"
 	"github.com/ruranjo/unientrega/internal/repository"
 	"github.com/ruranjo/unientrega/internal/services"
+	"github.com/ruranjo/unientrega/internal/storage"
 )
 
 // SetupRoutes configures all application routes
@@ -15,18 +20,83 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config) {
 	db := database.GetDB()
 
 	// Initialize repositories
+	txManager := repository.NewTxManager(db)
 	userRepo := repository.NewUserRepository(db)
 	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
 	productRepo := repository.NewProductRepository(db)
+	productSearchRepo := repository.NewProductSearchRepository(db)
+	productOptionRepo := repository.NewProductOptionRepository(db)
 	storeRepo := repository.NewStoreRepository(db)
 	orderRepo := repository.NewOrderRepository(db)
+	orderEventRepo := repository.NewOrderStatusEventRepository(db)
+	courierRepo := repository.NewCourierRepository(db)
+	offerRepo := repository.NewDeliveryOfferRepository(db)
+	locationRepo := repository.NewLocationRepository(db)
+	addressRepo := repository.NewDeliveryAddressRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	cartRepo := repository.NewCartRepository(db)
+	printJobRepo := repository.NewPrintJobRepository(db)
+	storeHoursRepo := repository.NewStoreHoursRepository(db)
+	timeSlotRepo := repository.NewTimeSlotRepository(db)
+	memberRepo := repository.NewStoreMemberRepository(db)
+	auditRepo := repository.NewAuditLogRepository(db)
+	stockMovementRepo := repository.NewStockMovementRepository(db)
+
+	// Initialize file storage
+	fileStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+
+	// Initialize mailer
+	mail, err := mailer.New(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
 
 	// Initialize services
-	userService := services.NewUserService(userRepo, passwordResetRepo)
-	authService := services.NewAuthService(userService)
-	storeService := services.NewStoreService(storeRepo, userRepo)
-	productService := services.NewProductService(productRepo)
-	orderService := services.NewOrderService(orderRepo, productRepo, storeRepo)
+	userService := services.NewUserService(txManager, userRepo, passwordResetRepo, sessionRepo)
+	authService := services.NewAuthService(txManager, userService, sessionRepo, cfg.JWT.RefreshExpiration)
+	authService.Start(cfg.JWT.SessionCleanupInterval)
+	middleware.SetSessionChecker(authService)
+	storeAccess := services.NewStoreAccess(storeRepo, memberRepo)
+	storeService := services.NewStoreService(txManager, storeRepo, userRepo, locationRepo, memberRepo, storeAccess)
+	storeMemberService := services.NewStoreMemberService(txManager, storeRepo, memberRepo, userRepo, storeAccess, mail, cfg.Membership.InvitationTTL)
+	auditService := services.NewAuditService(auditRepo)
+	inventoryService := services.NewInventoryService(productRepo, productOptionRepo, stockMovementRepo)
+	productService := services.NewProductService(txManager, productRepo, productOptionRepo, inventoryService, storeAccess, auditService)
+	productOptionService := services.NewProductOptionService(txManager, productRepo, productOptionRepo, inventoryService, productService)
+	productImportService := services.NewProductImportService(txManager, productRepo, productOptionRepo, inventoryService, storeAccess, productService)
+	productSearchService := services.NewProductSearchService(productSearchRepo, productRepo)
+	storeHoursService := services.NewStoreHoursService(txManager, storeRepo, storeHoursRepo, storeAccess)
+	timeSlotService := services.NewTimeSlotService(storeRepo, timeSlotRepo, storeHoursService, storeAccess)
+	pricingService := services.NewPricingService(cfg.Pricing)
+	orderService := services.NewOrderService(txManager, orderRepo, orderEventRepo, productRepo, productOptionRepo, storeRepo, locationRepo, addressRepo, refundRepo, printJobRepo, pricingService, storeHoursService, timeSlotService, inventoryService, storeAccess)
+	printJobService := services.NewPrintJobService(printJobRepo, storeRepo, fileStorage, cfg.Storage.MaxUploadSize, storeAccess)
+	printQueueService := services.NewPrintQueueService(txManager, printJobRepo, orderRepo, storeRepo, orderService, fileStorage, cfg.Storage.SigningSecret, cfg.Storage.DownloadURLTTL, storeAccess)
+	cartService := services.NewCartService(cartRepo, productRepo, storeRepo, orderService, cfg.Cart.TTL)
+	locationService := services.NewLocationService(locationRepo)
+	addressService := services.NewAddressService(txManager, addressRepo, locationRepo)
+	deliveryService := services.NewDeliveryService(txManager, orderRepo, orderService)
+	dispatchStrategy, err := services.NewDispatchStrategy(cfg.Dispatch.Strategy)
+	if err != nil {
+		log.Printf("%v, falling back to %s", err, services.DispatchLeastLoaded)
+		dispatchStrategy = services.LeastLoadedStrategy{}
+	}
+	dispatchService := services.NewDispatchService(txManager, courierRepo, offerRepo, orderRepo, storeRepo, dispatchStrategy, cfg.Dispatch.OfferTimeout, cfg.Dispatch.ReofferCooldown, cfg.Dispatch.SweepInterval)
+	if cfg.Dispatch.Enabled {
+		orderService.AddStatusListener(dispatchService)
+		deliveryService.AddAssignmentListener(dispatchService)
+		dispatchService.Start()
+	}
+	lowStockService := services.NewLowStockService(productRepo, storeRepo, memberRepo, mail)
+	if cfg.Inventory.LowStockAlerts {
+		lowStockService.Start(cfg.Inventory.LowStockCheckInterval)
+	}
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, cfg.Idempotency.TTL)
+	idempotencyService.Start(cfg.Idempotency.CleanupInterval)
 	chatRepo := repository.NewChatRepository(db)
 	chatService := services.NewChatService(chatRepo)
 
@@ -36,8 +106,21 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config) {
 	authHandler := handlers.NewAuthHandler(authService, userService)
 	userHandler := handlers.NewUserHandler(userService)
 	productHandler := handlers.NewProductHandler(productService)
+	productOptionHandler := handlers.NewProductOptionHandler(productOptionService)
+	productImportHandler := handlers.NewProductImportHandler(productImportService)
+	productSearchHandler := handlers.NewProductSearchHandler(productSearchService)
 	storeHandler := handlers.NewStoreHandler(storeService)
+	storeMemberHandler := handlers.NewStoreMemberHandler(storeMemberService)
+	storeHoursHandler := handlers.NewStoreHoursHandler(storeHoursService)
+	timeSlotHandler := handlers.NewTimeSlotHandler(timeSlotService)
 	orderHandler := handlers.NewOrderHandler(orderService)
+	cartHandler := handlers.NewCartHandler(cartService)
+	printJobHandler := handlers.NewPrintJobHandler(printJobService, cfg.Storage.MaxUploadSize)
+	printQueueHandler := handlers.NewPrintQueueHandler(printQueueService)
+	locationHandler := handlers.NewLocationHandler(locationService)
+	addressHandler := handlers.NewAddressHandler(addressService)
+	deliveryHandler := handlers.NewDeliveryHandler(deliveryService)
+	dispatchHandler := handlers.NewDispatchHandler(dispatchService)
 	chatHandler := handlers.NewChatHandler(chatService)
 
 	// Setup health and root routes
@@ -51,7 +134,19 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config) {
 	SetupAuthRoutes(v1, authHandler)
 	SetupUserRoutes(v1, userHandler)
 	SetupStoreRoutes(v1, storeHandler)
+	SetupStoreMemberRoutes(v1, storeMemberHandler)
+	SetupStoreHoursRoutes(v1, storeHoursHandler)
+	SetupTimeSlotRoutes(v1, timeSlotHandler)
 	SetupProductRoutes(v1, productHandler)
-	SetupOrderRoutes(v1, orderHandler)
+	SetupProductOptionRoutes(v1, productOptionHandler)
+	SetupProductImportRoutes(v1, productImportHandler)
+	SetupProductSearchRoutes(v1, productSearchHandler)
+	SetupLocationRoutes(v1, locationHandler)
+	SetupAddressRoutes(v1, addressHandler)
+	idempotency := middleware.Idempotency(idempotencyService)
+	SetupOrderRoutes(v1, orderHandler, idempotency)
+	SetupCartRoutes(v1, cartHandler, idempotency)
+	SetupPrintJobRoutes(v1, printJobHandler, printQueueHandler)
+	SetupDeliveryRoutes(v1, deliveryHandler, dispatchHandler)
 	SetupChatRoutes(v1, chatHandler)
 }
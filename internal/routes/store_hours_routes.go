@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/middleware"
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// SetupStoreHoursRoutes configures store opening hours, special hours and pause mode routes
+func SetupStoreHoursRoutes(v1 *gin.RouterGroup, storeHoursHandler *handlers.StoreHoursHandler) {
+	stores := v1.Group("/stores")
+	stores.Use(middleware.AuthRequired())
+	{
+		// Schedule (all authenticated users can view)
+		stores.GET("/:id/hours", storeHoursHandler.GetSchedule)
+
+		// Schedule management (store owners and managers, or superuser)
+		manage := stores.Group("")
+		manage.Use(middleware.RoleRequired(models.RoleSuperUser, models.RoleStore))
+		{
+			manage.PUT("/:id/hours", storeHoursHandler.SetOpeningHours)
+			manage.POST("/:id/special-hours", storeHoursHandler.AddSpecialHours)
+			manage.DELETE("/:id/special-hours/:special_id", storeHoursHandler.DeleteSpecialHours)
+			manage.POST("/:id/pause", storeHoursHandler.PauseStore)
+			manage.POST("/:id/resume", storeHoursHandler.ResumeStore)
+		}
+	}
+}
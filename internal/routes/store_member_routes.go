@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/middleware"
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// SetupStoreMemberRoutes configures store membership and invitation routes
+func SetupStoreMemberRoutes(v1 *gin.RouterGroup, memberHandler *handlers.StoreMemberHandler) {
+	stores := v1.Group("/stores")
+	stores.Use(middleware.AuthRequired(), middleware.RoleRequired(models.RoleSuperUser, models.RoleStore))
+	{
+		// Members (store members or superuser; changes need the manage members permission)
+		stores.GET("/:id/members", memberHandler.ListMembers)
+		stores.PUT("/:id/members/:user_id", memberHandler.UpdateMember)
+		stores.DELETE("/:id/members/:user_id", memberHandler.RemoveMember)
+
+		// Invitations (store owners and managers, or superuser)
+		stores.POST("/:id/invitations", memberHandler.InviteMember)
+		stores.GET("/:id/invitations", memberHandler.ListInvitations)
+		stores.DELETE("/:id/invitations/:invitation_id", memberHandler.RevokeInvitation)
+	}
+
+	// Invitations are accepted by any authenticated user the invitation was sent to
+	invitations := v1.Group("/store-invitations")
+	invitations.Use(middleware.AuthRequired())
+	{
+		invitations.POST("/accept", memberHandler.AcceptInvitation)
+	}
+
+	memberships := v1.Group("/store-memberships")
+	memberships.Use(middleware.AuthRequired())
+	{
+		memberships.GET("", memberHandler.ListMyMemberships)
+	}
+}
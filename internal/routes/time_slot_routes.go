@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ruranjo/unientrega/internal/handlers"
+	"github.com/ruranjo/unientrega/internal/middleware"
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// SetupTimeSlotRoutes configures scheduled order time slot routes
+func SetupTimeSlotRoutes(v1 *gin.RouterGroup, timeSlotHandler *handlers.TimeSlotHandler) {
+	stores := v1.Group("/stores")
+	stores.Use(middleware.AuthRequired())
+	{
+		// Slot availability and settings (all authenticated users can view)
+		stores.GET("/:id/slots", timeSlotHandler.ListSlots)
+		stores.GET("/:id/slot-settings", timeSlotHandler.GetSettings)
+
+		// Slot settings (store owners and managers, or superuser)
+		stores.PUT("/:id/slot-settings", middleware.RoleRequired(models.RoleSuperUser, models.RoleStore), timeSlotHandler.SetSettings)
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// AddressService handles business logic for the saved delivery addresses of users
+type AddressService struct {
+	txManager    *repository.TxManager
+	addressRepo  *repository.DeliveryAddressRepository
+	locationRepo *repository.LocationRepository
+}
+
+// NewAddressService creates a new address service
+func NewAddressService(txManager *repository.TxManager, addressRepo *repository.DeliveryAddressRepository, locationRepo *repository.LocationRepository) *AddressService {
+	return &AddressService{
+		txManager:    txManager,
+		addressRepo:  addressRepo,
+		locationRepo: locationRepo,
+	}
+}
+
+// AddressRequest represents the data to create or update a saved address
+type AddressRequest struct {
+	LocationID   uuid.UUID `json:"location_id" binding:"required"`
+	Label        string    `json:"label" binding:"required"`
+	Instructions string    `json:"instructions"`
+	IsDefault    bool      `json:"is_default"`
+}
+
+// CreateAddress saves a new delivery address for a user
+func (s *AddressService) CreateAddress(userID uuid.UUID, req *AddressRequest) (*models.DeliveryAddress, error) {
+	if err := s.validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	address := &models.DeliveryAddress{
+		UserID:       userID,
+		LocationID:   req.LocationID,
+		Label:        strings.TrimSpace(req.Label),
+		Instructions: req.Instructions,
+		IsDefault:    req.IsDefault,
+	}
+
+	err := s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		addressRepo := s.addressRepo.WithTx(tx)
+		if address.IsDefault {
+			if err := addressRepo.ClearDefault(userID); err != nil {
+				return err
+			}
+		}
+		return addressRepo.Create(address)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.addressRepo.GetByID(address.ID)
+}
+
+// ListAddresses returns the saved addresses of a user
+func (s *AddressService) ListAddresses(userID uuid.UUID) ([]*models.DeliveryAddress, error) {
+	return s.addressRepo.ListByUser(userID)
+}
+
+// GetAddress returns a saved address owned by the user
+func (s *AddressService) GetAddress(id, userID uuid.UUID) (*models.DeliveryAddress, error) {
+	address, err := s.addressRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if address.UserID != userID {
+		return nil, errors.New("permission denied")
+	}
+	return address, nil
+}
+
+// UpdateAddress updates a saved address owned by the user
+func (s *AddressService) UpdateAddress(id, userID uuid.UUID, req *AddressRequest) (*models.DeliveryAddress, error) {
+	address, err := s.GetAddress(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	address.LocationID = req.LocationID
+	address.Label = strings.TrimSpace(req.Label)
+	address.Instructions = req.Instructions
+	address.IsDefault = req.IsDefault
+
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		addressRepo := s.addressRepo.WithTx(tx)
+		if address.IsDefault {
+			if err := addressRepo.ClearDefault(userID); err != nil {
+				return err
+			}
+		}
+		return addressRepo.Update(address)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.addressRepo.GetByID(address.ID)
+}
+
+// DeleteAddress removes a saved address owned by the user
+func (s *AddressService) DeleteAddress(id, userID uuid.UUID) error {
+	if _, err := s.GetAddress(id, userID); err != nil {
+		return err
+	}
+	return s.addressRepo.Delete(id)
+}
+
+// validateRequest checks the label and that the location exists and is active
+func (s *AddressService) validateRequest(req *AddressRequest) error {
+	if strings.TrimSpace(req.Label) == "" {
+		return errors.New("label is required")
+	}
+	location, err := s.locationRepo.GetByID(req.LocationID)
+	if err != nil {
+		return err
+	}
+	if !location.IsActive {
+		return errors.New("location is not active")
+	}
+	return nil
+}
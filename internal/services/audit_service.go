@@ -0,0 +1,27 @@
+package services
+
+import (
+	"log"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// AuditService records security relevant actions in the audit log
+type AuditService struct {
+	auditRepo *repository.AuditLogRepository
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditRepo *repository.AuditLogRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record stores an audit log entry. Failures are logged and never fail the audited request.
+func (s *AuditService) Record(entry *models.AuditLog) {
+	if err := s.auditRepo.Create(entry); err != nil {
+		log.Printf("Failed to record audit entry %s by %s: %v", entry.Action, entry.ActorID, err)
+	}
+}
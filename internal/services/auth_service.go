@@ -2,24 +2,49 @@ package services
 
 import (
 	"errors"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+
 	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
 	"github.com/ruranjo/unientrega/internal/utils"
 )
 
-// AuthService handles authentication business logic
+// ErrRefreshTokenReused is returned when a refresh token is used a second time. The token
+// was copied, so the session it belongs to is revoked for the thief and the owner alike.
+var ErrRefreshTokenReused = errors.New("refresh token was already used, the session has been revoked")
+
+// AuthService handles authentication business logic.
+// Every sign in starts a session whose refresh token is replaced on each refresh.
 type AuthService struct {
+	txManager   *repository.TxManager
 	userService *UserService
+	sessionRepo *repository.SessionRepository
+	refreshTTL  time.Duration
+
+	stop chan struct{}
 }
 
-// NewAuthService creates a new auth service
-func NewAuthService(userService *UserService) *AuthService {
+// NewAuthService creates a new auth service; sessions end after refreshTTL without a refresh
+func NewAuthService(txManager *repository.TxManager, userService *UserService, sessionRepo *repository.SessionRepository, refreshTTL time.Duration) *AuthService {
 	return &AuthService{
+		txManager:   txManager,
 		userService: userService,
+		sessionRepo: sessionRepo,
+		refreshTTL:  refreshTTL,
 	}
 }
 
+// DeviceInfo identifies the device a session is used from
+type DeviceInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
 // RegisterRequest represents registration data
 type RegisterRequest struct {
 	Email     string      `json:"email" binding:"required,email"`
@@ -42,8 +67,14 @@ type AuthResponse struct {
 	User         *models.User `json:"user"`
 }
 
-// Register creates a new user account
-func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
+// TokenPair is the access token and the next refresh token of a session
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Register creates a new user account and signs it in on the device
+func (s *AuthService) Register(req *RegisterRequest, device DeviceInfo) (*AuthResponse, error) {
 	// Create user
 	user := &models.User{
 		Email:     req.Email,
@@ -63,78 +94,267 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		return nil, err
 	}
 
-	// Generate tokens
-	accessToken, err := utils.GenerateToken(user.ID, user.Email, user.Role)
-	if err != nil {
-		return nil, err
-	}
-
-	refreshToken, err := utils.GenerateRefreshToken(user.ID)
+	// Start a session and generate its tokens
+	tokens, err := s.startSession(user, device)
 	if err != nil {
 		return nil, err
 	}
 
 	return &AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 		User:         user,
 	}, nil
 }
 
-// Login authenticates a user and returns tokens
-func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
+// Login authenticates a user and returns the tokens of a new session on the device
+func (s *AuthService) Login(req *LoginRequest, device DeviceInfo) (*AuthResponse, error) {
 	// Authenticate user
 	user, err := s.userService.AuthenticateUser(req.Email, req.Password)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate tokens
-	accessToken, err := utils.GenerateToken(user.ID, user.Email, user.Role)
-	if err != nil {
-		return nil, err
-	}
-
-	refreshToken, err := utils.GenerateRefreshToken(user.ID)
+	// Start a session and generate its tokens
+	tokens, err := s.startSession(user, device)
 	if err != nil {
 		return nil, err
 	}
 
 	return &AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 		User:         user,
 	}, nil
 }
 
-// RefreshToken generates a new access token from a refresh token
-func (s *AuthService) RefreshToken(refreshToken string) (string, error) {
-	// Validate refresh token
-	userID, err := utils.ValidateRefreshToken(refreshToken)
+// RefreshToken exchanges a refresh token for a new access token and refresh token.
+// The refresh token is used up; presenting it again revokes its session.
+func (s *AuthService) RefreshToken(refreshToken string, device DeviceInfo) (*TokenPair, error) {
+	stored, err := s.sessionRepo.GetRefreshTokenByHash(utils.HashToken(refreshToken))
 	if err != nil {
-		return "", errors.New("invalid refresh token")
+		return nil, errors.New("invalid refresh token")
+	}
+	if !stored.Session.IsActive() {
+		return nil, errors.New("session has expired or been revoked")
+	}
+	if stored.UsedAt != nil {
+		return nil, s.revokeReused(stored.Session)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, errors.New("refresh token has expired")
 	}
 
 	// Get user
-	user, err := s.userService.GetUserByID(userID)
+	user, err := s.userService.GetUserByID(stored.Session.UserID)
 	if err != nil {
-		return "", errors.New("user not found")
+		return nil, errors.New("user not found")
 	}
 
 	if !user.IsActive {
-		return "", errors.New("user account is inactive")
+		return nil, errors.New("user account is inactive")
+	}
+
+	var tokens *TokenPair
+	reused := false
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		sessionRepo := s.sessionRepo.WithTx(tx)
+		now := time.Now()
+
+		// Only one refresh can use the token, a concurrent one is a reuse
+		ok, err := sessionRepo.MarkRefreshTokenUsed(stored.ID, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			reused = true
+			return nil
+		}
+
+		expiresAt := now.Add(s.refreshTTL)
+		active, err := sessionRepo.Touch(stored.SessionID, device.userAgent(), device.IPAddress, now, expiresAt)
+		if err != nil {
+			return err
+		}
+		if !active {
+			return errors.New("session has expired or been revoked")
+		}
+		tokens, err = s.issueTokens(sessionRepo, user, stored.SessionID, expiresAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, s.revokeReused(stored.Session)
+	}
+
+	return tokens, nil
+}
+
+// Logout ends the session of the user that the access token was issued to
+func (s *AuthService) Logout(userID, sessionID uuid.UUID) error {
+	// Tokens issued before sessions existed have no session to end
+	if sessionID == uuid.Nil {
+		return nil
+	}
+	_, err := s.sessionRepo.Revoke(sessionID, userID, models.SessionLogout)
+	return err
+}
+
+// IsSessionActive checks that a session of the user is neither revoked nor expired, so that
+// access tokens stop working as soon as their session ends
+func (s *AuthService) IsSessionActive(userID, sessionID uuid.UUID) (bool, error) {
+	// Tokens issued before sessions existed have no session
+	if sessionID == uuid.Nil {
+		return false, nil
+	}
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return session.UserID == userID && session.IsActive(), nil
+}
+
+// SessionInfo is an active session of a user as listed to them
+type SessionInfo struct {
+	models.UserSession
+	Current bool `json:"current"` // The session of the access token used for the request
+}
+
+// ListSessions lists the active sessions of a user, marking the current one
+func (s *AuthService) ListSessions(userID, currentSessionID uuid.UUID) ([]SessionInfo, error) {
+	sessions, err := s.sessionRepo.ListActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]SessionInfo, len(sessions))
+	for i, session := range sessions {
+		infos[i] = SessionInfo{
+			UserSession: session,
+			Current:     currentSessionID != uuid.Nil && session.ID == currentSessionID,
+		}
+	}
+	return infos, nil
+}
+
+// RevokeSession ends an active session of a user
+func (s *AuthService) RevokeSession(userID, sessionID uuid.UUID, reason models.SessionRevocation) error {
+	revoked, err := s.sessionRepo.Revoke(sessionID, userID, reason)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.New("session not found")
+	}
+	return nil
+}
+
+// RevokeAllSessions ends every active session of a user, signing them out on all devices.
+// It returns the number of sessions revoked.
+func (s *AuthService) RevokeAllSessions(userID uuid.UUID, reason models.SessionRevocation) (int64, error) {
+	return s.sessionRepo.RevokeByUser(userID, uuid.Nil, reason)
+}
+
+// startSession signs a user in on a device
+func (s *AuthService) startSession(user *models.User, device DeviceInfo) (*TokenPair, error) {
+	var tokens *TokenPair
+	err := s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		sessionRepo := s.sessionRepo.WithTx(tx)
+		now := time.Now()
+
+		session := &models.UserSession{
+			UserID:     user.ID,
+			UserAgent:  device.userAgent(),
+			IPAddress:  device.IPAddress,
+			LastUsedAt: now,
+			ExpiresAt:  now.Add(s.refreshTTL),
+		}
+		if err := sessionRepo.Create(session); err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = s.issueTokens(sessionRepo, user, session.ID, session.ExpiresAt)
+		return err
+	})
+	return tokens, err
+}
+
+// issueTokens generates an access token and stores a new refresh token for a session
+func (s *AuthService) issueTokens(sessionRepo *repository.SessionRepository, user *models.User, sessionID uuid.UUID, expiresAt time.Time) (*TokenPair, error) {
+	accessToken, err := utils.GenerateToken(user.ID, user.Email, user.Role, sessionID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate new access token
-	accessToken, err := utils.GenerateToken(user.ID, user.Email, user.Role)
+	refreshToken, err := utils.GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	err = sessionRepo.CreateRefreshToken(&models.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: expiresAt,
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return accessToken, nil
+	return &TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// revokeReused revokes the session of a refresh token that was used twice
+func (s *AuthService) revokeReused(session models.UserSession) error {
+	if _, err := s.sessionRepo.Revoke(session.ID, session.UserID, models.SessionTokenReuse); err != nil {
+		return err
+	}
+	log.Printf("auth: refresh token reused, revoked session %s of user %s", session.ID, session.UserID)
+	return ErrRefreshTokenReused
 }
 
 // GetUserByID retrieves a user by ID
 func (s *AuthService) GetUserByID(userID uuid.UUID) (*models.User, error) {
 	return s.userService.GetUserByID(userID)
 }
+
+// Start launches the background loop that removes expired and revoked sessions
+func (s *AuthService) Start(interval time.Duration) {
+	s.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.sessionRepo.DeleteExpired(time.Now()); err != nil {
+					log.Printf("auth: failed to delete expired sessions: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background loop
+func (s *AuthService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+	}
+}
+
+// maxUserAgentLength is the size of the user agent column of sessions
+const maxUserAgentLength = 500
+
+// userAgent returns the user agent of the device, cut to fit the session column
+func (d DeviceInfo) userAgent() string {
+	if len(d.UserAgent) <= maxUserAgentLength {
+		return d.UserAgent
+	}
+	return strings.ToValidUTF8(d.UserAgent[:maxUserAgentLength], "")
+}
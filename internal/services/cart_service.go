@@ -0,0 +1,500 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// ErrCartChanged is returned at checkout when the cart no longer matches what the client last saw
+var ErrCartChanged = errors.New("cart prices changed since it was last viewed")
+
+// Cart line issues, reported when a cart line cannot be ordered as is
+const (
+	CartIssueUnavailable       = "product_unavailable"
+	CartIssueConfiguration     = "configuration_unavailable" // The variant or a modifier can no longer be chosen
+	CartIssueInsufficientStock = "insufficient_stock"
+)
+
+// CartService handles the server-side shopping carts of users
+type CartService struct {
+	cartRepo     *repository.CartRepository
+	productRepo  *repository.ProductRepository
+	storeRepo    *repository.StoreRepository
+	orderService *OrderService
+	ttl          time.Duration
+}
+
+// NewCartService creates a new cart service; carts expire after ttl without changes
+func NewCartService(
+	cartRepo *repository.CartRepository,
+	productRepo *repository.ProductRepository,
+	storeRepo *repository.StoreRepository,
+	orderService *OrderService,
+	ttl time.Duration,
+) *CartService {
+	return &CartService{
+		cartRepo:     cartRepo,
+		productRepo:  productRepo,
+		storeRepo:    storeRepo,
+		orderService: orderService,
+		ttl:          ttl,
+	}
+}
+
+// CartItemRequest represents a configured product and quantity to put in a cart
+type CartItemRequest struct {
+	ProductID   uuid.UUID   `json:"product_id" binding:"required"`
+	VariantID   *uuid.UUID  `json:"variant_id"`   // Required for products with variants
+	ModifierIDs []uuid.UUID `json:"modifier_ids"` // Chosen modifiers of the product option groups
+	Quantity    int         `json:"quantity" binding:"required,min=1"`
+}
+
+// UpdateCartItemRequest represents a new quantity for a cart item; 0 removes the item
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity" binding:"min=0"`
+}
+
+// CheckoutRequest represents the request to turn a cart into an order
+type CheckoutRequest struct {
+	// Destination: exactly one of the following must be set
+	PickupAtStore      bool       `json:"pickup_at_store"`
+	DeliveryAddressID  *uuid.UUID `json:"delivery_address_id"`
+	DeliveryLocationID *uuid.UUID `json:"delivery_location_id"`
+
+	DeliveryInstructions string `json:"delivery_instructions"`
+
+	// Optional start of a 15-minute time slot; as soon as possible when empty
+	ScheduledFor *time.Time `json:"scheduled_for"`
+
+	// Optional subtotal in minor units the client showed to the user; checkout fails if prices changed
+	ExpectedSubtotal *int64 `json:"expected_subtotal"`
+}
+
+// CartLine is a cart item validated against the current product configuration, price and stock
+type CartLine struct {
+	ItemID        uuid.UUID    `json:"item_id"`
+	ProductID     uuid.UUID    `json:"product_id"`
+	Name          string       `json:"name"`
+	VariantID     *uuid.UUID   `json:"variant_id,omitempty"`
+	VariantName   string       `json:"variant_name,omitempty"`
+	ModifierIDs   []uuid.UUID  `json:"modifier_ids"`
+	ModifierNames []string     `json:"modifier_names,omitempty"`
+	Quantity      int          `json:"quantity"`
+	UnitPrice     models.Money `json:"unit_price"`  // Current price of the configuration
+	AddedPrice    models.Money `json:"added_price"` // Price when the item was added
+	PriceChanged  bool         `json:"price_changed"`
+	LineTotal     models.Money `json:"line_total"`
+	Available     int          `json:"available"` // Current stock of the product or variant
+	Issue         string       `json:"issue,omitempty"`
+}
+
+// CartView is a cart with live prices and stock
+type CartView struct {
+	ID        *uuid.UUID   `json:"id,omitempty"` // Nil while the cart is empty and was never saved
+	StoreID   uuid.UUID    `json:"store_id"`
+	Items     []CartLine   `json:"items"`
+	Subtotal  models.Money `json:"subtotal"` // Sum of the lines without issues
+	Valid     bool         `json:"valid"`    // True when the cart has items and all of them can be ordered
+	ExpiresAt *time.Time   `json:"expires_at,omitempty"`
+}
+
+// ListCarts returns the active carts of a user
+func (s *CartService) ListCarts(userID uuid.UUID) ([]*CartView, error) {
+	carts, err := s.cartRepo.ListByUser(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]*CartView, 0, len(carts))
+	for i := range carts {
+		view, err := s.buildView(&carts[i])
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// GetCart returns the cart of a user at a store, empty if there is none
+func (s *CartService) GetCart(userID, storeID uuid.UUID) (*CartView, error) {
+	cart, err := s.findCart(userID, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return emptyCartView(storeID), nil
+	}
+	return s.buildView(cart)
+}
+
+// AddItem adds a quantity of a configured product to the cart of a user at the product's store.
+// The quantity is added to the item with the same product, variant and modifiers.
+func (s *CartService) AddItem(userID, storeID uuid.UUID, req *CartItemRequest) (*CartView, error) {
+	if req.Quantity < 1 {
+		return nil, errors.New("quantity must be at least 1")
+	}
+
+	store, err := s.storeRepo.GetByID(storeID)
+	if err != nil {
+		return nil, errors.New("store not found")
+	}
+	if !store.IsActive {
+		return nil, errors.New("store is not active")
+	}
+
+	cart, err := s.findCart(userID, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		if cart, err = s.cartRepo.GetOrCreate(userID, storeID, time.Now().Add(s.ttl)); err != nil {
+			return nil, err
+		}
+	}
+
+	line := OrderItemRequest{
+		ProductID:   req.ProductID,
+		VariantID:   req.VariantID,
+		ModifierIDs: req.ModifierIDs,
+		Quantity:    req.Quantity,
+	}
+	if item := cartItemWithKey(cart, configurationKey(line)); item != nil {
+		line.Quantity += item.Quantity
+	}
+
+	return s.saveItem(cart, line)
+}
+
+// UpdateItem sets the quantity of a cart item; a quantity of 0 removes it
+func (s *CartService) UpdateItem(userID, storeID, itemID uuid.UUID, req *UpdateCartItemRequest) (*CartView, error) {
+	if req.Quantity < 0 {
+		return nil, errors.New("quantity must be non-negative")
+	}
+	if req.Quantity == 0 {
+		return s.RemoveItem(userID, storeID, itemID)
+	}
+
+	cart, err := s.findCart(userID, storeID)
+	if err != nil {
+		return nil, err
+	}
+	var item *models.CartItem
+	if cart != nil {
+		item = cartItem(cart, itemID)
+	}
+	if item == nil {
+		return nil, errors.New("product not in cart")
+	}
+
+	line := orderItemOf(item)
+	line.Quantity = req.Quantity
+	return s.saveItem(cart, line)
+}
+
+// RemoveItem removes an item from the cart
+func (s *CartService) RemoveItem(userID, storeID, itemID uuid.UUID) (*CartView, error) {
+	cart, err := s.findCart(userID, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return nil, errors.New("product not in cart")
+	}
+
+	removed, err := s.cartRepo.DeleteItem(cart.ID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, errors.New("product not in cart")
+	}
+	if err := s.cartRepo.Touch(cart.ID, time.Now().Add(s.ttl)); err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(userID, storeID)
+}
+
+// ClearCart removes the cart of a user at a store
+func (s *CartService) ClearCart(userID, storeID uuid.UUID) error {
+	cart, err := s.findCart(userID, storeID)
+	if err != nil || cart == nil {
+		return err
+	}
+	return s.cartRepo.Delete(cart.ID)
+}
+
+// Checkout places an order with the contents of the cart and empties the cart.
+// Prices, stock and the destination are validated again by the order service.
+func (s *CartService) Checkout(userID uuid.UUID, role models.Role, storeID uuid.UUID, req *CheckoutRequest) (*models.Order, error) {
+	cart, err := s.findCart(userID, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil || len(cart.Items) == 0 {
+		return nil, errors.New("cart is empty")
+	}
+
+	if req.ExpectedSubtotal != nil {
+		view, err := s.buildView(cart)
+		if err != nil {
+			return nil, err
+		}
+		if view.Subtotal.Amount != *req.ExpectedSubtotal {
+			return nil, ErrCartChanged
+		}
+	}
+
+	orderReq := &CreateOrderRequest{
+		StoreID:              storeID,
+		Items:                make([]OrderItemRequest, 0, len(cart.Items)),
+		PickupAtStore:        req.PickupAtStore,
+		DeliveryAddressID:    req.DeliveryAddressID,
+		DeliveryLocationID:   req.DeliveryLocationID,
+		DeliveryInstructions: req.DeliveryInstructions,
+		ScheduledFor:         req.ScheduledFor,
+	}
+	for i := range cart.Items {
+		orderReq.Items = append(orderReq.Items, orderItemOf(&cart.Items[i]))
+	}
+
+	order, err := s.orderService.CreateOrder(userID, role, orderReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cartRepo.Delete(cart.ID); err != nil {
+		log.Printf("cart: failed to empty cart %s after checkout: %v", cart.ID, err)
+	}
+	return order, nil
+}
+
+// findCart returns the cart of a user at a store, or nil if there is none.
+// Expired carts are removed.
+func (s *CartService) findCart(userID, storeID uuid.UUID) (*models.Cart, error) {
+	cart, err := s.cartRepo.GetByUserAndStore(userID, storeID)
+	if err != nil {
+		if err.Error() == "cart not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if time.Now().After(cart.ExpiresAt) {
+		if err := s.cartRepo.Delete(cart.ID); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return cart, nil
+}
+
+// saveItem configures a product against the cart store and checks its stock, counting the
+// other items sold from the same product or variant stock, then stores the item quantity
+func (s *CartService) saveItem(cart *models.Cart, line OrderItemRequest) (*CartView, error) {
+	product, err := s.productRepo.GetByID(line.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if product.StoreID != cart.StoreID {
+		return nil, errors.New("product does not belong to the store: " + product.Name)
+	}
+	if !product.IsActive {
+		return nil, errors.New("product is not active: " + product.Name)
+	}
+	configured, err := configureItem(product, productVariants(product), product.OptionGroups, line)
+	if err != nil {
+		return nil, err
+	}
+
+	key := configurationKey(line)
+	source := stockSource(product.ID, configured.VariantID)
+	requested := line.Quantity
+	for _, item := range cart.Items {
+		if item.ConfigurationKey != key && stockSource(item.ProductID, item.VariantID) == source {
+			requested += item.Quantity
+		}
+	}
+	if available, variant := stockOf(product, configured.VariantID); available < requested {
+		shortage := StockShortage{
+			ProductID: product.ID,
+			Name:      product.Name,
+			Requested: requested,
+			Available: available,
+		}
+		if variant != nil {
+			shortage = variantShortage(product, variant, requested)
+		}
+		return nil, &InsufficientStockError{Items: []StockShortage{shortage}}
+	}
+
+	err = s.cartRepo.SaveItem(&models.CartItem{
+		CartID:           cart.ID,
+		ProductID:        product.ID,
+		VariantID:        configured.VariantID,
+		ModifierIDs:      append([]uuid.UUID{}, line.ModifierIDs...),
+		ConfigurationKey: key,
+		Quantity:         line.Quantity,
+		AddedPrice:       configured.Price,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cartRepo.Touch(cart.ID, time.Now().Add(s.ttl)); err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(cart.UserID, cart.StoreID)
+}
+
+// buildView validates the items of a cart against the current products and their options
+func (s *CartService) buildView(cart *models.Cart) (*CartView, error) {
+	view := emptyCartView(cart.StoreID)
+	view.ID = &cart.ID
+	view.ExpiresAt = &cart.ExpiresAt
+	view.Items = make([]CartLine, 0, len(cart.Items))
+
+	productIDs := make([]uuid.UUID, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	productsByID := make(map[uuid.UUID]*models.Product, len(productIDs))
+	if len(productIDs) > 0 {
+		products, err := s.productRepo.GetByIDsWithOptions(productIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range products {
+			productsByID[product.ID] = product
+		}
+	}
+
+	// Items of the same product or variant are sold from the same stock
+	requested := make(map[uuid.UUID]int, len(cart.Items))
+	for _, item := range cart.Items {
+		requested[stockSource(item.ProductID, item.VariantID)] += item.Quantity
+	}
+
+	view.Valid = len(cart.Items) > 0
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		line := CartLine{
+			ItemID:      item.ID,
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			ModifierIDs: item.ModifierIDs,
+			Quantity:    item.Quantity,
+			AddedPrice:  item.AddedPrice,
+			UnitPrice:   item.AddedPrice,
+		}
+
+		product, ok := productsByID[item.ProductID]
+		if ok {
+			line.Name = product.Name
+		}
+		if !ok || !product.IsActive || product.StoreID != cart.StoreID {
+			line.Issue = CartIssueUnavailable
+		} else if configured, err := configureItem(product, productVariants(product), product.OptionGroups, orderItemOf(item)); err != nil {
+			line.Issue = CartIssueConfiguration
+		} else {
+			line.VariantName = configured.VariantName
+			for _, modifier := range configured.Modifiers {
+				line.ModifierNames = append(line.ModifierNames, modifier.Name)
+			}
+			line.UnitPrice = configured.Price
+			line.PriceChanged = configured.Price != item.AddedPrice
+			line.Available, _ = stockOf(product, item.VariantID)
+			if line.Available < requested[stockSource(item.ProductID, item.VariantID)] {
+				line.Issue = CartIssueInsufficientStock
+			}
+		}
+		line.LineTotal = line.UnitPrice.Multiply(item.Quantity)
+
+		if line.Issue != "" {
+			view.Valid = false
+		} else {
+			if view.Subtotal.IsZero() {
+				view.Subtotal.Currency = line.LineTotal.Currency
+			}
+			view.Subtotal = view.Subtotal.Add(line.LineTotal)
+		}
+		view.Items = append(view.Items, line)
+	}
+
+	return view, nil
+}
+
+// emptyCartView returns the view of a store cart without items
+func emptyCartView(storeID uuid.UUID) *CartView {
+	return &CartView{
+		StoreID:  storeID,
+		Items:    []CartLine{},
+		Subtotal: models.NewMoney(0, ""),
+	}
+}
+
+// cartItem returns the item of a cart with the given ID, or nil
+func cartItem(cart *models.Cart, itemID uuid.UUID) *models.CartItem {
+	for i := range cart.Items {
+		if cart.Items[i].ID == itemID {
+			return &cart.Items[i]
+		}
+	}
+	return nil
+}
+
+// cartItemWithKey returns the item of a cart with the given configuration, or nil
+func cartItemWithKey(cart *models.Cart, key string) *models.CartItem {
+	for i := range cart.Items {
+		if cart.Items[i].ConfigurationKey == key {
+			return &cart.Items[i]
+		}
+	}
+	return nil
+}
+
+// orderItemOf returns the order line of a cart item
+func orderItemOf(item *models.CartItem) OrderItemRequest {
+	return OrderItemRequest{
+		ProductID:   item.ProductID,
+		VariantID:   item.VariantID,
+		ModifierIDs: item.ModifierIDs,
+		Quantity:    item.Quantity,
+	}
+}
+
+// productVariants returns the variants of a product as configureItem expects them
+func productVariants(product *models.Product) []*models.ProductVariant {
+	variants := make([]*models.ProductVariant, 0, len(product.Variants))
+	for i := range product.Variants {
+		variants = append(variants, &product.Variants[i])
+	}
+	return variants
+}
+
+// stockSource identifies the stock a configuration is sold from: its variant, or its product
+func stockSource(productID uuid.UUID, variantID *uuid.UUID) uuid.UUID {
+	if variantID != nil {
+		return *variantID
+	}
+	return productID
+}
+
+// stockOf returns the stock a configuration is sold from, with its variant if it has one
+func stockOf(product *models.Product, variantID *uuid.UUID) (int, *models.ProductVariant) {
+	if variantID == nil {
+		return product.Stock, nil
+	}
+	for i := range product.Variants {
+		if product.Variants[i].ID == *variantID {
+			return product.Variants[i].Stock, &product.Variants[i]
+		}
+	}
+	return 0, nil
+}
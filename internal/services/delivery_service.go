@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// ErrOrderAlreadyClaimed is returned when another courier claimed the order first
+var ErrOrderAlreadyClaimed = errors.New("order has already been claimed by another courier")
+
+// ErrOrderNotReady is returned when an order is not waiting for a courier
+var ErrOrderNotReady = errors.New("order is not ready for delivery")
+
+// Delivery list scopes
+const (
+	DeliveryScopeActive = "active"
+	DeliveryScopePast   = "past"
+)
+
+// DeliveryAssignmentListener is notified after a courier claimed or released an order by hand
+type DeliveryAssignmentListener interface {
+	OrderClaimed(order *models.Order)
+	OrderReleased(order *models.Order, courierID uuid.UUID)
+}
+
+// DeliveryService handles the courier workflow for orders
+type DeliveryService struct {
+	txManager    *repository.TxManager
+	orderRepo    *repository.OrderRepository
+	orderService *OrderService
+	listeners    []DeliveryAssignmentListener
+}
+
+// NewDeliveryService creates a new delivery service
+func NewDeliveryService(txManager *repository.TxManager, orderRepo *repository.OrderRepository, orderService *OrderService) *DeliveryService {
+	return &DeliveryService{
+		txManager:    txManager,
+		orderRepo:    orderRepo,
+		orderService: orderService,
+	}
+}
+
+// AddAssignmentListener registers a listener that is notified of every claim and release
+func (s *DeliveryService) AddAssignmentListener(listener DeliveryAssignmentListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// ListAvailable lists a page of the ready orders that still need a courier
+func (s *DeliveryService) ListAvailable(limit, offset int, cursor string) ([]models.Order, string, int64, error) {
+	page, err := newPage(limit, offset, cursor, 10, 100)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return s.orderRepo.ListAwaitingDelivery(page)
+}
+
+// ListCourierDeliveries lists the deliveries of a courier.
+// The active scope returns claimed and in-transit orders, the past scope finished ones.
+func (s *DeliveryService) ListCourierDeliveries(courierID uuid.UUID, scope string, limit, offset int, cursor string) ([]models.Order, string, int64, error) {
+	var statuses []models.OrderStatus
+	switch scope {
+	case DeliveryScopeActive:
+		statuses = []models.OrderStatus{models.OrderStatusReady, models.OrderStatusInDelivery}
+	case DeliveryScopePast:
+		statuses = []models.OrderStatus{models.OrderStatusCompleted, models.OrderStatusCancelled}
+	default:
+		return nil, "", 0, errors.New("invalid scope")
+	}
+	page, err := newPage(limit, offset, cursor, 10, 100)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return s.orderRepo.ListByDeliveryPerson(courierID, statuses, page)
+}
+
+// ClaimOrder assigns a ready order to the courier.
+// The assignment is a single conditional update, so two couriers can never claim the same order.
+func (s *DeliveryService) ClaimOrder(orderID, courierID uuid.UUID) (*models.Order, error) {
+	ok, err := s.orderRepo.AssignDeliveryPerson(orderID, courierID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, errors.New("order not found")
+	}
+
+	if !ok {
+		if order.PickupAtStore {
+			return nil, errors.New("order is picked up at the store")
+		}
+		if order.Status != models.OrderStatusReady {
+			return nil, ErrOrderNotReady
+		}
+		return nil, ErrOrderAlreadyClaimed
+	}
+
+	for _, listener := range s.listeners {
+		listener.OrderClaimed(order)
+	}
+	return order, nil
+}
+
+// ReleaseOrder gives a claimed order back before it was picked up
+func (s *DeliveryService) ReleaseOrder(orderID, courierID uuid.UUID) (*models.Order, error) {
+	order, err := s.getAssignedOrder(orderID, courierID)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.orderRepo.UnassignDeliveryPerson(orderID, courierID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("order can no longer be released")
+	}
+
+	order.DeliveryPersonID = nil
+	for _, listener := range s.listeners {
+		listener.OrderReleased(order, courierID)
+	}
+	return order, nil
+}
+
+// MarkPickedUp records that the courier collected the order from the store
+func (s *DeliveryService) MarkPickedUp(orderID, courierID uuid.UUID, role models.Role) (*models.Order, error) {
+	order, err := s.getAssignedOrder(orderID, courierID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := order.Status
+	now := time.Now()
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.orderService.changeStatus(tx, order, models.OrderStatusInDelivery, courierID, role, "picked up by courier"); err != nil {
+			return err
+		}
+		return s.orderRepo.WithTx(tx).SetPickedUpAt(order.ID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order.PickedUpAt = &now
+	s.orderService.notifyStatusChange(order, from)
+	return order, nil
+}
+
+// MarkDelivered records that the courier handed the order over to the customer
+func (s *DeliveryService) MarkDelivered(orderID, courierID uuid.UUID, role models.Role) (*models.Order, error) {
+	order, err := s.getAssignedOrder(orderID, courierID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := order.Status
+	now := time.Now()
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.orderService.changeStatus(tx, order, models.OrderStatusCompleted, courierID, role, "delivered by courier"); err != nil {
+			return err
+		}
+		return s.orderRepo.WithTx(tx).SetDeliveredAt(order.ID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order.DeliveredAt = &now
+	s.orderService.notifyStatusChange(order, from)
+	return order, nil
+}
+
+// getAssignedOrder loads an order and checks that it is assigned to the courier
+func (s *DeliveryService) getAssignedOrder(orderID, courierID uuid.UUID) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, errors.New("order not found")
+	}
+	if order.DeliveryPersonID == nil || *order.DeliveryPersonID != courierID {
+		return nil, errors.New("permission denied")
+	}
+	return order, nil
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// ErrOfferExpired is returned when a courier responds to an offer after its deadline
+var ErrOfferExpired = errors.New("offer has expired")
+
+// ErrOfferClosed is returned when a courier responds to an offer that is no longer open
+var ErrOfferClosed = errors.New("offer is no longer open")
+
+// DispatchService automatically offers ready orders to available couriers.
+// Every order is offered to one courier at a time; when the courier declines or does not
+// answer before the offer expires, the order is offered to the next courier. A courier is
+// offered the same order again once the re-offer cooldown has passed since their last offer.
+type DispatchService struct {
+	txManager       *repository.TxManager
+	courierRepo     *repository.CourierRepository
+	offerRepo       *repository.DeliveryOfferRepository
+	orderRepo       *repository.OrderRepository
+	storeRepo       *repository.StoreRepository
+	strategy        DispatchStrategy
+	offerTimeout    time.Duration
+	reofferCooldown time.Duration
+	sweepInterval   time.Duration
+
+	mu   sync.Mutex // Serializes dispatch decisions
+	stop chan struct{}
+}
+
+// NewDispatchService creates a new dispatch service
+func NewDispatchService(
+	txManager *repository.TxManager,
+	courierRepo *repository.CourierRepository,
+	offerRepo *repository.DeliveryOfferRepository,
+	orderRepo *repository.OrderRepository,
+	storeRepo *repository.StoreRepository,
+	strategy DispatchStrategy,
+	offerTimeout, reofferCooldown, sweepInterval time.Duration,
+) *DispatchService {
+	return &DispatchService{
+		txManager:       txManager,
+		courierRepo:     courierRepo,
+		offerRepo:       offerRepo,
+		orderRepo:       orderRepo,
+		storeRepo:       storeRepo,
+		strategy:        strategy,
+		offerTimeout:    offerTimeout,
+		reofferCooldown: reofferCooldown,
+		sweepInterval:   sweepInterval,
+	}
+}
+
+// SetAvailabilityRequest represents a courier availability update
+type SetAvailabilityRequest struct {
+	Status models.CourierStatus `json:"status" binding:"required"`
+	Zone   string               `json:"zone"`
+}
+
+// Start launches the background loop that expires unanswered offers and
+// dispatches orders still waiting for a courier
+func (s *DispatchService) Start() {
+	s.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.sweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.sweep()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	log.Printf("Courier dispatcher started (strategy: %s, offer timeout: %s)", s.strategy.Name(), s.offerTimeout)
+}
+
+// Stop stops the background loop
+func (s *DispatchService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+	}
+}
+
+// SetAvailability updates the availability of a courier
+func (s *DispatchService) SetAvailability(courierID uuid.UUID, req *SetAvailabilityRequest) (*models.CourierAvailability, error) {
+	if !req.Status.IsValid() {
+		return nil, errors.New("invalid courier status")
+	}
+
+	availability := &models.CourierAvailability{
+		UserID:     courierID,
+		Status:     req.Status,
+		Zone:       strings.TrimSpace(req.Zone),
+		LastSeenAt: time.Now(),
+	}
+	if err := s.courierRepo.Upsert(availability); err != nil {
+		return nil, err
+	}
+
+	// A courier coming online may pick up orders nobody could take so far
+	if req.Status == models.CourierStatusOnline {
+		go s.dispatchWaitingOrders()
+	}
+
+	return s.courierRepo.GetByUserID(courierID)
+}
+
+// GetAvailability returns the availability of a courier, offline if never set
+func (s *DispatchService) GetAvailability(courierID uuid.UUID) (*models.CourierAvailability, error) {
+	availability, err := s.courierRepo.GetByUserID(courierID)
+	if err != nil {
+		return &models.CourierAvailability{UserID: courierID, Status: models.CourierStatusOffline}, nil
+	}
+	return availability, nil
+}
+
+// ListOffers returns the open offers of a courier
+func (s *DispatchService) ListOffers(courierID uuid.UUID) ([]models.DeliveryOffer, error) {
+	return s.offerRepo.ListPendingByCourier(courierID)
+}
+
+// AcceptOffer assigns the offered order to the courier
+func (s *DispatchService) AcceptOffer(offerID, courierID uuid.UUID) (*models.Order, error) {
+	offer, err := s.getCourierOffer(offerID, courierID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if now.After(offer.ExpiresAt) {
+		s.expireOffer(offer)
+		return nil, ErrOfferExpired
+	}
+
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		ok, err := s.offerRepo.WithTx(tx).Resolve(offer.ID, models.DeliveryOfferAccepted, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrOfferClosed
+		}
+
+		// Writes Order.DeliveryPersonID unless another courier claimed the order meanwhile
+		assigned, err := s.orderRepo.WithTx(tx).AssignDeliveryPerson(offer.OrderID, courierID)
+		if err != nil {
+			return err
+		}
+		if !assigned {
+			return ErrOrderAlreadyClaimed
+		}
+
+		return s.courierRepo.WithTx(tx).UpdateStatus(courierID, models.CourierStatusOnline, models.CourierStatusBusy)
+	})
+	if err != nil {
+		if errors.Is(err, ErrOrderAlreadyClaimed) {
+			s.offerRepo.Resolve(offer.ID, models.DeliveryOfferWithdrawn, now)
+		}
+		return nil, err
+	}
+
+	return s.orderRepo.GetByID(offer.OrderID)
+}
+
+// DeclineOffer rejects an offer and passes the order on to the next courier
+func (s *DispatchService) DeclineOffer(offerID, courierID uuid.UUID) error {
+	offer, err := s.getCourierOffer(offerID, courierID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.offerRepo.Resolve(offer.ID, models.DeliveryOfferDeclined, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrOfferClosed
+	}
+
+	go s.dispatch(offer.OrderID)
+	return nil
+}
+
+// OrderStatusChanged implements OrderStatusListener
+func (s *DispatchService) OrderStatusChanged(order *models.Order, from models.OrderStatus) {
+	switch order.Status {
+	case models.OrderStatusReady:
+		go s.dispatch(order.ID)
+	case models.OrderStatusCompleted, models.OrderStatusCancelled:
+		if err := s.offerRepo.WithdrawPendingForOrder(order.ID); err != nil {
+			log.Printf("dispatch: failed to withdraw offers for order %s: %v", order.ID, err)
+		}
+		if order.DeliveryPersonID != nil {
+			s.releaseCourier(*order.DeliveryPersonID)
+		}
+	}
+}
+
+// OrderClaimed implements DeliveryAssignmentListener: open offers of an order claimed by hand
+// are withdrawn, and the courier is busy like after accepting an offer
+func (s *DispatchService) OrderClaimed(order *models.Order) {
+	if err := s.offerRepo.WithdrawPendingForOrder(order.ID); err != nil {
+		log.Printf("dispatch: failed to withdraw offers for order %s: %v", order.ID, err)
+	}
+	if order.DeliveryPersonID != nil {
+		if err := s.courierRepo.UpdateStatus(*order.DeliveryPersonID, models.CourierStatusOnline, models.CourierStatusBusy); err != nil {
+			log.Printf("dispatch: failed to mark courier %s busy: %v", *order.DeliveryPersonID, err)
+		}
+	}
+}
+
+// OrderReleased implements DeliveryAssignmentListener: the released order is offered again
+func (s *DispatchService) OrderReleased(order *models.Order, courierID uuid.UUID) {
+	s.releaseCourier(courierID)
+	go s.dispatch(order.ID)
+}
+
+// sweep expires overdue offers and dispatches orders still waiting for a courier
+func (s *DispatchService) sweep() {
+	expired, err := s.offerRepo.ListExpired(time.Now())
+	if err != nil {
+		log.Printf("dispatch: failed to list expired offers: %v", err)
+		return
+	}
+	for i := range expired {
+		s.expireOffer(&expired[i])
+	}
+
+	s.dispatchWaitingOrders()
+}
+
+// dispatchWaitingOrders offers every unassigned ready order to a courier
+func (s *DispatchService) dispatchWaitingOrders() {
+	orderIDs, err := s.orderRepo.ListAwaitingDeliveryIDs()
+	if err != nil {
+		log.Printf("dispatch: failed to list waiting orders: %v", err)
+		return
+	}
+	for _, orderID := range orderIDs {
+		s.dispatch(orderID)
+	}
+}
+
+// dispatch offers an order to the next courier chosen by the strategy.
+// Orders that are already assigned or have an open offer are left alone.
+func (s *DispatchService) dispatch(orderID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		log.Printf("dispatch: order %s not found: %v", orderID, err)
+		return
+	}
+	if order.Status != models.OrderStatusReady || order.DeliveryPersonID != nil {
+		return
+	}
+
+	pending, err := s.offerRepo.HasPendingForOrder(orderID)
+	if err != nil || pending {
+		return
+	}
+
+	candidates, err := s.candidatesFor(orderID)
+	if err != nil {
+		log.Printf("dispatch: failed to load couriers for order %s: %v", orderID, err)
+		return
+	}
+	if len(candidates) == 0 {
+		// Nobody left to ask; the order stays available for manual claiming, and later sweeps
+		// offer it again once a cooldown has passed
+		return
+	}
+
+	store, _ := s.storeRepo.GetByID(order.StoreID)
+	picked := s.strategy.Pick(order, store, candidates)
+
+	offer := &models.DeliveryOffer{
+		OrderID:   orderID,
+		CourierID: picked.CourierID,
+		Status:    models.DeliveryOfferPending,
+		Strategy:  s.strategy.Name(),
+		ExpiresAt: time.Now().Add(s.offerTimeout),
+	}
+	if err := s.offerRepo.Create(offer); err != nil {
+		log.Printf("dispatch: failed to offer order %s: %v", orderID, err)
+	}
+}
+
+// candidatesFor returns online couriers without an open offer that were not offered the order
+// within the re-offer cooldown
+func (s *DispatchService) candidatesFor(orderID uuid.UUID) ([]CourierCandidate, error) {
+	couriers, err := s.courierRepo.ListByStatus(models.CourierStatusOnline)
+	if err != nil || len(couriers) == 0 {
+		return nil, err
+	}
+
+	excluded := make(map[uuid.UUID]bool)
+	offered, err := s.offerRepo.ListOfferedCourierIDs(orderID, time.Now().Add(-s.reofferCooldown))
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range offered {
+		excluded[id] = true
+	}
+	busy, err := s.offerRepo.ListCouriersWithPendingOffers()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range busy {
+		excluded[id] = true
+	}
+
+	ids := make([]uuid.UUID, 0, len(couriers))
+	for _, courier := range couriers {
+		ids = append(ids, courier.UserID)
+	}
+	loads, err := s.orderRepo.CountActiveDeliveries(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]CourierCandidate, 0, len(couriers))
+	for _, courier := range couriers {
+		if excluded[courier.UserID] {
+			continue
+		}
+		candidates = append(candidates, CourierCandidate{
+			CourierID:        courier.UserID,
+			Zone:             courier.Zone,
+			ActiveDeliveries: loads[courier.UserID],
+		})
+	}
+	return candidates, nil
+}
+
+// expireOffer closes an unanswered offer and re-offers the order
+func (s *DispatchService) expireOffer(offer *models.DeliveryOffer) {
+	ok, err := s.offerRepo.Resolve(offer.ID, models.DeliveryOfferExpired, time.Now())
+	if err != nil {
+		log.Printf("dispatch: failed to expire offer %s: %v", offer.ID, err)
+		return
+	}
+	if ok {
+		s.dispatch(offer.OrderID)
+	}
+}
+
+// releaseCourier makes a busy courier available again once they have no active deliveries left
+func (s *DispatchService) releaseCourier(courierID uuid.UUID) {
+	loads, err := s.orderRepo.CountActiveDeliveries([]uuid.UUID{courierID})
+	if err != nil || loads[courierID] > 0 {
+		return
+	}
+	if err := s.courierRepo.UpdateStatus(courierID, models.CourierStatusBusy, models.CourierStatusOnline); err != nil {
+		log.Printf("dispatch: failed to release courier %s: %v", courierID, err)
+	}
+}
+
+// getCourierOffer loads an offer and checks that it was made to the courier
+func (s *DispatchService) getCourierOffer(offerID, courierID uuid.UUID) (*models.DeliveryOffer, error) {
+	offer, err := s.offerRepo.GetByID(offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.CourierID != courierID {
+		return nil, errors.New("permission denied")
+	}
+	if offer.Status != models.DeliveryOfferPending {
+		return nil, ErrOfferClosed
+	}
+	return offer, nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// Dispatch strategy names
+const (
+	DispatchRoundRobin  = "round_robin"
+	DispatchLeastLoaded = "least_loaded"
+	DispatchNearestZone = "nearest_zone"
+)
+
+// CourierCandidate is an available courier that may be offered an order
+type CourierCandidate struct {
+	CourierID        uuid.UUID
+	Zone             string
+	ActiveDeliveries int
+}
+
+// DispatchStrategy picks which courier should be offered an order.
+// Candidates are never empty and are sorted by courier ID.
+type DispatchStrategy interface {
+	Name() string
+	Pick(order *models.Order, store *models.Store, candidates []CourierCandidate) CourierCandidate
+}
+
+// NewDispatchStrategy returns the strategy registered under name
+func NewDispatchStrategy(name string) (DispatchStrategy, error) {
+	switch name {
+	case DispatchRoundRobin:
+		return &RoundRobinStrategy{}, nil
+	case DispatchLeastLoaded:
+		return LeastLoadedStrategy{}, nil
+	case DispatchNearestZone:
+		return NearestZoneStrategy{}, nil
+	}
+	return nil, errors.New("unknown dispatch strategy: " + name)
+}
+
+// RoundRobinStrategy offers orders to couriers in turn
+type RoundRobinStrategy struct {
+	mu   sync.Mutex
+	last uuid.UUID
+}
+
+// Name returns the strategy name
+func (s *RoundRobinStrategy) Name() string {
+	return DispatchRoundRobin
+}
+
+// Pick returns the first courier after the one picked last time
+func (s *RoundRobinStrategy) Pick(order *models.Order, store *models.Store, candidates []CourierCandidate) CourierCandidate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	picked := candidates[0]
+	for _, candidate := range candidates {
+		if bytes.Compare(candidate.CourierID[:], s.last[:]) > 0 {
+			picked = candidate
+			break
+		}
+	}
+	s.last = picked.CourierID
+	return picked
+}
+
+// LeastLoadedStrategy offers orders to the courier with the fewest active deliveries
+type LeastLoadedStrategy struct{}
+
+// Name returns the strategy name
+func (LeastLoadedStrategy) Name() string {
+	return DispatchLeastLoaded
+}
+
+// Pick returns the courier with the fewest active deliveries
+func (LeastLoadedStrategy) Pick(order *models.Order, store *models.Store, candidates []CourierCandidate) CourierCandidate {
+	sorted := make([]CourierCandidate, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActiveDeliveries < sorted[j].ActiveDeliveries
+	})
+	return sorted[0]
+}
+
+// NearestZoneStrategy prefers couriers in the same campus zone as the store,
+// falling back to the least loaded courier anywhere on campus
+type NearestZoneStrategy struct{}
+
+// Name returns the strategy name
+func (NearestZoneStrategy) Name() string {
+	return DispatchNearestZone
+}
+
+// Pick returns the least loaded courier in the store zone, or the least loaded courier overall
+func (NearestZoneStrategy) Pick(order *models.Order, store *models.Store, candidates []CourierCandidate) CourierCandidate {
+	if store != nil && store.Zone != "" {
+		var sameZone []CourierCandidate
+		for _, candidate := range candidates {
+			if candidate.Zone == store.Zone {
+				sameZone = append(sameZone, candidate)
+			}
+		}
+		if len(sameZone) > 0 {
+			return LeastLoadedStrategy{}.Pick(order, store, sameZone)
+		}
+	}
+	return LeastLoadedStrategy{}.Pick(order, store, candidates)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// ErrIdempotencyKeyMismatch is returned when a key is reused for a different request
+var ErrIdempotencyKeyMismatch = errors.New("idempotency key was already used for a different request")
+
+// ErrIdempotencyKeyInProgress is returned when the original request of a key has not finished yet
+var ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+
+// IdempotencyService keeps track of requests sent with an Idempotency-Key header
+type IdempotencyService struct {
+	repo *repository.IdempotencyRepository
+	ttl  time.Duration
+
+	stop chan struct{}
+}
+
+// NewIdempotencyService creates a new idempotency service; keys are remembered for ttl
+func NewIdempotencyService(repo *repository.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+// Begin registers a request made with an idempotency key.
+// For a new key it returns an in-progress record and the request should be processed.
+// For a key seen before it returns the completed record whose response must be replayed,
+// ErrIdempotencyKeyInProgress while the first request is running, or ErrIdempotencyKeyMismatch
+// when the key was used for a different request.
+func (s *IdempotencyService) Begin(userID uuid.UUID, key, method, path, requestHash string) (*models.IdempotencyKey, error) {
+	// Two attempts: the second one runs after an expired key was removed
+	for attempt := 0; attempt < 2; attempt++ {
+		record := &models.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Method:      method,
+			Path:        path,
+			RequestHash: requestHash,
+			Status:      models.IdempotencyInProgress,
+			ExpiresAt:   time.Now().Add(s.ttl),
+		}
+		reserved, err := s.repo.Reserve(record)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return record, nil
+		}
+
+		existing, err := s.repo.GetByUserAndKey(userID, key)
+		if err != nil {
+			// Removed by a concurrent request in the meantime
+			continue
+		}
+		if time.Now().After(existing.ExpiresAt) {
+			if err := s.repo.Delete(existing.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if existing.Method != method || existing.Path != path || existing.RequestHash != requestHash {
+			return nil, ErrIdempotencyKeyMismatch
+		}
+		if existing.Status != models.IdempotencyCompleted {
+			return nil, ErrIdempotencyKeyInProgress
+		}
+		return existing, nil
+	}
+	return nil, ErrIdempotencyKeyInProgress
+}
+
+// Complete stores the response of a request so that retries can replay it.
+// Server errors are not stored, so the request may be retried with the same key.
+func (s *IdempotencyService) Complete(record *models.IdempotencyKey, code int, body []byte, contentType string) error {
+	if code >= 500 {
+		return s.repo.Delete(record.ID)
+	}
+	return s.repo.Complete(record.ID, code, body, contentType)
+}
+
+// Abort forgets a key whose request did not produce a response
+func (s *IdempotencyService) Abort(record *models.IdempotencyKey) error {
+	return s.repo.Delete(record.ID)
+}
+
+// Start launches the background loop that removes expired keys
+func (s *IdempotencyService) Start(interval time.Duration) {
+	s.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.repo.DeleteExpired(time.Now()); err != nil {
+					log.Printf("idempotency: failed to delete expired keys: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background loop
+func (s *IdempotencyService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// InventoryService keeps the inventory ledger. Every stock change of a product or variant
+// is appended as a movement and applied to the cached stock in the same transaction.
+type InventoryService struct {
+	productRepo  *repository.ProductRepository
+	optionRepo   *repository.ProductOptionRepository
+	movementRepo *repository.StockMovementRepository
+}
+
+// NewInventoryService creates a new inventory service
+func NewInventoryService(
+	productRepo *repository.ProductRepository,
+	optionRepo *repository.ProductOptionRepository,
+	movementRepo *repository.StockMovementRepository,
+) *InventoryService {
+	return &InventoryService{
+		productRepo:  productRepo,
+		optionRepo:   optionRepo,
+		movementRepo: movementRepo,
+	}
+}
+
+// ListMovements returns a page of the ledger of a product, newest first, optionally limited to one variant
+func (s *InventoryService) ListMovements(productID uuid.UUID, variantID *uuid.UUID, limit, offset int, cursor string) ([]models.StockMovement, string, int64, error) {
+	page, err := newPage(limit, offset, cursor, 20, 100)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return s.movementRepo.ListByProduct(productID, variantID, page)
+}
+
+// record applies a movement to the cached stock of its product or variant and appends it to
+// the ledger, inside the caller's transaction. It returns false, changing nothing, when the
+// movement would take the stock below zero.
+func (s *InventoryService) record(tx *gorm.DB, movement *models.StockMovement) (bool, error) {
+	var stock int
+	var ok bool
+	var err error
+	if movement.VariantID != nil {
+		stock, ok, err = s.optionRepo.WithTx(tx).AdjustVariantStock(*movement.VariantID, movement.Quantity)
+	} else {
+		stock, ok, err = s.productRepo.WithTx(tx).AdjustStock(movement.ProductID, movement.Quantity)
+	}
+	if err != nil || !ok {
+		return false, err
+	}
+
+	movement.StockAfter = stock
+	if err := s.movementRepo.WithTx(tx).Create(movement); err != nil {
+		return false, err
+	}
+	return true, nil
+}
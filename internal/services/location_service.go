@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// LocationService handles business logic for the campus location catalog
+type LocationService struct {
+	locationRepo *repository.LocationRepository
+}
+
+// NewLocationService creates a new location service
+func NewLocationService(locationRepo *repository.LocationRepository) *LocationService {
+	return &LocationService{
+		locationRepo: locationRepo,
+	}
+}
+
+// CreateLocation adds a location to the catalog with validation
+func (s *LocationService) CreateLocation(location *models.CampusLocation) error {
+	if err := validateLocation(location); err != nil {
+		return err
+	}
+	return s.locationRepo.Create(location)
+}
+
+// GetLocationByID retrieves a location by ID
+func (s *LocationService) GetLocationByID(id uuid.UUID) (*models.CampusLocation, error) {
+	return s.locationRepo.GetByID(id)
+}
+
+// UpdateLocation updates a location with validation
+func (s *LocationService) UpdateLocation(location *models.CampusLocation) error {
+	if err := validateLocation(location); err != nil {
+		return err
+	}
+
+	// Check if location exists
+	if _, err := s.locationRepo.GetByID(location.ID); err != nil {
+		return err
+	}
+
+	return s.locationRepo.Update(location)
+}
+
+// DeleteLocation soft deletes a location
+func (s *LocationService) DeleteLocation(id uuid.UUID) error {
+	// Check if location exists
+	if _, err := s.locationRepo.GetByID(id); err != nil {
+		return err
+	}
+	return s.locationRepo.Delete(id)
+}
+
+// ListLocations returns the catalog, optionally filtered by building
+func (s *LocationService) ListLocations(building string, activeOnly bool) ([]*models.CampusLocation, error) {
+	return s.locationRepo.List(strings.TrimSpace(building), activeOnly)
+}
+
+// validateLocation checks the required fields and coordinates of a location
+func validateLocation(location *models.CampusLocation) error {
+	if strings.TrimSpace(location.Name) == "" {
+		return errors.New("location name is required")
+	}
+	if strings.TrimSpace(location.Building) == "" {
+		return errors.New("building is required")
+	}
+	if (location.Latitude == nil) != (location.Longitude == nil) {
+		return errors.New("latitude and longitude must be provided together")
+	}
+	if location.Latitude != nil && (*location.Latitude < -90 || *location.Latitude > 90) {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if location.Longitude != nil && (*location.Longitude < -180 || *location.Longitude > 180) {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/mailer"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// lowStockBatchSize is the number of low-stock products handled per check
+const lowStockBatchSize = 500
+
+// LowStockService alerts store members when products drop to their reorder threshold.
+// Each shortage is alerted once; the alert is re-armed when the product is restocked above
+// its threshold.
+type LowStockService struct {
+	productRepo *repository.ProductRepository
+	storeRepo   *repository.StoreRepository
+	memberRepo  *repository.StoreMemberRepository
+	mailer      mailer.Mailer
+
+	stop chan struct{}
+}
+
+// NewLowStockService creates a new low-stock service
+func NewLowStockService(
+	productRepo *repository.ProductRepository,
+	storeRepo *repository.StoreRepository,
+	memberRepo *repository.StoreMemberRepository,
+	mail mailer.Mailer,
+) *LowStockService {
+	return &LowStockService{
+		productRepo: productRepo,
+		storeRepo:   storeRepo,
+		memberRepo:  memberRepo,
+		mailer:      mail,
+	}
+}
+
+// Start launches the background loop that checks stock levels
+func (s *LowStockService) Start(interval time.Duration) {
+	s.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Check(); err != nil {
+					log.Printf("low stock: check failed: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	log.Printf("Low-stock checker started (interval: %s)", interval)
+}
+
+// Stop stops the background loop
+func (s *LowStockService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+	}
+}
+
+// Check re-arms the alerts of restocked products and emails the members of each store
+// about its products that reached their reorder threshold
+func (s *LowStockService) Check() error {
+	if _, err := s.productRepo.ResetRestockedAlerts(); err != nil {
+		return err
+	}
+
+	products, err := s.productRepo.ListLowStock(lowStockBatchSize)
+	if err != nil {
+		return err
+	}
+
+	// Claim the alerts first, so that concurrent checkers never send the same alert twice
+	now := time.Now()
+	byStore := make(map[uuid.UUID][]*models.Product)
+	var storeIDs []uuid.UUID
+	for _, product := range products {
+		claimed, err := s.productRepo.ClaimLowStockAlert(product.ID, now)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		if _, ok := byStore[product.StoreID]; !ok {
+			storeIDs = append(storeIDs, product.StoreID)
+		}
+		byStore[product.StoreID] = append(byStore[product.StoreID], product)
+	}
+
+	for _, storeID := range storeIDs {
+		s.alertStore(storeID, byStore[storeID])
+	}
+	return nil
+}
+
+// alertStore emails a low-stock alert to every member of a store. Failures are logged,
+// the alerts are not retried.
+func (s *LowStockService) alertStore(storeID uuid.UUID, products []*models.Product) {
+	store, err := s.storeRepo.GetByID(storeID)
+	if err != nil {
+		log.Printf("low stock: failed to load store %s: %v", storeID, err)
+		return
+	}
+	members, err := s.memberRepo.ListByStore(storeID)
+	if err != nil {
+		log.Printf("low stock: failed to load members of store %s: %v", storeID, err)
+		return
+	}
+
+	for _, member := range members {
+		if member.User == nil || member.User.Email == "" {
+			continue
+		}
+		if err := s.mailer.Send(lowStockEmail(store, member.User.Email, products)); err != nil {
+			log.Printf("low stock: failed to alert %s of store %s: %v", member.User.Email, storeID, err)
+		}
+	}
+}
+
+// availableStock returns the stock a product can be sold from: the total of its active
+// variants, or its own stock when it has none
+func availableStock(product *models.Product) int {
+	total, hasVariants := 0, false
+	for _, variant := range product.Variants {
+		if variant.IsActive {
+			total += variant.Stock
+			hasVariants = true
+		}
+	}
+	if !hasVariants {
+		return product.Stock
+	}
+	return total
+}
+
+// lowStockEmail builds the alert listing the products of a store that are running low
+func lowStockEmail(store *models.Store, to string, products []*models.Product) mailer.Message {
+	var lines strings.Builder
+	for _, product := range products {
+		fmt.Fprintf(&lines, "- %s: %d left (reorder at %d)", product.Name, availableStock(product), product.ReorderThreshold)
+		if product.SKU != "" {
+			fmt.Fprintf(&lines, ", SKU %s", product.SKU)
+		}
+		lines.WriteString("\n")
+	}
+
+	return mailer.Message{
+		To:      to,
+		Subject: fmt.Sprintf("Low stock at %s", store.Name),
+		Body: fmt.Sprintf(
+			"These products of %s have reached their reorder threshold:\n\n%s\n"+
+				"Record a restock to clear the alert.\n",
+			store.Name, lines.String(),
+		),
+	}
+}
@@ -2,39 +2,142 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ruranjo/unientrega/internal/models"
 	"github.com/ruranjo/unientrega/internal/repository"
+	"github.com/ruranjo/unientrega/internal/utils"
+	"gorm.io/gorm"
 )
 
 // OrderService handles order business logic
 type OrderService struct {
-	orderRepo   *repository.OrderRepository
-	productRepo *repository.ProductRepository
-	storeRepo   *repository.StoreRepository
+	txManager    *repository.TxManager
+	orderRepo    *repository.OrderRepository
+	eventRepo    *repository.OrderStatusEventRepository
+	productRepo  *repository.ProductRepository
+	optionRepo   *repository.ProductOptionRepository
+	storeRepo    *repository.StoreRepository
+	locationRepo *repository.LocationRepository
+	addressRepo  *repository.DeliveryAddressRepository
+	refundRepo   *repository.RefundRepository
+	printJobRepo *repository.PrintJobRepository
+	pricing      *PricingService
+	storeHours   *StoreHoursService
+	timeSlots    *TimeSlotService
+	inventory    *InventoryService
+	access       *StoreAccess
+	listeners    []OrderStatusListener
 }
 
 // NewOrderService creates a new order service
-func NewOrderService(orderRepo *repository.OrderRepository, productRepo *repository.ProductRepository, storeRepo *repository.StoreRepository) *OrderService {
+func NewOrderService(
+	txManager *repository.TxManager,
+	orderRepo *repository.OrderRepository,
+	eventRepo *repository.OrderStatusEventRepository,
+	productRepo *repository.ProductRepository,
+	optionRepo *repository.ProductOptionRepository,
+	storeRepo *repository.StoreRepository,
+	locationRepo *repository.LocationRepository,
+	addressRepo *repository.DeliveryAddressRepository,
+	refundRepo *repository.RefundRepository,
+	printJobRepo *repository.PrintJobRepository,
+	pricing *PricingService,
+	storeHours *StoreHoursService,
+	timeSlots *TimeSlotService,
+	inventory *InventoryService,
+	access *StoreAccess,
+) *OrderService {
 	return &OrderService{
-		orderRepo:   orderRepo,
-		productRepo: productRepo,
-		storeRepo:   storeRepo,
+		txManager:    txManager,
+		orderRepo:    orderRepo,
+		eventRepo:    eventRepo,
+		productRepo:  productRepo,
+		optionRepo:   optionRepo,
+		storeRepo:    storeRepo,
+		locationRepo: locationRepo,
+		addressRepo:  addressRepo,
+		refundRepo:   refundRepo,
+		printJobRepo: printJobRepo,
+		pricing:      pricing,
+		storeHours:   storeHours,
+		timeSlots:    timeSlots,
+		inventory:    inventory,
+		access:       access,
 	}
 }
 
+// AddStatusListener registers a listener that is notified of every committed status change
+func (s *OrderService) AddStatusListener(listener OrderStatusListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// OrderItemRequest represents a product and quantity requested in an order
+type OrderItemRequest struct {
+	ProductID   uuid.UUID   `json:"product_id" binding:"required"`
+	VariantID   *uuid.UUID  `json:"variant_id"`   // Required for products with variants
+	ModifierIDs []uuid.UUID `json:"modifier_ids"` // Chosen modifiers of the product option groups
+	Quantity    int         `json:"quantity" binding:"required,min=1"`
+}
+
 // CreateOrderRequest represents the request to create an order
 type CreateOrderRequest struct {
-	StoreID uuid.UUID `json:"store_id" binding:"required"`
-	Items   []struct {
-		ProductID uuid.UUID `json:"product_id" binding:"required"`
-		Quantity  int       `json:"quantity" binding:"required,min=1"`
-	} `json:"items" binding:"required,min=1"`
+	StoreID     uuid.UUID          `json:"store_id" binding:"required"`
+	Items       []OrderItemRequest `json:"items" binding:"dive"`
+	PrintJobIDs []uuid.UUID        `json:"print_job_ids"` // Uploaded print jobs of the user at the same store
+
+	// Destination: exactly one of the following must be set
+	PickupAtStore      bool       `json:"pickup_at_store"`
+	DeliveryAddressID  *uuid.UUID `json:"delivery_address_id"`  // Saved address of the user
+	DeliveryLocationID *uuid.UUID `json:"delivery_location_id"` // Any active campus location
+
+	DeliveryInstructions string `json:"delivery_instructions"` // Overrides the instructions of the saved address
+
+	// Optional start of a 15-minute time slot to pick up or receive the order; as soon as possible when empty
+	ScheduledFor *time.Time `json:"scheduled_for"`
+}
+
+// StockShortage describes an order item that cannot be covered by the available stock
+type StockShortage struct {
+	ProductID uuid.UUID  `json:"product_id"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+	Name      string     `json:"name"`
+	Requested int        `json:"requested"`
+	Available int        `json:"available"`
 }
 
-// CreateOrder creates a new order
-func (s *OrderService) CreateOrder(userID uuid.UUID, req *CreateOrderRequest) (*models.Order, error) {
+// InsufficientStockError is returned when one or more order items exceed the available stock
+type InsufficientStockError struct {
+	Items []StockShortage
+}
+
+// Error implements the error interface
+func (e *InsufficientStockError) Error() string {
+	names := make([]string, 0, len(e.Items))
+	for _, item := range e.Items {
+		names = append(names, item.Name)
+	}
+	return "insufficient stock for products: " + strings.Join(names, ", ")
+}
+
+// variantShortage describes a variant without enough stock
+func variantShortage(product *models.Product, variant *models.ProductVariant, requested int) StockShortage {
+	return StockShortage{
+		ProductID: product.ID,
+		VariantID: &variant.ID,
+		Name:      product.Name + " (" + variant.Name + ")",
+		Requested: requested,
+		Available: variant.Stock,
+	}
+}
+
+// CreateOrder creates a new order.
+// Stock is reserved and the order is stored in a single transaction, so either the whole
+// order is placed or nothing changes.
+func (s *OrderService) CreateOrder(userID uuid.UUID, role models.Role, req *CreateOrderRequest) (*models.Order, error) {
 	// Verify store exists and is active
 	store, err := s.storeRepo.GetByID(req.StoreID)
 	if err != nil {
@@ -44,70 +147,395 @@ func (s *OrderService) CreateOrder(userID uuid.UUID, req *CreateOrderRequest) (*
 		return nil, errors.New("store is not active")
 	}
 
-	// Prepare order
-	order := &models.Order{
-		UserID:  userID,
-		StoreID: req.StoreID,
-		Status:  models.OrderStatusPending,
-		Items:   make([]models.OrderItem, 0, len(req.Items)),
+	// Scheduled orders need an open slot, other orders an open store
+	var slotSettings *models.TimeSlotSettings
+	if req.ScheduledFor != nil {
+		slotSettings, err = s.timeSlots.ValidateSlot(store, *req.ScheduledFor)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := s.storeHours.CheckOpen(store, time.Now()); err != nil {
+		return nil, err
 	}
 
-	var total float64
+	if len(req.Items) == 0 && len(req.PrintJobIDs) == 0 {
+		return nil, errors.New("order must contain at least one item or print job")
+	}
 
-	// Process items
+	// Merge repeated lines with the same configuration into one order item
+	productIDs := make([]uuid.UUID, 0, len(req.Items))
+	seenProducts := make(map[uuid.UUID]bool, len(req.Items))
+	lines := make([]OrderItemRequest, 0, len(req.Items))
+	lineIndex := make(map[string]int, len(req.Items))
 	for _, itemReq := range req.Items {
-		product, err := s.productRepo.GetByID(itemReq.ProductID)
+		if itemReq.Quantity < 1 {
+			return nil, errors.New("item quantity must be at least 1")
+		}
+		if !seenProducts[itemReq.ProductID] {
+			seenProducts[itemReq.ProductID] = true
+			productIDs = append(productIDs, itemReq.ProductID)
+		}
+		key := configurationKey(itemReq)
+		if i, ok := lineIndex[key]; ok {
+			lines[i].Quantity += itemReq.Quantity
+			continue
+		}
+		lineIndex[key] = len(lines)
+		lines = append(lines, itemReq)
+	}
+
+	// Prepare order; the ID is assigned up front so that stock movements can refer to it
+	order := &models.Order{
+		ID:           uuid.New(),
+		UserID:       userID,
+		StoreID:      req.StoreID,
+		Status:       models.OrderStatusPending,
+		Items:        make([]models.OrderItem, 0, len(lines)),
+		ScheduledFor: req.ScheduledFor,
+	}
+
+	destination, err := s.applyDestination(userID, req, order)
+	if err != nil {
+		return nil, err
+	}
+
+	var storeLocation *models.CampusLocation
+	if store.LocationID != nil {
+		storeLocation, _ = s.locationRepo.GetByID(*store.LocationID)
+	}
+
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		productRepo := s.productRepo.WithTx(tx)
+
+		// Lock the product rows so concurrent orders wait for this one to finish
+		products, err := productRepo.GetByIDsForUpdate(productIDs)
 		if err != nil {
-			return nil, errors.New("product not found: " + itemReq.ProductID.String())
+			return err
+		}
+		productsByID := make(map[uuid.UUID]*models.Product, len(products))
+		for _, product := range products {
+			productsByID[product.ID] = product
 		}
 
-		if !product.IsActive {
-			return nil, errors.New("product is not active: " + product.Name)
+		// Variants carry their own stock, so their rows are locked as well
+		optionRepo := s.optionRepo.WithTx(tx)
+		variants, err := optionRepo.GetVariantsByProductIDsForUpdate(productIDs)
+		if err != nil {
+			return err
+		}
+		variantsByProduct := make(map[uuid.UUID][]*models.ProductVariant, len(productIDs))
+		for _, variant := range variants {
+			variantsByProduct[variant.ProductID] = append(variantsByProduct[variant.ProductID], variant)
+		}
+		groups, err := optionRepo.ListOptionGroupsByProducts(productIDs)
+		if err != nil {
+			return err
+		}
+		groupsByProduct := make(map[uuid.UUID][]models.ProductOptionGroup, len(productIDs))
+		for _, group := range groups {
+			groupsByProduct[group.ProductID] = append(groupsByProduct[group.ProductID], group)
 		}
 
-		if product.StoreID != req.StoreID {
-			return nil, errors.New("product does not belong to the store: " + product.Name)
+		var subtotal *models.Money
+
+		// An order is charged in a single currency
+		addToSubtotal := func(amount models.Money) error {
+			if subtotal == nil {
+				subtotal = &amount
+				return nil
+			}
+			if !subtotal.SameCurrency(amount) {
+				return errors.New("products are priced in different currencies")
+			}
+			*subtotal = subtotal.Add(amount)
+			return nil
 		}
 
-		if product.Stock < itemReq.Quantity {
-			return nil, errors.New("insufficient stock for product: " + product.Name)
+		// Process items; stock is tracked per variant for products with variants
+		productQuantities := make(map[uuid.UUID]int, len(productIDs))
+		variantQuantities := make(map[uuid.UUID]int)
+		for _, line := range lines {
+			product, ok := productsByID[line.ProductID]
+			if !ok {
+				return errors.New("product not found: " + line.ProductID.String())
+			}
+
+			if !product.IsActive {
+				return errors.New("product is not active: " + product.Name)
+			}
+
+			if product.StoreID != req.StoreID {
+				return errors.New("product does not belong to the store: " + product.Name)
+			}
+
+			// Create order item (snapshot of the current configuration and price)
+			item, err := configureItem(product, variantsByProduct[product.ID], groupsByProduct[product.ID], line)
+			if err != nil {
+				return err
+			}
+			if item.VariantID != nil {
+				variantQuantities[*item.VariantID] += item.Quantity
+			} else {
+				productQuantities[product.ID] += item.Quantity
+			}
+			order.Items = append(order.Items, *item)
+
+			// Update subtotal
+			if err := addToSubtotal(item.Price.Multiply(item.Quantity)); err != nil {
+				return err
+			}
 		}
 
-		// Create order item
-		orderItem := models.OrderItem{
-			ProductID: product.ID,
-			Quantity:  itemReq.Quantity,
-			Price:     product.Price,
+		var shortages []StockShortage
+		for _, productID := range productIDs {
+			product := productsByID[productID]
+			if quantity := productQuantities[productID]; quantity > 0 && product.Stock < quantity {
+				shortages = append(shortages, StockShortage{
+					ProductID: product.ID,
+					Name:      product.Name,
+					Requested: quantity,
+					Available: product.Stock,
+				})
+			}
+		}
+		for _, variant := range variants {
+			if quantity := variantQuantities[variant.ID]; quantity > 0 && variant.Stock < quantity {
+				shortages = append(shortages, variantShortage(productsByID[variant.ProductID], variant, quantity))
+			}
+		}
+		if len(shortages) > 0 {
+			return &InsufficientStockError{Items: shortages}
 		}
-		order.Items = append(order.Items, orderItem)
 
-		// Update total
-		total += product.Price * float64(itemReq.Quantity)
+		// Record the sales in the inventory ledger; the rows are locked, the conditional
+		// updates are a last safeguard
+		for _, productID := range productIDs {
+			quantity := productQuantities[productID]
+			if quantity == 0 {
+				continue
+			}
+			ok, err := s.inventory.record(tx, &models.StockMovement{
+				ProductID: productID,
+				Type:      models.StockMovementSale,
+				Quantity:  -quantity,
+				OrderID:   &order.ID,
+				ActorID:   &userID,
+				ActorRole: role,
+			})
+			if err != nil {
+				return err
+			}
+			if !ok {
+				product := productsByID[productID]
+				return &InsufficientStockError{Items: []StockShortage{{
+					ProductID: product.ID,
+					Name:      product.Name,
+					Requested: quantity,
+					Available: product.Stock,
+				}}}
+			}
+		}
+		for _, variant := range variants {
+			quantity := variantQuantities[variant.ID]
+			if quantity == 0 {
+				continue
+			}
+			ok, err := s.inventory.record(tx, &models.StockMovement{
+				ProductID: variant.ProductID,
+				VariantID: &variant.ID,
+				Type:      models.StockMovementSale,
+				Quantity:  -quantity,
+				OrderID:   &order.ID,
+				ActorID:   &userID,
+				ActorRole: role,
+			})
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return &InsufficientStockError{Items: []StockShortage{variantShortage(productsByID[variant.ProductID], variant, quantity)}}
+			}
+		}
 
-		// Decrement stock (simple approach, ideally should be transactional with order creation)
-		// In a real app, we might reserve stock first or use a transaction in the repo layer
-		// For now, we'll update it here. If order creation fails, we might have an issue (stock lost).
-		// A better approach would be to pass a transaction to the service or handle it in repo.
-		// Given the current architecture, we'll assume happy path or fix later.
-		// To be safe, let's NOT decrement here but rely on the handler/repo transaction if possible.
-		// But since we don't have transaction propagation yet, let's just update it.
-		err = s.productRepo.UpdateStock(product.ID, product.Stock-itemReq.Quantity)
+		// Price print jobs with the current store rates
+		printJobs, printTurnaround, err := s.lockPrintJobs(tx, userID, req)
 		if err != nil {
-			return nil, errors.New("failed to update stock for product: " + product.Name)
+			return err
+		}
+		for _, job := range printJobs {
+			if err := addToSubtotal(job.Price); err != nil {
+				return err
+			}
 		}
-	}
 
-	order.Total = total
+		// Add delivery fee and surcharges
+		fees, err := s.pricing.Quote(DeliveryQuote{
+			Subtotal:      *subtotal,
+			PickupAtStore: order.PickupAtStore,
+			Store:         store,
+			StoreLocation: storeLocation,
+			Destination:   destination,
+		})
+		if err != nil {
+			return err
+		}
+		order.Subtotal = fees.Subtotal
+		order.DeliveryFee = fees.DeliveryFee
+		order.DeliveryFeeBasis = fees.DeliveryFeeBasis
+		order.SmallOrderFee = fees.SmallOrderSurcharge
+		order.Total = fees.Total
 
-	err = s.orderRepo.Create(order)
+		if order.ScheduledFor != nil {
+			if err := s.timeSlots.reserve(tx, store.ID, *order.ScheduledFor, slotSettings.Capacity); err != nil {
+				return err
+			}
+		}
+
+		if err := s.orderRepo.WithTx(tx).Create(order); err != nil {
+			return err
+		}
+
+		if len(printJobs) > 0 {
+			// Copies are promised for the scheduled slot unless they take longer to print
+			promisedAt := time.Now().Add(time.Duration(printTurnaround) * time.Minute)
+			if order.ScheduledFor != nil && order.ScheduledFor.After(promisedAt) {
+				promisedAt = *order.ScheduledFor
+			}
+			attached, err := s.printJobRepo.WithTx(tx).AttachToOrder(req.PrintJobIDs, order.ID, promisedAt)
+			if err != nil {
+				return err
+			}
+			if !attached {
+				return ErrPrintJobOrdered
+			}
+			order.PrintJobs = make([]models.PrintJob, 0, len(printJobs))
+			for _, job := range printJobs {
+				job.OrderID = &order.ID
+				job.Status = models.PrintJobStatusQueued
+				job.PromisedAt = &promisedAt
+				order.PrintJobs = append(order.PrintJobs, *job)
+			}
+		}
+
+		// Start the order timeline
+		return s.eventRepo.WithTx(tx).Create(&models.OrderStatusEvent{
+			OrderID:       order.ID,
+			ToStatus:      order.Status,
+			ChangedBy:     userID,
+			ChangedByRole: role,
+		})
+	})
 	if err != nil {
-		// Rollback stock changes? (Complex without transactions)
 		return nil, err
 	}
 
 	return order, nil
 }
 
+// lockPrintJobs locks the print jobs of an order request, checks that they can be ordered
+// and prices them again with the current rates of the store.
+// It also returns the turnaround in minutes promised by the store.
+func (s *OrderService) lockPrintJobs(tx *gorm.DB, userID uuid.UUID, req *CreateOrderRequest) ([]*models.PrintJob, int, error) {
+	if len(req.PrintJobIDs) == 0 {
+		return nil, 0, nil
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.PrintJobIDs))
+	for _, id := range req.PrintJobIDs {
+		if seen[id] {
+			return nil, 0, errors.New("print job listed more than once: " + id.String())
+		}
+		seen[id] = true
+	}
+
+	printJobRepo := s.printJobRepo.WithTx(tx)
+	jobs, err := printJobRepo.GetByIDsForUpdate(req.PrintJobIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(jobs) != len(req.PrintJobIDs) {
+		return nil, 0, errors.New("print job not found")
+	}
+
+	pricing, err := printJobRepo.GetPricing(req.StoreID)
+	if err != nil {
+		return nil, 0, ErrPrintingNotOffered
+	}
+
+	for _, job := range jobs {
+		if job.UserID != userID {
+			return nil, 0, errors.New("print job not found")
+		}
+		if job.StoreID != req.StoreID {
+			return nil, 0, errors.New("print job does not belong to the store: " + job.FileName)
+		}
+		if job.OrderID != nil || job.Status != models.PrintJobStatusUploaded {
+			return nil, 0, ErrPrintJobOrdered
+		}
+		if err := quotePrintJob(job, pricing); err != nil {
+			return nil, 0, err
+		}
+		if err := printJobRepo.Update(job); err != nil {
+			return nil, 0, err
+		}
+	}
+	return jobs, pricing.TurnaroundMinutes, nil
+}
+
+// applyDestination validates the delivery target of an order request and sets it on the order.
+// It returns the destination location, or nil for pickup orders.
+func (s *OrderService) applyDestination(userID uuid.UUID, req *CreateOrderRequest, order *models.Order) (*models.CampusLocation, error) {
+	targets := 0
+	if req.PickupAtStore {
+		targets++
+	}
+	if req.DeliveryAddressID != nil {
+		targets++
+	}
+	if req.DeliveryLocationID != nil {
+		targets++
+	}
+	if targets == 0 {
+		return nil, errors.New("a delivery address, a delivery location or pickup at store is required")
+	}
+	if targets > 1 {
+		return nil, errors.New("choose only one of delivery address, delivery location or pickup at store")
+	}
+
+	if req.PickupAtStore {
+		order.PickupAtStore = true
+		return nil, nil
+	}
+
+	locationID := req.DeliveryLocationID
+	order.DeliveryInstructions = req.DeliveryInstructions
+
+	if req.DeliveryAddressID != nil {
+		address, err := s.addressRepo.GetByID(*req.DeliveryAddressID)
+		if err != nil {
+			return nil, err
+		}
+		if address.UserID != userID {
+			return nil, errors.New("address not found")
+		}
+		locationID = &address.LocationID
+		order.DeliveryAddressID = &address.ID
+		if order.DeliveryInstructions == "" {
+			order.DeliveryInstructions = address.Instructions
+		}
+	}
+
+	location, err := s.locationRepo.GetByID(*locationID)
+	if err != nil {
+		return nil, err
+	}
+	if !location.IsActive {
+		return nil, errors.New("delivery location is not active")
+	}
+	order.DeliveryLocationID = &location.ID
+	return location, nil
+}
+
 // GetOrder retrieves an order by ID and checks permissions
 func (s *OrderService) GetOrder(id uuid.UUID, userID uuid.UUID, role models.Role) (*models.Order, error) {
 	order, err := s.orderRepo.GetByID(id)
@@ -124,56 +552,245 @@ func (s *OrderService) GetOrder(id uuid.UUID, userID uuid.UUID, role models.Role
 		return order, nil
 	}
 
-	// Check if user is owner of the store
-	store, err := s.storeRepo.GetByID(order.StoreID)
+	// Assigned courier can see the order they deliver
+	if order.DeliveryPersonID != nil && *order.DeliveryPersonID == userID {
+		return order, nil
+	}
+
+	// Store members can see the orders of their store
+	allowed, err := s.access.Can(order.StoreID, userID, role, models.PermissionManageOrders)
 	if err != nil {
 		return nil, err
 	}
-
-	if store.OwnerID == userID {
+	if allowed {
 		return order, nil
 	}
 
 	return nil, errors.New("permission denied")
 }
 
-// ListUserOrders lists orders for a user
-func (s *OrderService) ListUserOrders(userID uuid.UUID, limit, offset int) ([]models.Order, int64, error) {
-	return s.orderRepo.ListByUser(userID, limit, offset)
+// ListUserOrders lists a page of the orders of a user matching a list query.
+// It returns the cursor of the next page and, in offset mode, the total.
+func (s *OrderService) ListUserOrders(userID uuid.UUID, limit, offset int, cursor string, list utils.ListQuery) ([]models.Order, string, int64, error) {
+	page, err := newPage(limit, offset, cursor, 10, 100)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return s.orderRepo.ListByUser(userID, page, list)
 }
 
-// ListStoreOrders lists orders for a store (permission checked in handler)
-func (s *OrderService) ListStoreOrders(storeID uuid.UUID, limit, offset int) ([]models.Order, int64, error) {
-	return s.orderRepo.ListByStore(storeID, limit, offset)
+// ListStoreOrders lists a page of the orders of a store matching a list query (store members or superuser).
+// It returns the cursor of the next page and, in offset mode, the total.
+func (s *OrderService) ListStoreOrders(storeID, userID uuid.UUID, role models.Role, limit, offset int, cursor string, list utils.ListQuery) ([]models.Order, string, int64, error) {
+	if _, err := s.access.Authorize(storeID, userID, role, models.PermissionManageOrders); err != nil {
+		return nil, "", 0, err
+	}
+	page, err := newPage(limit, offset, cursor, 10, 100)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return s.orderRepo.ListByStore(storeID, page, list)
 }
 
-// UpdateOrderStatus updates the status of an order
-func (s *OrderService) UpdateOrderStatus(id uuid.UUID, status models.OrderStatus, userID uuid.UUID, role models.Role) (*models.Order, error) {
+// UpdateOrderStatus moves an order to a new status following the order lifecycle
+func (s *OrderService) UpdateOrderStatus(id uuid.UUID, status models.OrderStatus, reason string, userID uuid.UUID, role models.Role) (*models.Order, error) {
 	if !status.IsValid() {
 		return nil, errors.New("invalid status")
 	}
+	if status == models.OrderStatusCancelled {
+		return nil, ErrUseCancelOrder
+	}
 
 	order, err := s.orderRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Only store owner or superuser can update status
+	// Only store members or superuser can update status; members act as the store
+	actingRole := role
 	if role != models.RoleSuperUser {
-		store, err := s.storeRepo.GetByID(order.StoreID)
-		if err != nil {
+		if _, err := s.access.Authorize(order.StoreID, userID, role, models.PermissionManageOrders); err != nil {
 			return nil, err
 		}
-		if store.OwnerID != userID {
-			return nil, errors.New("permission denied")
-		}
+		actingRole = models.RoleStore
 	}
 
-	err = s.orderRepo.UpdateStatus(id, status)
+	from := order.Status
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		return s.changeStatus(tx, order, status, userID, actingRole, reason)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	order.Status = status
+	s.notifyStatusChange(order, from)
 	return order, nil
 }
+
+// CancelOrderRequest represents the request to cancel an order
+type CancelOrderRequest struct {
+	ReasonCode models.CancellationReason `json:"reason_code" binding:"required"`
+	Note       string                    `json:"note"`
+}
+
+// CancelOrder cancels an order, puts the stock of its items back and records a refund if it was paid.
+// The customer may cancel while the order is pending, the store staff until it is completed.
+func (s *OrderService) CancelOrder(id uuid.UUID, req *CancelOrderRequest, userID uuid.UUID, role models.Role) (*models.Order, error) {
+	if !req.ReasonCode.IsValid() {
+		return nil, errors.New("invalid cancellation reason")
+	}
+	note := strings.TrimSpace(req.Note)
+	if req.ReasonCode == models.CancellationOther && note == "" {
+		return nil, errors.New("a note is required when the cancellation reason is other")
+	}
+
+	order, err := s.orderRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("order not found")
+	}
+
+	actingRole, err := s.cancelRole(order, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := req.ReasonCode.String()
+	if note != "" {
+		reason += ": " + note
+	}
+
+	from := order.Status
+	now := time.Now()
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		// The conditional status change makes sure stock is restored only once
+		if err := s.changeStatus(tx, order, models.OrderStatusCancelled, userID, actingRole, reason); err != nil {
+			return err
+		}
+
+		orderRepo := s.orderRepo.WithTx(tx)
+		if err := orderRepo.SetCancellation(order.ID, req.ReasonCode, note, userID, now); err != nil {
+			return err
+		}
+
+		for _, item := range order.Items {
+			_, err := s.inventory.record(tx, &models.StockMovement{
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+				Type:      models.StockMovementReturn,
+				Quantity:  item.Quantity,
+				OrderID:   &order.ID,
+				ActorID:   &userID,
+				ActorRole: actingRole,
+				Reason:    "order cancelled: " + reason,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := s.printJobRepo.WithTx(tx).CancelByOrder(order.ID); err != nil {
+			return err
+		}
+		if order.ScheduledFor != nil {
+			if err := s.timeSlots.release(tx, order.StoreID, *order.ScheduledFor); err != nil {
+				return err
+			}
+		}
+
+		if order.PaymentStatus != models.PaymentStatusPaid {
+			return nil
+		}
+		err := s.refundRepo.WithTx(tx).Create(&models.Refund{
+			OrderID:     order.ID,
+			Amount:      order.Total,
+			Reason:      req.ReasonCode,
+			Note:        note,
+			RequestedBy: userID,
+		})
+		if err != nil {
+			return err
+		}
+		return orderRepo.SetPaymentStatus(order.ID, models.PaymentStatusRefundPending)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyStatusChange(order, from)
+	return s.orderRepo.GetByID(order.ID)
+}
+
+// cancelRole applies the cancellation rules for the user and the current order status and
+// returns the role the user cancels the order as: superuser, store member or customer
+func (s *OrderService) cancelRole(order *models.Order, userID uuid.UUID, role models.Role) (models.Role, error) {
+	if order.Status == models.OrderStatusCompleted || order.Status == models.OrderStatusCancelled {
+		return "", fmt.Errorf("%w: %s orders cannot be cancelled", ErrInvalidTransition, order.Status)
+	}
+	if role == models.RoleSuperUser {
+		return role, nil
+	}
+
+	allowed, err := s.access.Can(order.StoreID, userID, role, models.PermissionManageOrders)
+	if err != nil {
+		return "", err
+	}
+	if allowed {
+		return models.RoleStore, nil
+	}
+
+	if order.UserID == userID {
+		if order.Status != models.OrderStatusPending {
+			return "", fmt.Errorf("%w: orders can only be cancelled by the customer while pending", ErrInvalidTransition)
+		}
+		return models.RoleClient, nil
+	}
+
+	return "", errors.New("permission denied")
+}
+
+// GetOrderTimeline returns the status history of an order the user is allowed to see
+func (s *OrderService) GetOrderTimeline(id uuid.UUID, userID uuid.UUID, role models.Role) ([]models.OrderStatusEvent, error) {
+	if _, err := s.GetOrder(id, userID, role); err != nil {
+		return nil, err
+	}
+	return s.eventRepo.ListByOrder(id)
+}
+
+// notifyStatusChange informs the registered listeners about a committed status change
+func (s *OrderService) notifyStatusChange(order *models.Order, from models.OrderStatus) {
+	for _, listener := range s.listeners {
+		listener.OrderStatusChanged(order, from)
+	}
+}
+
+// changeStatus validates and applies a status change inside tx and records it in the timeline.
+// On success order.Status holds the new status. Callers must call notifyStatusChange once the
+// transaction is committed.
+func (s *OrderService) changeStatus(tx *gorm.DB, order *models.Order, to models.OrderStatus, actorID uuid.UUID, role models.Role, reason string) error {
+	from := order.Status
+	if err := validateOrderTransition(from, to, role); err != nil {
+		return err
+	}
+
+	ok, err := s.orderRepo.WithTx(tx).TransitionStatus(order.ID, from, to)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrStatusConflict
+	}
+
+	err = s.eventRepo.WithTx(tx).Create(&models.OrderStatusEvent{
+		OrderID:       order.ID,
+		FromStatus:    from,
+		ToStatus:      to,
+		ChangedBy:     actorID,
+		ChangedByRole: role,
+		Reason:        reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	order.Status = to
+	return nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// ErrInvalidTransition is returned when an order cannot move to the requested status
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// ErrStatusConflict is returned when an order changed status while it was being updated
+var ErrStatusConflict = errors.New("order status was changed by another request")
+
+// ErrUseCancelOrder is returned when an order is cancelled through a plain status update.
+// Cancellation restores stock and issues refunds, so it has its own operation.
+var ErrUseCancelOrder = errors.New("orders must be cancelled through the cancel endpoint")
+
+// orderTransitions declares the order lifecycle: for every current status, the statuses
+// it may move to and the roles allowed to perform each move.
+// Completed and cancelled orders are final.
+var orderTransitions = map[models.OrderStatus]map[models.OrderStatus][]models.Role{
+	models.OrderStatusPending: {
+		models.OrderStatusConfirmed: {models.RoleStore, models.RoleSuperUser},
+		models.OrderStatusCancelled: {models.RoleClient, models.RoleStore, models.RoleSuperUser},
+	},
+	models.OrderStatusConfirmed: {
+		models.OrderStatusPreparing: {models.RoleStore, models.RoleSuperUser},
+		models.OrderStatusCancelled: {models.RoleStore, models.RoleSuperUser},
+	},
+	models.OrderStatusPreparing: {
+		models.OrderStatusReady:     {models.RoleStore, models.RoleSuperUser},
+		models.OrderStatusCancelled: {models.RoleStore, models.RoleSuperUser},
+	},
+	models.OrderStatusReady: {
+		models.OrderStatusInDelivery: {models.RoleDelivery, models.RoleSuperUser},
+		models.OrderStatusCompleted:  {models.RoleStore, models.RoleSuperUser},
+		models.OrderStatusCancelled:  {models.RoleStore, models.RoleSuperUser},
+	},
+	models.OrderStatusInDelivery: {
+		models.OrderStatusCompleted: {models.RoleDelivery, models.RoleSuperUser},
+		models.OrderStatusCancelled: {models.RoleStore, models.RoleSuperUser},
+	},
+}
+
+// CanTransitionOrder reports whether a user with the given role may move an order from one status to another
+func CanTransitionOrder(from, to models.OrderStatus, role models.Role) bool {
+	roles, ok := orderTransitions[from][to]
+	if !ok {
+		return false
+	}
+	for _, allowed := range roles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOrderTransition checks a status change against the transition graph
+func validateOrderTransition(from, to models.OrderStatus, role models.Role) error {
+	if _, ok := orderTransitions[from][to]; !ok {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+	if !CanTransitionOrder(from, to, role) {
+		return errors.New("permission denied")
+	}
+	return nil
+}
+
+// OrderStatusListener is notified after an order status change has been committed
+type OrderStatusListener interface {
+	OrderStatusChanged(order *models.Order, from models.OrderStatus)
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// newPage validates the paging parameters of a list, applying its default and maximum limit.
+// A cursor returned by a previous page continues the list from there and replaces the offset.
+func newPage(limit, offset int, cursor string, defaultLimit, maxLimit int) (repository.Page, error) {
+	// Set default limit if not provided or invalid
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	// Cap maximum limit
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	// Ensure offset is non-negative
+	if offset < 0 {
+		offset = 0
+	}
+
+	page := repository.Page{Limit: limit, Offset: offset}
+	if cursor != "" {
+		after, err := repository.DecodeCursor(cursor)
+		if err != nil {
+			return repository.Page{}, err
+		}
+		page.After = after
+		page.Offset = 0
+	}
+	return page, nil
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ruranjo/unientrega/internal/config"
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// Delivery fee bases, stored on the order to explain how the fee was computed
+const (
+	FeeBasisPickup   = "pickup"
+	FeeBasisZone     = "zone"
+	FeeBasisDistance = "distance"
+	FeeBasisBase     = "base"
+	FeeBasisFree     = "free_delivery"
+)
+
+// FeeBreakdown is the price breakdown of an order
+type FeeBreakdown struct {
+	Subtotal            models.Money `json:"subtotal"`
+	DeliveryFee         models.Money `json:"delivery_fee"`
+	DeliveryFeeBasis    string       `json:"delivery_fee_basis"`
+	SmallOrderSurcharge models.Money `json:"small_order_surcharge"`
+	Total               models.Money `json:"total"`
+}
+
+// DeliveryQuote holds what the pricing rules need to know about an order
+type DeliveryQuote struct {
+	Subtotal      models.Money
+	PickupAtStore bool
+	Store         *models.Store
+	StoreLocation *models.CampusLocation // Optional catalog location of the store
+	Destination   *models.CampusLocation // Nil for pickup orders
+}
+
+// PricingService computes delivery fees and surcharges
+type PricingService struct {
+	cfg config.PricingConfig
+}
+
+// NewPricingService creates a new pricing service
+func NewPricingService(cfg config.PricingConfig) *PricingService {
+	return &PricingService{
+		cfg: cfg,
+	}
+}
+
+// Quote computes the fee breakdown of an order.
+// Delivery fees come from the zone matrix, then distance bands, then the base fee.
+// Orders above the free delivery threshold pay no delivery fee, and delivery orders
+// below the small order threshold pay a surcharge. Configured fees and thresholds are in
+// the application currency, so subtotals in any other currency are rejected.
+func (s *PricingService) Quote(quote DeliveryQuote) (FeeBreakdown, error) {
+	currency := quote.Subtotal.Currency
+	if currency != models.DefaultCurrency {
+		return FeeBreakdown{}, fmt.Errorf("orders must be priced in %s, got %s", models.DefaultCurrency, currency)
+	}
+
+	breakdown := FeeBreakdown{
+		Subtotal:            models.NewMoney(quote.Subtotal.Amount, currency),
+		DeliveryFee:         models.NewMoney(0, currency),
+		SmallOrderSurcharge: models.NewMoney(0, currency),
+	}
+
+	if quote.PickupAtStore {
+		breakdown.DeliveryFeeBasis = FeeBasisPickup
+		breakdown.Total = breakdown.Subtotal
+		return breakdown, nil
+	}
+
+	fee, basis := s.deliveryFee(quote)
+	breakdown.DeliveryFee = models.NewMoney(fee, currency)
+	breakdown.DeliveryFeeBasis = basis
+
+	if s.cfg.FreeDeliveryThreshold > 0 && breakdown.Subtotal.Amount >= s.cfg.FreeDeliveryThreshold {
+		breakdown.DeliveryFee = models.NewMoney(0, currency)
+		breakdown.DeliveryFeeBasis = FeeBasisFree
+	}
+
+	if s.cfg.SmallOrderThreshold > 0 && breakdown.Subtotal.Amount < s.cfg.SmallOrderThreshold {
+		breakdown.SmallOrderSurcharge = models.NewMoney(s.cfg.SmallOrderSurcharge, currency)
+	}
+
+	breakdown.Total = breakdown.Subtotal.Add(breakdown.DeliveryFee).Add(breakdown.SmallOrderSurcharge)
+	return breakdown, nil
+}
+
+// deliveryFee returns the fee in minor units between the store and the destination
+// and the rule that produced it
+func (s *PricingService) deliveryFee(quote DeliveryQuote) (int64, string) {
+	storeZone := ""
+	if quote.Store != nil {
+		storeZone = quote.Store.Zone
+	}
+	if storeZone == "" && quote.StoreLocation != nil {
+		storeZone = quote.StoreLocation.Zone
+	}
+
+	if storeZone != "" && quote.Destination != nil && quote.Destination.Zone != "" {
+		if fee, ok := s.cfg.ZoneFees[config.ZonePairKey(storeZone, quote.Destination.Zone)]; ok {
+			return fee, FeeBasisZone
+		}
+	}
+
+	if len(s.cfg.DistanceBands) > 0 && hasCoordinates(quote.StoreLocation) && hasCoordinates(quote.Destination) {
+		meters := distanceMeters(quote.StoreLocation, quote.Destination)
+		for _, band := range s.cfg.DistanceBands {
+			if meters <= band.MaxMeters {
+				return band.Fee, FeeBasisDistance
+			}
+		}
+		// Farther than every band: charge the widest band
+		return s.cfg.DistanceBands[len(s.cfg.DistanceBands)-1].Fee, FeeBasisDistance
+	}
+
+	return s.cfg.DeliveryBaseFee, FeeBasisBase
+}
+
+// hasCoordinates reports whether a location has latitude and longitude
+func hasCoordinates(location *models.CampusLocation) bool {
+	return location != nil && location.Latitude != nil && location.Longitude != nil
+}
+
+// distanceMeters returns the great-circle distance between two locations
+func distanceMeters(a, b *models.CampusLocation) float64 {
+	const earthRadius = 6371000.0
+	lat1 := *a.Latitude * math.Pi / 180
+	lat2 := *b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (*b.Longitude - *a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+	"github.com/ruranjo/unientrega/internal/storage"
+	"github.com/ruranjo/unientrega/internal/utils"
+)
+
+// ErrPrintJobOrdered is returned when a print job that is part of an order is changed
+var ErrPrintJobOrdered = errors.New("print job was already ordered")
+
+// ErrPrintingNotOffered is returned when a store has no print pricing configured
+var ErrPrintingNotOffered = errors.New("store does not offer printing")
+
+// maxPrintCopies limits the copies of a single print job
+const maxPrintCopies = 500
+
+// defaultTurnaroundMinutes is the time copy centers promise for a print job when not configured
+const defaultTurnaroundMinutes = 60
+
+// PrintJobService handles document uploads and print job pricing
+type PrintJobService struct {
+	printJobRepo  *repository.PrintJobRepository
+	storeRepo     *repository.StoreRepository
+	storage       storage.Storage
+	maxUploadSize int64
+	access        *StoreAccess
+}
+
+// NewPrintJobService creates a new print job service
+func NewPrintJobService(
+	printJobRepo *repository.PrintJobRepository,
+	storeRepo *repository.StoreRepository,
+	fileStorage storage.Storage,
+	maxUploadSize int64,
+	access *StoreAccess,
+) *PrintJobService {
+	return &PrintJobService{
+		printJobRepo:  printJobRepo,
+		storeRepo:     storeRepo,
+		storage:       fileStorage,
+		maxUploadSize: maxUploadSize,
+		access:        access,
+	}
+}
+
+// PrintOptions represents how a document should be printed
+type PrintOptions struct {
+	ColorMode  models.PrintColorMode `form:"color_mode" json:"color_mode"` // bw (default) or color
+	Duplex     bool                  `form:"duplex" json:"duplex"`
+	Copies     int                   `form:"copies" json:"copies"`           // Defaults to 1
+	PageRanges string                `form:"page_ranges" json:"page_ranges"` // e.g. "1-3,5,8-"; empty prints every page
+	Binding    models.PrintBinding   `form:"binding" json:"binding"`         // none (default), staple, spiral or thermal
+	Notes      string                `form:"notes" json:"notes"`
+}
+
+// PrintPricingRequest represents the printing rates of a store
+type PrintPricingRequest struct {
+	BWPagePrice         models.Money `json:"bw_page_price" binding:"required"`
+	ColorPagePrice      models.Money `json:"color_page_price" binding:"required"`
+	StapleBindingPrice  models.Money `json:"staple_binding_price"`
+	SpiralBindingPrice  models.Money `json:"spiral_binding_price"`
+	ThermalBindingPrice models.Money `json:"thermal_binding_price"`
+	TurnaroundMinutes   int          `json:"turnaround_minutes"` // Defaults to 60
+}
+
+// GetPricing returns the printing rates of a store
+func (s *PrintJobService) GetPricing(storeID uuid.UUID) (*models.PrintPricing, error) {
+	pricing, err := s.printJobRepo.GetPricing(storeID)
+	if err != nil {
+		return nil, ErrPrintingNotOffered
+	}
+	return pricing, nil
+}
+
+// SetPricing configures the printing rates of a store (store owners and managers, or superuser)
+func (s *PrintJobService) SetPricing(storeID, userID uuid.UUID, role models.Role, req *PrintPricingRequest) (*models.PrintPricing, error) {
+	if _, err := s.access.Authorize(storeID, userID, role, models.PermissionManageProducts); err != nil {
+		return nil, err
+	}
+
+	pricing := &models.PrintPricing{
+		StoreID:             storeID,
+		BWPagePrice:         req.BWPagePrice,
+		ColorPagePrice:      req.ColorPagePrice,
+		StapleBindingPrice:  req.StapleBindingPrice,
+		SpiralBindingPrice:  req.SpiralBindingPrice,
+		ThermalBindingPrice: req.ThermalBindingPrice,
+		TurnaroundMinutes:   req.TurnaroundMinutes,
+	}
+	if pricing.TurnaroundMinutes == 0 {
+		pricing.TurnaroundMinutes = defaultTurnaroundMinutes
+	}
+	if pricing.TurnaroundMinutes < 0 {
+		return nil, errors.New("turnaround minutes must be positive")
+	}
+	prices := []*models.Money{
+		&pricing.BWPagePrice, &pricing.ColorPagePrice,
+		&pricing.StapleBindingPrice, &pricing.SpiralBindingPrice, &pricing.ThermalBindingPrice,
+	}
+	for _, price := range prices {
+		if err := validatePrice(price); err != nil {
+			return nil, err
+		}
+		if !price.SameCurrency(pricing.BWPagePrice) {
+			return nil, errors.New("print prices must use the same currency")
+		}
+	}
+
+	if err := s.printJobRepo.SavePricing(pricing); err != nil {
+		return nil, err
+	}
+	return s.printJobRepo.GetPricing(storeID)
+}
+
+// CreatePrintJob stores an uploaded PDF document and prices it with the store rates
+func (s *PrintJobService) CreatePrintJob(userID, storeID uuid.UUID, fileName string, file io.Reader, options *PrintOptions) (*models.PrintJob, error) {
+	store, err := s.storeRepo.GetByID(storeID)
+	if err != nil {
+		return nil, errors.New("store not found")
+	}
+	if !store.IsActive {
+		return nil, errors.New("store is not active")
+	}
+	pricing, err := s.GetPricing(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(filepath.Ext(fileName), ".pdf") {
+		return nil, errors.New("only PDF documents can be printed")
+	}
+
+	// Read one byte more than allowed to detect oversized uploads
+	data, err := io.ReadAll(io.LimitReader(file, s.maxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxUploadSize {
+		return nil, fmt.Errorf("document is larger than %d MB", s.maxUploadSize>>20)
+	}
+
+	pageCount, err := utils.CountPDFPages(data)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.PrintJob{
+		ID:        uuid.New(),
+		UserID:    userID,
+		StoreID:   storeID,
+		Status:    models.PrintJobStatusUploaded,
+		FileName:  filepath.Base(fileName),
+		FileSize:  int64(len(data)),
+		PageCount: pageCount,
+	}
+	job.StorageKey = "print-jobs/" + job.ID.String() + ".pdf"
+
+	if err := applyPrintOptions(job, options); err != nil {
+		return nil, err
+	}
+	if err := quotePrintJob(job, pricing); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.storage.Save(job.StorageKey, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := s.printJobRepo.Create(job); err != nil {
+		if delErr := s.storage.Delete(job.StorageKey); delErr != nil {
+			log.Printf("print jobs: failed to remove orphaned document %s: %v", job.StorageKey, delErr)
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetPrintJob returns a print job visible to the user: its owner, the store staff or a superuser
+func (s *PrintJobService) GetPrintJob(id, userID uuid.UUID, role models.Role) (*models.PrintJob, error) {
+	job, err := s.printJobRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID == userID {
+		return job, nil
+	}
+
+	allowed, err := s.access.Can(job.StoreID, userID, role, models.PermissionManagePrinting)
+	if err != nil {
+		return nil, err
+	}
+	if allowed {
+		return job, nil
+	}
+
+	return nil, errors.New("permission denied")
+}
+
+// ListPrintJobs returns a page of the print jobs of a user and the cursor of the next page
+func (s *PrintJobService) ListPrintJobs(userID uuid.UUID, limit, offset int, cursor string) ([]models.PrintJob, string, int64, error) {
+	page, err := newPage(limit, offset, cursor, 10, 100)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return s.printJobRepo.ListByUser(userID, page)
+}
+
+// UpdatePrintJob changes the options of a print job that was not ordered yet and prices it again
+func (s *PrintJobService) UpdatePrintJob(id, userID uuid.UUID, options *PrintOptions) (*models.PrintJob, error) {
+	job, err := s.getOwnUploadedJob(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pricing, err := s.GetPricing(job.StoreID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPrintOptions(job, options); err != nil {
+		return nil, err
+	}
+	if err := quotePrintJob(job, pricing); err != nil {
+		return nil, err
+	}
+
+	if err := s.printJobRepo.Update(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// DeletePrintJob removes a print job that was not ordered yet together with its document
+func (s *PrintJobService) DeletePrintJob(id, userID uuid.UUID) error {
+	job, err := s.getOwnUploadedJob(id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.printJobRepo.Delete(job.ID); err != nil {
+		return err
+	}
+	if err := s.storage.Delete(job.StorageKey); err != nil {
+		log.Printf("print jobs: failed to remove document %s: %v", job.StorageKey, err)
+	}
+	return nil
+}
+
+// getOwnUploadedJob loads a print job of the user that can still be changed
+func (s *PrintJobService) getOwnUploadedJob(id, userID uuid.UUID) (*models.PrintJob, error) {
+	job, err := s.printJobRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, errors.New("permission denied")
+	}
+	if job.OrderID != nil || job.Status != models.PrintJobStatusUploaded {
+		return nil, ErrPrintJobOrdered
+	}
+	return job, nil
+}
+
+// applyPrintOptions validates print options, fills in defaults and sets them on the job
+func applyPrintOptions(job *models.PrintJob, options *PrintOptions) error {
+	colorMode := options.ColorMode
+	if colorMode == "" {
+		colorMode = models.PrintColorBW
+	}
+	if !colorMode.IsValid() {
+		return errors.New("invalid color mode")
+	}
+
+	binding := options.Binding
+	if binding == "" {
+		binding = models.PrintBindingNone
+	}
+	if !binding.IsValid() {
+		return errors.New("invalid binding")
+	}
+
+	copies := options.Copies
+	if copies == 0 {
+		copies = 1
+	}
+	if copies < 1 || copies > maxPrintCopies {
+		return fmt.Errorf("copies must be between 1 and %d", maxPrintCopies)
+	}
+
+	ranges := strings.ReplaceAll(strings.TrimSpace(options.PageRanges), " ", "")
+	selected, err := countSelectedPages(ranges, job.PageCount)
+	if err != nil {
+		return err
+	}
+
+	job.ColorMode = colorMode
+	job.Duplex = options.Duplex
+	job.Copies = copies
+	job.PageRanges = ranges
+	job.Binding = binding
+	job.Notes = strings.TrimSpace(options.Notes)
+	job.SelectedPages = selected
+	return nil
+}
+
+// quotePrintJob prices a print job: the page rate for every selected page of every copy,
+// plus the binding of every copy
+func quotePrintJob(job *models.PrintJob, pricing *models.PrintPricing) error {
+	pageRate := pricing.BWPagePrice
+	if job.ColorMode == models.PrintColorColor {
+		pageRate = pricing.ColorPagePrice
+	}
+	binding := pricing.BindingPrice(job.Binding)
+	if !binding.SameCurrency(pageRate) {
+		return errors.New("print prices must use the same currency")
+	}
+
+	job.Price = pageRate.Multiply(job.SelectedPages * job.Copies).Add(binding.Multiply(job.Copies))
+	return nil
+}
+
+// countSelectedPages returns how many distinct pages a page range selects, e.g. "1-3,5,8-"
+// selects pages 1, 2, 3, 5 and 8 to the end. An empty range selects every page.
+func countSelectedPages(ranges string, pageCount int) (int, error) {
+	if ranges == "" {
+		return pageCount, nil
+	}
+
+	selected := make([]bool, pageCount+1)
+	for _, part := range strings.Split(ranges, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return 0, fmt.Errorf("invalid page range %q", part)
+		}
+		last := first
+		if len(bounds) == 2 {
+			if bounds[1] == "" {
+				last = pageCount
+			} else if last, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, fmt.Errorf("invalid page range %q", part)
+			}
+		}
+		if first < 1 || last > pageCount || first > last {
+			return 0, fmt.Errorf("page range %q is outside the document (1-%d)", part, pageCount)
+		}
+		for page := first; page <= last; page++ {
+			selected[page] = true
+		}
+	}
+
+	count := 0
+	for _, isSelected := range selected {
+		if isSelected {
+			count++
+		}
+	}
+	return count, nil
+}
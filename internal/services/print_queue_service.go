@@ -0,0 +1,297 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+	"github.com/ruranjo/unientrega/internal/storage"
+	"github.com/ruranjo/unientrega/internal/utils"
+)
+
+// ErrPrintJobConflict is returned when a print job action does not apply to the current job status
+var ErrPrintJobConflict = errors.New("print job status does not allow this action")
+
+// ErrInvalidDownloadSignature is returned for expired or tampered download URLs
+var ErrInvalidDownloadSignature = errors.New("download link is invalid or has expired")
+
+// printQueueStatuses are the statuses shown in the copy center queue by default
+var printQueueStatuses = []models.PrintJobStatus{
+	models.PrintJobStatusQueued,
+	models.PrintJobStatusPrinting,
+	models.PrintJobStatusProblem,
+}
+
+// PrintQueueService lets copy center staff work through ordered print jobs.
+// Print job progress moves the parent order forward: starting the first job puts the
+// order in preparation and printing the last job of an order without other items makes it ready.
+type PrintQueueService struct {
+	txManager      *repository.TxManager
+	printJobRepo   *repository.PrintJobRepository
+	orderRepo      *repository.OrderRepository
+	storeRepo      *repository.StoreRepository
+	orderService   *OrderService
+	storage        storage.Storage
+	signingSecret  string
+	downloadURLTTL time.Duration
+	access         *StoreAccess
+}
+
+// NewPrintQueueService creates a new print queue service
+func NewPrintQueueService(
+	txManager *repository.TxManager,
+	printJobRepo *repository.PrintJobRepository,
+	orderRepo *repository.OrderRepository,
+	storeRepo *repository.StoreRepository,
+	orderService *OrderService,
+	fileStorage storage.Storage,
+	signingSecret string,
+	downloadURLTTL time.Duration,
+	access *StoreAccess,
+) *PrintQueueService {
+	return &PrintQueueService{
+		txManager:      txManager,
+		printJobRepo:   printJobRepo,
+		orderRepo:      orderRepo,
+		storeRepo:      storeRepo,
+		orderService:   orderService,
+		storage:        fileStorage,
+		signingSecret:  signingSecret,
+		downloadURLTTL: downloadURLTTL,
+		access:         access,
+	}
+}
+
+// PrintProblemRequest represents a problem reported for a print job
+type PrintProblemRequest struct {
+	ReasonCode models.PrintProblemReason `json:"reason_code" binding:"required"`
+	Note       string                    `json:"note"`
+}
+
+// DownloadURL is a short-lived link to the original document of a print job
+type DownloadURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ListQueue returns the ordered print jobs of a store, the earliest promised first.
+// Without statuses the queue shows queued, printing and problem jobs.
+func (s *PrintQueueService) ListQueue(storeID, userID uuid.UUID, role models.Role, statuses []models.PrintJobStatus, limit, offset int) ([]models.PrintJob, int64, error) {
+	if err := s.checkStoreAccess(storeID, userID, role); err != nil {
+		return nil, 0, err
+	}
+
+	for _, status := range statuses {
+		if !status.IsValid() || status == models.PrintJobStatusUploaded {
+			return nil, 0, errors.New("invalid print job status: " + status.String())
+		}
+	}
+	if len(statuses) == 0 {
+		statuses = printQueueStatuses
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.printJobRepo.ListQueue(storeID, statuses, limit, offset)
+}
+
+// StartJob marks a queued job, or one whose problem was solved, as being printed
+func (s *PrintQueueService) StartJob(storeID, jobID, userID uuid.UUID, role models.Role) (*models.PrintJob, error) {
+	now := time.Now()
+	return s.transition(storeID, jobID, userID, role,
+		[]models.PrintJobStatus{models.PrintJobStatusQueued, models.PrintJobStatusProblem},
+		models.PrintJobStatusPrinting,
+		map[string]interface{}{"started_at": now, "problem_reason": "", "problem_note": ""},
+	)
+}
+
+// MarkPrinted marks a job as printed
+func (s *PrintQueueService) MarkPrinted(storeID, jobID, userID uuid.UUID, role models.Role) (*models.PrintJob, error) {
+	now := time.Now()
+	return s.transition(storeID, jobID, userID, role,
+		[]models.PrintJobStatus{models.PrintJobStatusPrinting},
+		models.PrintJobStatusPrinted,
+		map[string]interface{}{"printed_at": now},
+	)
+}
+
+// ReportProblem flags a job that cannot be printed, e.g. a corrupt file or a printer out of toner
+func (s *PrintQueueService) ReportProblem(storeID, jobID, userID uuid.UUID, role models.Role, req *PrintProblemRequest) (*models.PrintJob, error) {
+	if !req.ReasonCode.IsValid() {
+		return nil, errors.New("invalid problem reason")
+	}
+	note := strings.TrimSpace(req.Note)
+	if req.ReasonCode == models.PrintProblemOther && note == "" {
+		return nil, errors.New("a note is required when the problem reason is other")
+	}
+
+	return s.transition(storeID, jobID, userID, role,
+		[]models.PrintJobStatus{models.PrintJobStatusQueued, models.PrintJobStatusPrinting},
+		models.PrintJobStatusProblem,
+		map[string]interface{}{"problem_reason": req.ReasonCode, "problem_note": note},
+	)
+}
+
+// CreateDownloadURL returns a signed link to the original document of a queued job
+func (s *PrintQueueService) CreateDownloadURL(storeID, jobID, userID uuid.UUID, role models.Role) (*DownloadURL, error) {
+	job, err := s.getStoreJob(storeID, jobID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.downloadURLTTL).Truncate(time.Second)
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", utils.SignResource(s.signingSecret, downloadResource(job.ID), expiresAt))
+
+	return &DownloadURL{
+		URL:       "/api/v1/print-jobs/" + job.ID.String() + "/download?" + query.Encode(),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// OpenDocument checks a signed download link and returns the document it grants access to.
+// The caller must close the returned reader.
+func (s *PrintQueueService) OpenDocument(jobID uuid.UUID, expires, signature string) (*models.PrintJob, io.ReadCloser, error) {
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !utils.VerifyResourceSignature(s.signingSecret, downloadResource(jobID), expiresUnix, signature) {
+		return nil, nil, ErrInvalidDownloadSignature
+	}
+
+	job, err := s.printJobRepo.GetByID(jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	document, err := s.storage.Open(job.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, errors.New("print job document not found")
+		}
+		return nil, nil, err
+	}
+	return job, document, nil
+}
+
+// transition changes the status of a store print job and moves the parent order forward
+func (s *PrintQueueService) transition(storeID, jobID, userID uuid.UUID, role models.Role, from []models.PrintJobStatus, to models.PrintJobStatus, fields map[string]interface{}) (*models.PrintJob, error) {
+	job, err := s.getStoreJob(storeID, jobID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	var order *models.Order
+	var orderFrom models.OrderStatus
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		ok, err := s.printJobRepo.WithTx(tx).TransitionStatus(job.ID, from, to, fields)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s -> %s", ErrPrintJobConflict, job.Status, to)
+		}
+
+		order, orderFrom, err = s.syncOrder(tx, *job.OrderID, to, userID, role)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if order != nil && order.Status != orderFrom {
+		s.orderService.notifyStatusChange(order, orderFrom)
+	}
+	return s.printJobRepo.GetByID(job.ID)
+}
+
+// syncOrder moves the order of a print job forward after the job changed to status.
+// It returns the order and its previous status, or nil if the order was not loaded.
+func (s *PrintQueueService) syncOrder(tx *gorm.DB, orderID uuid.UUID, status models.PrintJobStatus, userID uuid.UUID, role models.Role) (*models.Order, models.OrderStatus, error) {
+	if status != models.PrintJobStatusPrinting && status != models.PrintJobStatusPrinted {
+		return nil, "", nil
+	}
+
+	order, err := s.orderRepo.WithTx(tx).GetByID(orderID)
+	if err != nil {
+		return nil, "", err
+	}
+	from := order.Status
+
+	switch status {
+	case models.PrintJobStatusPrinting:
+		// Printing means the copy center accepted the order
+		if order.Status == models.OrderStatusPending {
+			if err := s.orderService.changeStatus(tx, order, models.OrderStatusConfirmed, userID, role, "print job started"); err != nil {
+				return nil, "", err
+			}
+		}
+		if order.Status == models.OrderStatusConfirmed {
+			if err := s.orderService.changeStatus(tx, order, models.OrderStatusPreparing, userID, role, "print job started"); err != nil {
+				return nil, "", err
+			}
+		}
+
+	case models.PrintJobStatusPrinted:
+		// Orders with other items are marked ready by the store once everything is packed
+		if order.Status != models.OrderStatusPreparing || len(order.Items) > 0 {
+			return order, from, nil
+		}
+		jobs, err := s.printJobRepo.WithTx(tx).ListByOrder(order.ID)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, job := range jobs {
+			if job.Status != models.PrintJobStatusPrinted && job.Status != models.PrintJobStatusCancelled {
+				return order, from, nil
+			}
+		}
+		if err := s.orderService.changeStatus(tx, order, models.OrderStatusReady, userID, role, "all print jobs printed"); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return order, from, nil
+}
+
+// getStoreJob loads an ordered print job of a store the user may manage
+func (s *PrintQueueService) getStoreJob(storeID, jobID, userID uuid.UUID, role models.Role) (*models.PrintJob, error) {
+	if err := s.checkStoreAccess(storeID, userID, role); err != nil {
+		return nil, err
+	}
+
+	job, err := s.printJobRepo.GetByID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.StoreID != storeID || job.OrderID == nil {
+		return nil, errors.New("print job not found")
+	}
+	return job, nil
+}
+
+// checkStoreAccess checks that the user works the print queue of the store (any store member or superuser)
+func (s *PrintQueueService) checkStoreAccess(storeID, userID uuid.UUID, role models.Role) error {
+	_, err := s.access.Authorize(storeID, userID, role, models.PermissionManagePrinting)
+	return err
+}
+
+// downloadResource is the signed resource name of a print job document
+func downloadResource(jobID uuid.UUID) string {
+	return "print-job-document:" + jobID.String()
+}
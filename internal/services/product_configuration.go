@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+// configurationKey identifies an order line by product, variant and chosen modifiers,
+// so that repeated lines with the same configuration can be merged
+func configurationKey(item OrderItemRequest) string {
+	parts := make([]string, 0, len(item.ModifierIDs)+2)
+	parts = append(parts, item.ProductID.String())
+	if item.VariantID != nil {
+		parts = append(parts, item.VariantID.String())
+	} else {
+		parts = append(parts, "")
+	}
+	modifiers := make([]string, 0, len(item.ModifierIDs))
+	for _, id := range item.ModifierIDs {
+		modifiers = append(modifiers, id.String())
+	}
+	sort.Strings(modifiers)
+	return strings.Join(append(parts, modifiers...), "/")
+}
+
+// optionChoice is a modifier together with its option group
+type optionChoice struct {
+	group    *models.ProductOptionGroup
+	modifier *models.ProductModifier
+}
+
+// configureItem builds the order item of a line: it resolves the chosen variant, checks the
+// chosen modifiers against the option groups of the product and prices one unit.
+func configureItem(product *models.Product, variants []*models.ProductVariant, groups []models.ProductOptionGroup, line OrderItemRequest) (*models.OrderItem, error) {
+	item := &models.OrderItem{
+		ProductID: product.ID,
+		SKU:       product.SKU,
+		Quantity:  line.Quantity,
+		Price:     product.Price,
+	}
+
+	if line.VariantID != nil {
+		var variant *models.ProductVariant
+		for _, candidate := range variants {
+			if candidate.ID == *line.VariantID {
+				variant = candidate
+				break
+			}
+		}
+		if variant == nil {
+			return nil, errors.New("variant not found for product: " + product.Name)
+		}
+		if !variant.IsActive {
+			return nil, fmt.Errorf("variant is not active: %s (%s)", product.Name, variant.Name)
+		}
+		item.VariantID = &variant.ID
+		item.VariantName = variant.Name
+		item.SKU = variant.SKU
+		item.Price = variant.Price
+	} else {
+		for _, variant := range variants {
+			if variant.IsActive {
+				return nil, errors.New("a variant must be chosen for product: " + product.Name)
+			}
+		}
+	}
+
+	choices := make(map[uuid.UUID]optionChoice)
+	for i := range groups {
+		for j := range groups[i].Modifiers {
+			choices[groups[i].Modifiers[j].ID] = optionChoice{group: &groups[i], modifier: &groups[i].Modifiers[j]}
+		}
+	}
+
+	selected := make(map[uuid.UUID]int, len(groups))
+	seen := make(map[uuid.UUID]bool, len(line.ModifierIDs))
+	for _, id := range line.ModifierIDs {
+		if seen[id] {
+			return nil, errors.New("modifier chosen more than once for product: " + product.Name)
+		}
+		seen[id] = true
+
+		choice, ok := choices[id]
+		if !ok || !choice.modifier.IsActive {
+			return nil, errors.New("modifier not available for product: " + product.Name)
+		}
+		if !choice.modifier.PriceDelta.SameCurrency(item.Price) {
+			return nil, errors.New("modifier is priced in a different currency: " + choice.modifier.Name)
+		}
+
+		selected[choice.group.ID]++
+		item.Price = item.Price.Add(choice.modifier.PriceDelta)
+		item.Modifiers = append(item.Modifiers, models.OrderItemModifier{
+			ModifierID: choice.modifier.ID,
+			GroupName:  choice.group.Name,
+			Name:       choice.modifier.Name,
+			PriceDelta: choice.modifier.PriceDelta,
+		})
+	}
+
+	for _, group := range groups {
+		count := selected[group.ID]
+		if (group.Required && count == 0) || count < group.MinSelections {
+			return nil, fmt.Errorf("choose at least %d of %s for product: %s", max(group.MinSelections, 1), group.Name, product.Name)
+		}
+		if group.MaxSelections > 0 && count > group.MaxSelections {
+			return nil, fmt.Errorf("choose at most %d of %s for product: %s", group.MaxSelections, group.Name, product.Name)
+		}
+	}
+
+	if item.Price.IsNegative() {
+		return nil, errors.New("chosen modifiers make the price negative for product: " + product.Name)
+	}
+	return item, nil
+}
@@ -0,0 +1,455 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+	"github.com/ruranjo/unientrega/internal/utils"
+)
+
+const (
+	// MaxImportFileSize is the largest accepted import file
+	MaxImportFileSize = 10 << 20
+	// maxImportRows is the largest number of products in one import
+	maxImportRows = 5000
+)
+
+// productColumns are the spreadsheet columns of the product catalog, in export order
+var productColumns = []string{
+	"sku", "name", "description", "tags", "category", "price", "currency",
+	"stock", "image_url", "is_active", "reorder_threshold", "auto_hide",
+}
+
+// tagSeparator separates the tags of a product in a spreadsheet cell
+const tagSeparator = ";"
+
+// requiredProductColumns must be present in every import file
+var requiredProductColumns = []string{"sku", "name", "category", "price"}
+
+// ProductImportService imports and exports the catalog of a store as CSV or XLSX spreadsheets.
+// Imports upsert products by SKU and run every row through the product validation rules.
+type ProductImportService struct {
+	txManager   *repository.TxManager
+	productRepo *repository.ProductRepository
+	optionRepo  *repository.ProductOptionRepository
+	inventory   *InventoryService
+	access      *StoreAccess
+	products    *ProductService
+}
+
+// NewProductImportService creates a new product import service
+func NewProductImportService(
+	txManager *repository.TxManager,
+	productRepo *repository.ProductRepository,
+	optionRepo *repository.ProductOptionRepository,
+	inventory *InventoryService,
+	access *StoreAccess,
+	products *ProductService,
+) *ProductImportService {
+	return &ProductImportService{
+		txManager:   txManager,
+		productRepo: productRepo,
+		optionRepo:  optionRepo,
+		inventory:   inventory,
+		access:      access,
+		products:    products,
+	}
+}
+
+// ImportRowError describes why a row of an import file was rejected
+type ImportRowError struct {
+	Row   int    `json:"row"` // Line of the file, the header is line 1
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportResult reports the outcome of a product import.
+// Rows are only imported when every row is valid; a dry run never imports.
+type ImportResult struct {
+	DryRun  bool             `json:"dry_run"`
+	Applied bool             `json:"applied"`
+	Rows    int              `json:"rows"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+// importRow is a validated row of an import file
+type importRow struct {
+	product *models.Product
+	stock   *int // Stock to reach, nil keeps the current stock
+	isNew   bool
+}
+
+// ImportProducts creates or updates the products of a store from a CSV or XLSX file, matching
+// existing products by SKU. Stock changes are recorded in the inventory ledger.
+func (s *ProductImportService) ImportProducts(storeID, userID uuid.UUID, role models.Role, fileName string, file io.Reader, dryRun bool) (*ImportResult, error) {
+	if err := s.products.authorize(storeID, userID, role, models.AuditProductImport, nil); err != nil {
+		return nil, err
+	}
+
+	format, err := utils.SpreadsheetFormatOf(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read one byte more than allowed to detect oversized uploads
+	data, err := io.ReadAll(io.LimitReader(file, MaxImportFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImportFileSize {
+		return nil, fmt.Errorf("import file is larger than %d MB", MaxImportFileSize>>20)
+	}
+
+	records, err := utils.ReadSpreadsheet(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("import file is empty")
+	}
+	columns, err := parseProductHeader(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip blank lines, remembering the line number of every row
+	var lines []int
+	for i, record := range records[1:] {
+		if !isBlankRecord(record) {
+			lines = append(lines, i+2)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("import file has no product rows")
+	}
+	if len(lines) > maxImportRows {
+		return nil, fmt.Errorf("import file has more than %d product rows", maxImportRows)
+	}
+
+	// Look up the SKUs of the file at once
+	skus := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if sku := cell(records[line-1], columns, "sku"); sku != "" {
+			skus = append(skus, sku)
+		}
+	}
+	existing, err := s.productRepo.GetBySKUs(skus)
+	if err != nil {
+		return nil, err
+	}
+	bySKU := make(map[string]*models.Product, len(existing))
+	for _, product := range existing {
+		bySKU[product.SKU] = product
+	}
+	variantSKUs, err := s.optionRepo.ListUsedVariantSKUs(skus)
+	if err != nil {
+		return nil, err
+	}
+	usedByVariant := make(map[string]bool, len(variantSKUs))
+	for _, sku := range variantSKUs {
+		usedByVariant[sku] = true
+	}
+
+	result := &ImportResult{DryRun: dryRun, Rows: len(lines), Errors: []ImportRowError{}}
+	rows := make([]importRow, 0, len(lines))
+	seen := make(map[string]int, len(lines))
+	for _, line := range lines {
+		record := records[line-1]
+		sku := cell(record, columns, "sku")
+		rowError := func(message string) {
+			result.Errors = append(result.Errors, ImportRowError{Row: line, SKU: sku, Error: message})
+		}
+
+		if sku == "" {
+			rowError("SKU is required")
+			continue
+		}
+		if first, ok := seen[sku]; ok {
+			rowError(fmt.Sprintf("SKU is repeated, first seen on row %d", first))
+			continue
+		}
+		seen[sku] = line
+		if usedByVariant[sku] {
+			rowError("SKU is used by a product variant")
+			continue
+		}
+
+		row := importRow{isNew: true}
+		if current, ok := bySKU[sku]; ok {
+			switch {
+			case current.DeletedAt.Valid:
+				rowError("SKU belongs to a deleted product")
+				continue
+			case current.StoreID != storeID:
+				rowError("SKU is used by a product of another store")
+				continue
+			}
+			product := *current
+			row.product = &product
+			row.isNew = false
+		} else {
+			row.product = &models.Product{StoreID: storeID, SKU: sku, IsActive: true}
+		}
+
+		stock, err := applyProductRecord(row.product, record, columns)
+		if err != nil {
+			rowError(err.Error())
+			continue
+		}
+		row.stock = stock
+		if stock != nil && row.isNew {
+			row.product.Stock = *stock
+		}
+		if err := validateProduct(row.product); err != nil {
+			rowError(err.Error())
+			continue
+		}
+
+		if row.isNew {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		rows = append(rows, row)
+	}
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	if err := s.applyImport(rows, userID, role); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// applyImport stores the rows of a valid import in a single transaction
+func (s *ProductImportService) applyImport(rows []importRow, userID uuid.UUID, role models.Role) error {
+	return s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		productRepo := s.productRepo.WithTx(tx)
+
+		// Lock the products being updated so that their stock can be compared
+		var ids []uuid.UUID
+		for _, row := range rows {
+			if !row.isNew {
+				ids = append(ids, row.product.ID)
+			}
+		}
+		current := make(map[uuid.UUID]int, len(ids))
+		if len(ids) > 0 {
+			locked, err := productRepo.GetByIDsForUpdate(ids)
+			if err != nil {
+				return err
+			}
+			for _, product := range locked {
+				current[product.ID] = product.Stock
+			}
+		}
+
+		for _, row := range rows {
+			movement := &models.StockMovement{
+				Type:      models.StockMovementAdjustment,
+				ActorID:   &userID,
+				ActorRole: role,
+				Reason:    "product import",
+			}
+			if row.isNew {
+				// The initial stock is the first entry of the inventory ledger
+				movement.Type = models.StockMovementRestock
+				movement.Quantity = row.product.Stock
+				row.product.Stock = 0
+				if err := productRepo.Create(row.product); err != nil {
+					return err
+				}
+			} else {
+				if err := productRepo.Update(row.product); err != nil {
+					return err
+				}
+				if row.stock != nil {
+					movement.Quantity = *row.stock - current[row.product.ID]
+				}
+			}
+
+			if movement.Quantity == 0 {
+				continue
+			}
+			movement.ProductID = row.product.ID
+			if _, err := s.inventory.record(tx, movement); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ExportProducts writes the catalog of a store as a CSV or XLSX file in the import format
+func (s *ProductImportService) ExportProducts(storeID, userID uuid.UUID, role models.Role, format utils.SpreadsheetFormat, w io.Writer) error {
+	if _, err := s.access.Authorize(storeID, userID, role, models.PermissionManageProducts); err != nil {
+		return err
+	}
+
+	products, err := s.productRepo.ListByStore(storeID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(products)+1)
+	rows = append(rows, productColumns)
+	for _, product := range products {
+		rows = append(rows, []string{
+			product.SKU,
+			product.Name,
+			product.Description,
+			strings.Join(product.Tags, tagSeparator+" "),
+			product.Category.String(),
+			product.Price.Decimal(),
+			product.Price.Currency,
+			strconv.Itoa(product.Stock),
+			product.ImageURL,
+			strconv.FormatBool(product.IsActive),
+			strconv.Itoa(product.ReorderThreshold),
+			strconv.FormatBool(product.AutoHide),
+		})
+	}
+	return utils.WriteSpreadsheet(w, rows, format)
+}
+
+// parseProductHeader maps the column names of an import file to their positions
+func parseProductHeader(header []string) (map[string]int, error) {
+	known := make(map[string]bool, len(productColumns))
+	for _, name := range productColumns {
+		known[name] = true
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, errors.New("unknown column: " + name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, errors.New("repeated column: " + name)
+		}
+		columns[name] = i
+	}
+	for _, name := range requiredProductColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.New("missing column: " + name)
+		}
+	}
+	return columns, nil
+}
+
+// applyProductRecord copies the cells of a row onto a product. Columns missing from the file
+// keep the current values. It returns the stock of the row, nil when it does not set one.
+func applyProductRecord(product *models.Product, record []string, columns map[string]int) (*int, error) {
+	has := func(name string) bool {
+		_, ok := columns[name]
+		return ok
+	}
+
+	product.Name = cell(record, columns, "name")
+	product.Category = models.ProductCategory(strings.ToLower(cell(record, columns, "category")))
+	if has("description") {
+		product.Description = cell(record, columns, "description")
+	}
+	if has("tags") {
+		product.Tags = strings.Split(cell(record, columns, "tags"), tagSeparator)
+	}
+	if has("image_url") {
+		product.ImageURL = cell(record, columns, "image_url")
+	}
+
+	currency := product.Price.Currency
+	if value := cell(record, columns, "currency"); value != "" {
+		currency = value
+	}
+	price, err := strconv.ParseFloat(cell(record, columns, "price"), 64)
+	if err != nil || math.IsNaN(price) || math.IsInf(price, 0) {
+		return nil, errors.New("invalid price")
+	}
+	product.Price = models.MoneyFromDecimal(price, currency)
+
+	flags := []struct {
+		name  string
+		field *bool
+	}{
+		{"is_active", &product.IsActive},
+		{"auto_hide", &product.AutoHide},
+	}
+	for _, flag := range flags {
+		if value := cell(record, columns, flag.name); value != "" {
+			parsed, err := parseImportBool(value)
+			if err != nil {
+				return nil, errors.New("invalid " + flag.name)
+			}
+			*flag.field = parsed
+		}
+	}
+
+	if value := cell(record, columns, "reorder_threshold"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("invalid reorder_threshold")
+		}
+		product.ReorderThreshold = threshold
+	}
+
+	value := cell(record, columns, "stock")
+	if value == "" {
+		return nil, nil
+	}
+	stock, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, errors.New("invalid stock")
+	}
+	if stock < 0 {
+		return nil, errors.New("product stock must be non-negative")
+	}
+	return &stock, nil
+}
+
+// cell returns the trimmed value of a column in a row, empty when the row is shorter
+func cell(record []string, columns map[string]int, name string) string {
+	i, ok := columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// isBlankRecord reports whether every cell of a row is empty
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseImportBool parses true/false cells, accepting yes/no as spreadsheet users write them
+func parseImportBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y":
+		return true, nil
+	case "no", "n":
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
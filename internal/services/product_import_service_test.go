@@ -0,0 +1,53 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+func TestImportProductTags(t *testing.T) {
+	columns, err := parseProductHeader([]string{"SKU", "Name", "Tags", "Category", "Price"})
+	if err != nil {
+		t.Fatalf("parseProductHeader() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		current []string
+		cell    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "separated by semicolons", cell: "vegan; Gluten Free", want: []string{"vegan", "gluten free"}},
+		{name: "empty and repeated tags are dropped", cell: " Vegan;;vegan ; ", want: []string{"vegan"}},
+		{name: "empty cell clears the tags", current: []string{"old"}, cell: "", want: []string{}},
+		{name: "tag too long", cell: strings.Repeat("x", maxTagLength+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := &models.Product{StoreID: uuid.New(), Tags: tt.current}
+			record := []string{"SKU-1", "Pen", tt.cell, "stationery", "1.50"}
+			if _, err := applyProductRecord(product, record, columns); err != nil {
+				t.Fatalf("applyProductRecord() error = %v", err)
+			}
+			err := validateProduct(product)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("validateProduct() tags = %q, want an error", product.Tags)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateProduct() error = %v", err)
+			}
+			if !reflect.DeepEqual(product.Tags, tt.want) {
+				t.Errorf("tags = %q, want %q", product.Tags, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// ProductOptionService manages product variants and option groups with their modifiers.
+// Changes need the same store permission as editing the product itself.
+type ProductOptionService struct {
+	txManager   *repository.TxManager
+	productRepo *repository.ProductRepository
+	optionRepo  *repository.ProductOptionRepository
+	inventory   *InventoryService
+	products    *ProductService
+}
+
+// NewProductOptionService creates a new product option service
+func NewProductOptionService(
+	txManager *repository.TxManager,
+	productRepo *repository.ProductRepository,
+	optionRepo *repository.ProductOptionRepository,
+	inventory *InventoryService,
+	products *ProductService,
+) *ProductOptionService {
+	return &ProductOptionService{
+		txManager:   txManager,
+		productRepo: productRepo,
+		optionRepo:  optionRepo,
+		inventory:   inventory,
+		products:    products,
+	}
+}
+
+// VariantRequest represents a product variant
+type VariantRequest struct {
+	Name      string       `json:"name" binding:"required"`
+	SKU       string       `json:"sku" binding:"required"`
+	Price     models.Money `json:"price"`
+	Stock     int          `json:"stock" binding:"min=0"` // Initial stock; later changes go through stock movements
+	IsActive  *bool        `json:"is_active"`             // Defaults to true
+	SortOrder int          `json:"sort_order"`
+}
+
+// ModifierRequest represents a modifier of an option group
+type ModifierRequest struct {
+	Name       string       `json:"name" binding:"required"`
+	PriceDelta models.Money `json:"price_delta"` // Added to the item price, may be negative
+	IsActive   *bool        `json:"is_active"`   // Defaults to true
+	SortOrder  int          `json:"sort_order"`
+}
+
+// OptionGroupRequest represents an option group with all its modifiers
+type OptionGroupRequest struct {
+	Name          string            `json:"name" binding:"required"`
+	Required      bool              `json:"required"`
+	MinSelections int               `json:"min_selections" binding:"min=0"`
+	MaxSelections int               `json:"max_selections" binding:"min=0"` // 0 means no limit
+	SortOrder     int               `json:"sort_order"`
+	Modifiers     []ModifierRequest `json:"modifiers" binding:"required,min=1,dive"`
+}
+
+// CreateVariant adds a variant to a product
+func (s *ProductOptionService) CreateVariant(productID, userID uuid.UUID, role models.Role, req *VariantRequest) (*models.ProductVariant, error) {
+	product, err := s.getManagedProduct(productID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	variant := &models.ProductVariant{ProductID: product.ID}
+	if err := s.applyVariant(product, variant, req); err != nil {
+		return nil, err
+	}
+
+	// The initial stock is the first entry of the inventory ledger
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.optionRepo.WithTx(tx).CreateVariant(variant); err != nil {
+			return err
+		}
+		if req.Stock == 0 {
+			return nil
+		}
+		movement := &models.StockMovement{
+			ProductID: product.ID,
+			VariantID: &variant.ID,
+			Type:      models.StockMovementRestock,
+			Quantity:  req.Stock,
+			ActorID:   &userID,
+			ActorRole: role,
+			Reason:    "initial stock",
+		}
+		if _, err := s.inventory.record(tx, movement); err != nil {
+			return err
+		}
+		variant.Stock = movement.StockAfter
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+// UpdateVariant replaces the details of a product variant. Its stock is left untouched.
+func (s *ProductOptionService) UpdateVariant(productID, variantID, userID uuid.UUID, role models.Role, req *VariantRequest) (*models.ProductVariant, error) {
+	product, err := s.getManagedProduct(productID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := s.getVariant(product, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyVariant(product, variant, req); err != nil {
+		return nil, err
+	}
+	if err := s.optionRepo.UpdateVariant(variant); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+// DeleteVariant removes a variant from a product. Past orders keep their snapshot.
+func (s *ProductOptionService) DeleteVariant(productID, variantID, userID uuid.UUID, role models.Role) error {
+	product, err := s.getManagedProduct(productID, userID, role)
+	if err != nil {
+		return err
+	}
+	if _, err := s.getVariant(product, variantID); err != nil {
+		return err
+	}
+	return s.optionRepo.DeleteVariant(variantID)
+}
+
+// CreateOptionGroup adds an option group with its modifiers to a product
+func (s *ProductOptionService) CreateOptionGroup(productID, userID uuid.UUID, role models.Role, req *OptionGroupRequest) (*models.ProductOptionGroup, error) {
+	product, err := s.getManagedProduct(productID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &models.ProductOptionGroup{ProductID: product.ID}
+	if err := applyOptionGroup(product, group, req); err != nil {
+		return nil, err
+	}
+	if err := s.optionRepo.CreateOptionGroup(group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// UpdateOptionGroup replaces an option group and all its modifiers
+func (s *ProductOptionService) UpdateOptionGroup(productID, groupID, userID uuid.UUID, role models.Role, req *OptionGroupRequest) (*models.ProductOptionGroup, error) {
+	product, err := s.getManagedProduct(productID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.getOptionGroup(product, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyOptionGroup(product, group, req); err != nil {
+		return nil, err
+	}
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		return s.optionRepo.WithTx(tx).ReplaceOptionGroup(group)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// DeleteOptionGroup removes an option group and its modifiers from a product
+func (s *ProductOptionService) DeleteOptionGroup(productID, groupID, userID uuid.UUID, role models.Role) error {
+	product, err := s.getManagedProduct(productID, userID, role)
+	if err != nil {
+		return err
+	}
+	if _, err := s.getOptionGroup(product, groupID); err != nil {
+		return err
+	}
+	return s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		return s.optionRepo.WithTx(tx).DeleteOptionGroup(groupID)
+	})
+}
+
+// getManagedProduct loads a product whose catalog entry the user may change
+func (s *ProductOptionService) getManagedProduct(productID, userID uuid.UUID, role models.Role) (*models.Product, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.products.authorize(product.StoreID, userID, role, models.AuditProductUpdate, &product.ID); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// getVariant loads a variant of a product
+func (s *ProductOptionService) getVariant(product *models.Product, variantID uuid.UUID) (*models.ProductVariant, error) {
+	variant, err := s.optionRepo.GetVariant(variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant.ProductID != product.ID {
+		return nil, errors.New("product variant not found")
+	}
+	return variant, nil
+}
+
+// getOptionGroup loads an option group of a product
+func (s *ProductOptionService) getOptionGroup(product *models.Product, groupID uuid.UUID) (*models.ProductOptionGroup, error) {
+	group, err := s.optionRepo.GetOptionGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.ProductID != product.ID {
+		return nil, errors.New("option group not found")
+	}
+	return group, nil
+}
+
+// applyVariant validates a variant request and copies it onto the variant
+func (s *ProductOptionService) applyVariant(product *models.Product, variant *models.ProductVariant, req *VariantRequest) error {
+	name := strings.TrimSpace(req.Name)
+	sku := strings.TrimSpace(req.SKU)
+	if name == "" {
+		return errors.New("variant name is required")
+	}
+	if sku == "" {
+		return errors.New("variant SKU is required")
+	}
+	price := req.Price
+	if err := validatePrice(&price); err != nil {
+		return err
+	}
+	if !price.SameCurrency(product.Price) {
+		return errors.New("variant price must use the product currency")
+	}
+	if req.Stock < 0 {
+		return errors.New("variant stock must be non-negative")
+	}
+
+	// Variant SKUs share the namespace of product SKUs
+	if sku != variant.SKU {
+		exists, err := s.optionRepo.ExistsVariantBySKU(sku, variant.ID)
+		if err != nil {
+			return err
+		}
+		if !exists && sku != product.SKU {
+			exists, err = s.productRepo.ExistsBySKU(sku)
+			if err != nil {
+				return err
+			}
+		}
+		if exists {
+			return errors.New("product with this SKU already exists")
+		}
+	}
+
+	variant.Name = name
+	variant.SKU = sku
+	variant.Price = price
+	variant.IsActive = req.IsActive == nil || *req.IsActive
+	variant.SortOrder = req.SortOrder
+	return nil
+}
+
+// applyOptionGroup validates an option group request and copies it onto the group,
+// replacing its modifiers
+func applyOptionGroup(product *models.Product, group *models.ProductOptionGroup, req *OptionGroupRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.New("option group name is required")
+	}
+
+	// Required groups need at least one choice; groups with a minimum are required
+	minSelections := req.MinSelections
+	if req.Required && minSelections == 0 {
+		minSelections = 1
+	}
+	if req.MaxSelections > 0 && req.MaxSelections < minSelections {
+		return errors.New("max selections must not be lower than min selections")
+	}
+
+	modifiers := make([]models.ProductModifier, 0, len(req.Modifiers))
+	active := 0
+	for _, modReq := range req.Modifiers {
+		modName := strings.TrimSpace(modReq.Name)
+		if modName == "" {
+			return errors.New("modifier name is required")
+		}
+		delta := models.NewMoney(modReq.PriceDelta.Amount, strings.TrimSpace(modReq.PriceDelta.Currency))
+		if !delta.IsValidCurrency() {
+			return errors.New("invalid modifier price currency")
+		}
+		if !delta.SameCurrency(product.Price) {
+			return errors.New("modifier price must use the product currency")
+		}
+		isActive := modReq.IsActive == nil || *modReq.IsActive
+		if isActive {
+			active++
+		}
+		modifiers = append(modifiers, models.ProductModifier{
+			GroupID:    group.ID,
+			Name:       modName,
+			PriceDelta: delta,
+			IsActive:   isActive,
+			SortOrder:  modReq.SortOrder,
+		})
+	}
+	if active < minSelections {
+		return errors.New("option group has fewer active modifiers than min selections")
+	}
+
+	group.Name = name
+	group.Required = minSelections > 0
+	group.MinSelections = minSelections
+	group.MaxSelections = req.MaxSelections
+	group.SortOrder = req.SortOrder
+	group.Modifiers = modifiers
+	return nil
+}
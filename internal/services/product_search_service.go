@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// maxSearchQueryLength is the longest accepted search text, in characters
+const maxSearchQueryLength = 200
+
+// ProductSearchService searches the listed products of all stores.
+// Texts are matched in Spanish and English regardless of accents, with a fuzzy fallback
+// on the product name for typos and partial words.
+type ProductSearchService struct {
+	searchRepo  *repository.ProductSearchRepository
+	productRepo *repository.ProductRepository
+}
+
+// NewProductSearchService creates a new product search service
+func NewProductSearchService(searchRepo *repository.ProductSearchRepository, productRepo *repository.ProductRepository) *ProductSearchService {
+	return &ProductSearchService{
+		searchRepo:  searchRepo,
+		productRepo: productRepo,
+	}
+}
+
+// ProductSearchMatch is a product found by a search
+type ProductSearchMatch struct {
+	Product    *models.Product  `json:"product"`
+	Rank       float64          `json:"rank"`
+	Highlights SearchHighlights `json:"highlights"`
+}
+
+// SearchHighlights are HTML-escaped product texts with the matched words wrapped in <mark> tags
+type SearchHighlights struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// SearchFacet is the number of matching products with a category or at a store
+type SearchFacet struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// ProductSearchFacets counts the matching products per category and store
+type ProductSearchFacets struct {
+	Categories []SearchFacet `json:"categories"`
+	Stores     []SearchFacet `json:"stores"`
+}
+
+// ProductSearchResult is a page of search results with the facets of the whole result set
+type ProductSearchResult struct {
+	Matches []ProductSearchMatch
+	Total   int64
+	Facets  ProductSearchFacets
+}
+
+// SearchProducts finds listed products matching a search text, best match first.
+// Category and store narrow the results; each facet ignores its own filter.
+func (s *ProductSearchService) SearchProducts(query string, category models.ProductCategory, storeID uuid.UUID, limit, offset int) (*ProductSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("search text is required")
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, errors.New("search text is too long")
+	}
+	if category != "" && !category.IsValid() {
+		return nil, errors.New("invalid product category")
+	}
+
+	// Set default limit if not provided or invalid
+	if limit <= 0 {
+		limit = 20
+	}
+	// Cap maximum limit
+	if limit > 50 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	filter := repository.ProductSearchFilter{Query: query, Category: category, StoreID: storeID}
+	hits, err := s.searchRepo.Search(filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.searchRepo.Count(filter)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.searchRepo.CategoryFacets(filter)
+	if err != nil {
+		return nil, err
+	}
+	stores, err := s.searchRepo.StoreFacets(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ProductID)
+	}
+	products, err := s.productRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	productsByID := make(map[uuid.UUID]*models.Product, len(products))
+	for _, product := range products {
+		productsByID[product.ID] = product
+	}
+
+	result := &ProductSearchResult{
+		Matches: make([]ProductSearchMatch, 0, len(hits)),
+		Total:   total,
+		Facets: ProductSearchFacets{
+			Categories: searchFacets(categories),
+			Stores:     searchFacets(stores),
+		},
+	}
+	for _, hit := range hits {
+		product, ok := productsByID[hit.ProductID]
+		if !ok {
+			// Deleted since the search ran
+			continue
+		}
+		result.Matches = append(result.Matches, ProductSearchMatch{
+			Product: product,
+			Rank:    hit.Rank,
+			Highlights: SearchHighlights{
+				Name:        highlight(hit.NameHighlight),
+				Description: highlight(hit.DescriptionHighlight),
+			},
+		})
+	}
+	return result, nil
+}
+
+// highlight escapes a highlighted text and marks its matches with <mark> tags
+func highlight(text string) string {
+	text = html.EscapeString(text)
+	text = strings.ReplaceAll(text, repository.HighlightStart, "<mark>")
+	return strings.ReplaceAll(text, repository.HighlightStop, "</mark>")
+}
+
+// searchFacets converts facet counts for the response
+func searchFacets(counts []repository.FacetCount) []SearchFacet {
+	facets := make([]SearchFacet, 0, len(counts))
+	for _, count := range counts {
+		facets = append(facets, SearchFacet{Value: count.Value, Label: count.Label, Count: count.Count})
+	}
+	return facets
+}
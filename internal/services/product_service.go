@@ -2,56 +2,91 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"github.com/ruranjo/unientrega/internal/models"
 	"github.com/ruranjo/unientrega/internal/repository"
+	"github.com/ruranjo/unientrega/internal/utils"
 )
 
-// ProductService handles business logic for products
+const (
+	// maxTags is the largest number of search tags of a product
+	maxTags = 20
+	// maxTagLength is the longest search tag, in characters
+	maxTagLength = 50
+)
+
+// ProductService handles business logic for products.
+// Catalog changes require the manage products permission at the product's store;
+// denied attempts are recorded in the audit log.
 type ProductService struct {
+	txManager   *repository.TxManager
 	productRepo *repository.ProductRepository
+	optionRepo  *repository.ProductOptionRepository
+	inventory   *InventoryService
+	access      *StoreAccess
+	audit       *AuditService
 }
 
 // NewProductService creates a new product service
-func NewProductService(productRepo *repository.ProductRepository) *ProductService {
+func NewProductService(
+	txManager *repository.TxManager,
+	productRepo *repository.ProductRepository,
+	optionRepo *repository.ProductOptionRepository,
+	inventory *InventoryService,
+	access *StoreAccess,
+	audit *AuditService,
+) *ProductService {
 	return &ProductService{
+		txManager:   txManager,
 		productRepo: productRepo,
+		optionRepo:  optionRepo,
+		inventory:   inventory,
+		access:      access,
+		audit:       audit,
 	}
 }
 
-// CreateProduct creates a new product with validation
-func (s *ProductService) CreateProduct(product *models.Product) error {
-	// Validate product name
-	if strings.TrimSpace(product.Name) == "" {
-		return errors.New("product name is required")
-	}
+// StockUpdate sets the counted stock quantity of one product
+type StockUpdate struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Stock     int       `json:"stock" binding:"min=0"`
+}
 
-	// Validate price
-	if product.Price < 0 {
-		return errors.New("product price must be non-negative")
-	}
+// BulkStockRequest represents a stock count of several products at once.
+// Differences with the current stock are recorded as adjustments.
+type BulkStockRequest struct {
+	Items  []StockUpdate `json:"items" binding:"required,min=1,max=100,dive"`
+	Reason string        `json:"reason"` // Defaults to "stock count"
+}
 
-	// Validate stock
-	if product.Stock < 0 {
-		return errors.New("product stock must be non-negative")
-	}
+// StockMovementRequest represents a manual stock change of a product or one of its variants.
+// Restocks and returns add Quantity units, sales and spoilage remove them and
+// adjustments add a signed Quantity.
+type StockMovementRequest struct {
+	Type      models.StockMovementType `json:"type" binding:"required"`
+	Quantity  int                      `json:"quantity" binding:"required"`
+	VariantID *uuid.UUID               `json:"variant_id"` // Required to change the stock of a variant
+	Reason    string                   `json:"reason"`
+}
 
-	// Validate category
-	if !product.Category.IsValid() {
-		return errors.New("invalid product category")
+// CreateProduct creates a new product with validation (store owners and managers, or superuser)
+func (s *ProductService) CreateProduct(product *models.Product, userID uuid.UUID, role models.Role) error {
+	if err := validateProduct(product); err != nil {
+		return err
 	}
-
-	// Validate store ID
-	if product.StoreID == uuid.Nil {
-		return errors.New("store ID is required")
+	if err := s.authorize(product.StoreID, userID, role, models.AuditProductCreate, nil); err != nil {
+		return err
 	}
 
 	// Check SKU uniqueness if provided
 	if product.SKU != "" {
-		exists, err := s.productRepo.ExistsBySKU(product.SKU)
+		exists, err := s.skuExists(product.SKU)
 		if err != nil {
 			return err
 		}
@@ -60,7 +95,32 @@ func (s *ProductService) CreateProduct(product *models.Product) error {
 		}
 	}
 
-	return s.productRepo.Create(product)
+	product.LowStockAlertedAt = nil
+
+	// The initial stock is the first entry of the inventory ledger
+	initialStock := product.Stock
+	product.Stock = 0
+	return s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.productRepo.WithTx(tx).Create(product); err != nil {
+			return err
+		}
+		if initialStock == 0 {
+			return nil
+		}
+		movement := &models.StockMovement{
+			ProductID: product.ID,
+			Type:      models.StockMovementRestock,
+			Quantity:  initialStock,
+			ActorID:   &userID,
+			ActorRole: role,
+			Reason:    "initial stock",
+		}
+		if _, err := s.inventory.record(tx, movement); err != nil {
+			return err
+		}
+		product.Stock = movement.StockAfter
+		return nil
+	})
 }
 
 // GetProductByID retrieves a product by ID
@@ -76,31 +136,11 @@ func (s *ProductService) GetProductBySKU(sku string) (*models.Product, error) {
 	return s.productRepo.GetBySKU(sku)
 }
 
-// UpdateProduct updates a product with validation
-func (s *ProductService) UpdateProduct(product *models.Product) error {
-	// Validate product name
-	if strings.TrimSpace(product.Name) == "" {
-		return errors.New("product name is required")
-	}
-
-	// Validate price
-	if product.Price < 0 {
-		return errors.New("product price must be non-negative")
-	}
-
-	// Validate stock
-	if product.Stock < 0 {
-		return errors.New("product stock must be non-negative")
-	}
-
-	// Validate category
-	if !product.Category.IsValid() {
-		return errors.New("invalid product category")
-	}
-
-	// Validate store ID
-	if product.StoreID == uuid.Nil {
-		return errors.New("store ID is required")
+// UpdateProduct updates a product with validation.
+// Moving a product to another store requires the permission at both stores.
+func (s *ProductService) UpdateProduct(product *models.Product, userID uuid.UUID, role models.Role) error {
+	if err := validateProduct(product); err != nil {
+		return err
 	}
 
 	// Check if product exists
@@ -108,10 +148,22 @@ func (s *ProductService) UpdateProduct(product *models.Product) error {
 	if err != nil {
 		return err
 	}
+	if err := s.authorize(existingProduct.StoreID, userID, role, models.AuditProductUpdate, &product.ID); err != nil {
+		return err
+	}
+	if product.StoreID != existingProduct.StoreID {
+		if err := s.authorize(product.StoreID, userID, role, models.AuditProductUpdate, &product.ID); err != nil {
+			return err
+		}
+	}
+
+	// Stock only changes through the inventory ledger, the alert state through the low-stock checker
+	product.Stock = existingProduct.Stock
+	product.LowStockAlertedAt = existingProduct.LowStockAlertedAt
 
 	// Check SKU uniqueness if changed
 	if product.SKU != "" && product.SKU != existingProduct.SKU {
-		exists, err := s.productRepo.ExistsBySKU(product.SKU)
+		exists, err := s.skuExists(product.SKU)
 		if err != nil {
 			return err
 		}
@@ -124,54 +176,287 @@ func (s *ProductService) UpdateProduct(product *models.Product) error {
 }
 
 // DeleteProduct soft deletes a product
-func (s *ProductService) DeleteProduct(id uuid.UUID) error {
+func (s *ProductService) DeleteProduct(id, userID uuid.UUID, role models.Role) error {
 	// Check if product exists
-	_, err := s.productRepo.GetByID(id)
+	product, err := s.productRepo.GetByID(id)
 	if err != nil {
 		return err
 	}
+	if err := s.authorize(product.StoreID, userID, role, models.AuditProductDelete, &product.ID); err != nil {
+		return err
+	}
 
 	return s.productRepo.Delete(id)
 }
 
-// ListProducts returns a list of products with optional filters
-func (s *ProductService) ListProducts(limit, offset int, category models.ProductCategory, storeID uuid.UUID, activeOnly bool) ([]*models.Product, error) {
-	// Set default limit if not provided or invalid
-	if limit <= 0 {
-		limit = 10
+// ListProducts returns a page of the products matching a list query and the cursor of the next page
+func (s *ProductService) ListProducts(limit, offset int, cursor string, list utils.ListQuery) ([]*models.Product, string, error) {
+	page, err := newPage(limit, offset, cursor, 10, 100)
+	if err != nil {
+		return nil, "", err
 	}
-	// Cap maximum limit to prevent excessive queries
-	if limit > 100 {
-		limit = 100
+	return s.productRepo.List(page, list)
+}
+
+// CountProducts returns the total number of products matching the filters of a list query
+func (s *ProductService) CountProducts(list utils.ListQuery) (int64, error) {
+	return s.productRepo.Count(list)
+}
+
+// RecordStockMovement changes the stock of a product or variant through the inventory ledger
+func (s *ProductService) RecordStockMovement(id, userID uuid.UUID, role models.Role, req *StockMovementRequest) (*models.StockMovement, error) {
+	quantity, err := movementQuantity(req.Type, req.Quantity)
+	if err != nil {
+		return nil, err
 	}
 
-	// Ensure offset is non-negative
-	if offset < 0 {
-		offset = 0
+	// Check if product exists
+	product, err := s.productRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(product.StoreID, userID, role, models.AuditProductStock, &product.ID); err != nil {
+		return nil, err
+	}
+	if req.VariantID != nil {
+		variant, err := s.optionRepo.GetVariant(*req.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		if variant.ProductID != product.ID {
+			return nil, errors.New("product variant not found")
+		}
 	}
 
-	return s.productRepo.List(limit, offset, category, storeID, activeOnly)
+	movement := &models.StockMovement{
+		ProductID: product.ID,
+		VariantID: req.VariantID,
+		Type:      req.Type,
+		Quantity:  quantity,
+		ActorID:   &userID,
+		ActorRole: role,
+		Reason:    strings.TrimSpace(req.Reason),
+	}
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		ok, err := s.inventory.record(tx, movement)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("stock cannot drop below zero")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movement, nil
 }
 
-// CountProducts returns the total number of products with optional filters
-func (s *ProductService) CountProducts(category models.ProductCategory, storeID uuid.UUID, activeOnly bool) (int64, error) {
-	return s.productRepo.Count(category, storeID, activeOnly)
+// ListStockMovements returns the inventory ledger of a product (store owners and managers, or superuser)
+func (s *ProductService) ListStockMovements(id, userID uuid.UUID, role models.Role, variantID *uuid.UUID, limit, offset int, cursor string) ([]models.StockMovement, string, int64, error) {
+	product, err := s.productRepo.GetByID(id)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	if _, err := s.access.Authorize(product.StoreID, userID, role, models.PermissionManageProducts); err != nil {
+		return nil, "", 0, err
+	}
+	return s.inventory.ListMovements(product.ID, variantID, limit, offset, cursor)
 }
 
-// UpdateStock updates the stock quantity of a product with validation
-func (s *ProductService) UpdateStock(id uuid.UUID, quantity int) error {
-	// Validate stock quantity
-	if quantity < 0 {
-		return errors.New("stock quantity must be non-negative")
+// BulkUpdateStock records a stock count of several products at once.
+// Either every product is updated or, if any product is missing or belongs to a
+// store the user does not manage, none is.
+func (s *ProductService) BulkUpdateStock(req *BulkStockRequest, userID uuid.UUID, role models.Role) error {
+	ids := make([]uuid.UUID, 0, len(req.Items))
+	seen := make(map[uuid.UUID]bool, len(req.Items))
+	for _, item := range req.Items {
+		if item.Stock < 0 {
+			return errors.New("stock quantity must be non-negative")
+		}
+		if seen[item.ProductID] {
+			return errors.New("duplicate product " + item.ProductID.String())
+		}
+		seen[item.ProductID] = true
+		ids = append(ids, item.ProductID)
 	}
 
-	// Check if product exists
-	_, err := s.productRepo.GetByID(id)
+	return s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		productRepo := s.productRepo.WithTx(tx)
+		products, err := productRepo.GetByIDsForUpdate(ids)
+		if err != nil {
+			return err
+		}
+		if len(products) != len(ids) {
+			return errors.New("product not found")
+		}
+
+		checked := make(map[uuid.UUID]bool)
+		for _, product := range products {
+			if checked[product.StoreID] {
+				continue
+			}
+			if err := s.authorize(product.StoreID, userID, role, models.AuditProductBulkStock, nil); err != nil {
+				return err
+			}
+			checked[product.StoreID] = true
+		}
+
+		reason := strings.TrimSpace(req.Reason)
+		if reason == "" {
+			reason = "stock count"
+		}
+		productsByID := make(map[uuid.UUID]*models.Product, len(products))
+		for _, product := range products {
+			productsByID[product.ID] = product
+		}
+		for _, item := range req.Items {
+			delta := item.Stock - productsByID[item.ProductID].Stock
+			if delta == 0 {
+				continue
+			}
+			_, err := s.inventory.record(tx, &models.StockMovement{
+				ProductID: item.ProductID,
+				Type:      models.StockMovementAdjustment,
+				Quantity:  delta,
+				ActorID:   &userID,
+				ActorRole: role,
+				Reason:    reason,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// movementQuantity returns the signed stock change of a manual movement
+func movementQuantity(movementType models.StockMovementType, quantity int) (int, error) {
+	if !movementType.IsValid() {
+		return 0, errors.New("invalid stock movement type")
+	}
+	if quantity == 0 {
+		return 0, errors.New("quantity must not be zero")
+	}
+	switch movementType {
+	case models.StockMovementAdjustment:
+		return quantity, nil
+	case models.StockMovementRestock, models.StockMovementReturn:
+		if quantity < 0 {
+			return 0, errors.New("quantity must be positive for " + movementType.String())
+		}
+		return quantity, nil
+	default:
+		if quantity < 0 {
+			return 0, errors.New("quantity must be positive for " + movementType.String())
+		}
+		return -quantity, nil
+	}
+}
+
+// validateProduct checks the fields of a product that do not depend on other records.
+// Product imports validate every row with it as well.
+func validateProduct(product *models.Product) error {
+	// Validate product name
+	if strings.TrimSpace(product.Name) == "" {
+		return errors.New("product name is required")
+	}
+
+	// Validate price
+	if err := validatePrice(&product.Price); err != nil {
+		return err
+	}
+
+	// Validate stock
+	if product.Stock < 0 {
+		return errors.New("product stock must be non-negative")
+	}
+
+	// Validate reorder threshold
+	if product.ReorderThreshold < 0 {
+		return errors.New("reorder threshold must be non-negative")
+	}
+
+	// Validate category
+	if !product.Category.IsValid() {
+		return errors.New("invalid product category")
+	}
+
+	// Validate store ID
+	if product.StoreID == uuid.Nil {
+		return errors.New("store ID is required")
+	}
+
+	// Validate search tags
+	tags, err := normalizeTags(product.Tags)
 	if err != nil {
 		return err
 	}
+	product.Tags = tags
+
+	return nil
+}
+
+// normalizeTags trims and lowercases product tags, dropping empty and repeated ones
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("tags must not be longer than %d characters", maxTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxTags {
+		return nil, fmt.Errorf("a product can have at most %d tags", maxTags)
+	}
+	return normalized, nil
+}
+
+// validatePrice checks a product price, defaulting its currency to the application currency
+func validatePrice(price *models.Money) error {
+	*price = models.NewMoney(price.Amount, strings.TrimSpace(price.Currency))
+	if price.IsNegative() {
+		return errors.New("product price must be non-negative")
+	}
+	if !price.IsValidCurrency() {
+		return errors.New("invalid price currency")
+	}
+	return nil
+}
 
-	return s.productRepo.UpdateStock(id, quantity)
+// skuExists checks if a product or a product variant uses the SKU
+func (s *ProductService) skuExists(sku string) (bool, error) {
+	exists, err := s.productRepo.ExistsBySKU(sku)
+	if err != nil || exists {
+		return exists, err
+	}
+	return s.optionRepo.ExistsVariantBySKU(sku, uuid.Nil)
+}
+
+// authorize checks that the user manages the catalog of a store and audits denied attempts
+func (s *ProductService) authorize(storeID, userID uuid.UUID, role models.Role, action models.AuditAction, productID *uuid.UUID) error {
+	_, err := s.access.Authorize(storeID, userID, role, models.PermissionManageProducts)
+	if err != nil && err.Error() == "permission denied" {
+		s.audit.Record(&models.AuditLog{
+			ActorID:      userID,
+			ActorRole:    role,
+			Action:       action,
+			Outcome:      models.AuditOutcomeDenied,
+			ResourceType: "product",
+			ResourceID:   productID,
+			StoreID:      &storeID,
+			Reason:       "missing " + string(models.PermissionManageProducts) + " permission",
+		})
+	}
+	return err
 }
 
 // IsAvailable checks if a product is available (active and in stock)
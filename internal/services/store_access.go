@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// StoreAccess checks what users may do at a store through their store membership.
+// Superusers may do everything at every store.
+type StoreAccess struct {
+	storeRepo  *repository.StoreRepository
+	memberRepo *repository.StoreMemberRepository
+}
+
+// NewStoreAccess creates a new store access checker
+func NewStoreAccess(storeRepo *repository.StoreRepository, memberRepo *repository.StoreMemberRepository) *StoreAccess {
+	return &StoreAccess{
+		storeRepo:  storeRepo,
+		memberRepo: memberRepo,
+	}
+}
+
+// Authorize checks that the user has a permission at a store and returns the store
+func (a *StoreAccess) Authorize(storeID, userID uuid.UUID, role models.Role, permission models.StorePermission) (*models.Store, error) {
+	store, err := a.storeRepo.GetByID(storeID)
+	if err != nil {
+		return nil, errors.New("store not found")
+	}
+
+	allowed, err := a.Can(storeID, userID, role, permission)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("permission denied")
+	}
+	return store, nil
+}
+
+// Can reports whether the user has a permission at a store
+func (a *StoreAccess) Can(storeID, userID uuid.UUID, role models.Role, permission models.StorePermission) (bool, error) {
+	if role == models.RoleSuperUser {
+		return true, nil
+	}
+
+	member, err := a.Membership(storeID, userID)
+	if err != nil || member == nil {
+		return false, err
+	}
+	return member.Role.Can(permission), nil
+}
+
+// Membership returns the membership of a user at a store, or nil if the user is not a member
+func (a *StoreAccess) Membership(storeID, userID uuid.UUID) (*models.StoreMember, error) {
+	member, err := a.memberRepo.GetByStoreAndUser(storeID, userID)
+	if err != nil {
+		if err.Error() == "store member not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return member, nil
+}
@@ -0,0 +1,484 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// openingSearchDays is how far ahead the next opening time of a closed store is searched
+const openingSearchDays = 366
+
+// dateLayout is the format of special hours dates
+const dateLayout = "2006-01-02"
+
+// StoreClosedError is returned when a store does not accept orders at the requested time
+type StoreClosedError struct {
+	Reason        string
+	NextOpeningAt *time.Time // Nil when no opening is scheduled
+}
+
+// Error implements the error interface
+func (e *StoreClosedError) Error() string {
+	msg := "store is closed"
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+	if e.NextOpeningAt != nil {
+		return msg + ", next opening at " + e.NextOpeningAt.Format(time.RFC3339)
+	}
+	return msg + " and has no upcoming opening hours"
+}
+
+// StoreHoursService manages store opening hours, special hours and pause mode.
+// Stores without a weekly schedule are open around the clock unless special hours say otherwise.
+type StoreHoursService struct {
+	txManager *repository.TxManager
+	storeRepo *repository.StoreRepository
+	hoursRepo *repository.StoreHoursRepository
+	access    *StoreAccess
+}
+
+// NewStoreHoursService creates a new store hours service
+func NewStoreHoursService(txManager *repository.TxManager, storeRepo *repository.StoreRepository, hoursRepo *repository.StoreHoursRepository, access *StoreAccess) *StoreHoursService {
+	return &StoreHoursService{
+		txManager: txManager,
+		storeRepo: storeRepo,
+		hoursRepo: hoursRepo,
+		access:    access,
+	}
+}
+
+// OpeningPeriodRequest represents a weekly opening period
+type OpeningPeriodRequest struct {
+	Weekday  int    `json:"weekday" binding:"min=0,max=6"` // 0 = Sunday ... 6 = Saturday
+	OpensAt  string `json:"opens_at" binding:"required"`   // HH:MM
+	ClosesAt string `json:"closes_at" binding:"required"`  // HH:MM, at or before opens_at ends the next day
+}
+
+// OpeningHoursRequest replaces the weekly schedule of a store.
+// An empty list of hours leaves the store open around the clock.
+type OpeningHoursRequest struct {
+	TimeZone string                 `json:"time_zone"` // IANA zone; keeps the current zone when empty
+	Hours    []OpeningPeriodRequest `json:"hours" binding:"dive"`
+}
+
+// SpecialHoursRequest represents a closure or different hours for a range of dates
+type SpecialHoursRequest struct {
+	StartDate string `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate   string `json:"end_date"`                      // YYYY-MM-DD, defaults to the start date
+	Closed    bool   `json:"closed"`
+	OpensAt   string `json:"opens_at"` // Required when not closed
+	ClosesAt  string `json:"closes_at"`
+	Reason    string `json:"reason"`
+}
+
+// PauseStoreRequest represents a temporary stop of order intake
+type PauseStoreRequest struct {
+	Until  *time.Time `json:"until"` // Resumes automatically at this time; paused until resumed when empty
+	Reason string     `json:"reason"`
+}
+
+// StoreSchedule is the opening schedule of a store and whether it accepts orders now
+type StoreSchedule struct {
+	StoreID       uuid.UUID                  `json:"store_id"`
+	TimeZone      string                     `json:"time_zone"`
+	IsOpen        bool                       `json:"is_open"`
+	ClosedReason  string                     `json:"closed_reason,omitempty"`
+	NextOpeningAt *time.Time                 `json:"next_opening_at,omitempty"`
+	IsPaused      bool                       `json:"is_paused"`
+	PausedUntil   *time.Time                 `json:"paused_until,omitempty"`
+	PauseReason   string                     `json:"pause_reason,omitempty"`
+	OpeningHours  []models.StoreOpeningHours `json:"opening_hours"`
+	SpecialHours  []models.StoreSpecialHours `json:"special_hours"` // Current and upcoming
+}
+
+// GetSchedule returns the schedule of a store
+func (s *StoreHoursService) GetSchedule(storeID uuid.UUID) (*StoreSchedule, error) {
+	store, err := s.storeRepo.GetByID(storeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildSchedule(store, time.Now())
+}
+
+// SetOpeningHours replaces the weekly schedule and optionally the time zone of a store
+func (s *StoreHoursService) SetOpeningHours(storeID, userID uuid.UUID, role models.Role, req *OpeningHoursRequest) (*StoreSchedule, error) {
+	store, err := s.getManagedStore(storeID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if tz := strings.TrimSpace(req.TimeZone); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return nil, errors.New("invalid time zone: " + tz)
+		}
+		store.TimeZone = tz
+	}
+
+	hours := make([]models.StoreOpeningHours, 0, len(req.Hours))
+	for _, period := range req.Hours {
+		if _, err := parseClockRange(period.OpensAt, period.ClosesAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", time.Weekday(period.Weekday), err)
+		}
+		hours = append(hours, models.StoreOpeningHours{
+			StoreID:  store.ID,
+			Weekday:  period.Weekday,
+			OpensAt:  period.OpensAt,
+			ClosesAt: period.ClosesAt,
+		})
+	}
+
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.storeRepo.WithTx(tx).SetTimeZone(store.ID, store.TimeZone); err != nil {
+			return err
+		}
+		return s.hoursRepo.WithTx(tx).ReplaceOpeningHours(store.ID, hours)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildSchedule(store, time.Now())
+}
+
+// AddSpecialHours closes a store or changes its hours for a range of dates.
+// When special hours overlap, the most recently added ones apply.
+func (s *StoreHoursService) AddSpecialHours(storeID, userID uuid.UUID, role models.Role, req *SpecialHoursRequest) (*models.StoreSpecialHours, error) {
+	store, err := s.getManagedStore(storeID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.EndDate == "" {
+		req.EndDate = req.StartDate
+	}
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		return nil, errors.New("start date must use the YYYY-MM-DD format")
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		return nil, errors.New("end date must use the YYYY-MM-DD format")
+	}
+	if end.Before(start) {
+		return nil, errors.New("end date must not be before the start date")
+	}
+
+	special := &models.StoreSpecialHours{
+		StoreID:   store.ID,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Closed:    req.Closed,
+		Reason:    strings.TrimSpace(req.Reason),
+	}
+	if !req.Closed {
+		if _, err := parseClockRange(req.OpensAt, req.ClosesAt); err != nil {
+			return nil, err
+		}
+		special.OpensAt = req.OpensAt
+		special.ClosesAt = req.ClosesAt
+	}
+
+	if err := s.hoursRepo.CreateSpecialHours(special); err != nil {
+		return nil, err
+	}
+	return special, nil
+}
+
+// DeleteSpecialHours removes special hours of a store
+func (s *StoreHoursService) DeleteSpecialHours(storeID, specialID, userID uuid.UUID, role models.Role) error {
+	if _, err := s.getManagedStore(storeID, userID, role); err != nil {
+		return err
+	}
+
+	special, err := s.hoursRepo.GetSpecialHours(specialID)
+	if err != nil {
+		return err
+	}
+	if special.StoreID != storeID {
+		return errors.New("special hours not found")
+	}
+	return s.hoursRepo.DeleteSpecialHours(special.ID)
+}
+
+// PauseStore stops order intake of a store, e.g. during a rush
+func (s *StoreHoursService) PauseStore(storeID, userID uuid.UUID, role models.Role, req *PauseStoreRequest) (*StoreSchedule, error) {
+	store, err := s.getManagedStore(storeID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if req.Until != nil && !req.Until.After(now) {
+		return nil, errors.New("pause end must be in the future")
+	}
+
+	store.IsPaused = true
+	store.PausedUntil = req.Until
+	store.PauseReason = strings.TrimSpace(req.Reason)
+	if err := s.storeRepo.SetPause(store.ID, store.IsPaused, store.PausedUntil, store.PauseReason); err != nil {
+		return nil, err
+	}
+	return s.buildSchedule(store, now)
+}
+
+// ResumeStore resumes order intake of a paused store
+func (s *StoreHoursService) ResumeStore(storeID, userID uuid.UUID, role models.Role) (*StoreSchedule, error) {
+	store, err := s.getManagedStore(storeID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	store.IsPaused = false
+	store.PausedUntil = nil
+	store.PauseReason = ""
+	if err := s.storeRepo.SetPause(store.ID, false, nil, ""); err != nil {
+		return nil, err
+	}
+	return s.buildSchedule(store, time.Now())
+}
+
+// CheckOpen returns a StoreClosedError if the store does not accept orders at the given time
+func (s *StoreHoursService) CheckOpen(store *models.Store, at time.Time) error {
+	calendar, err := s.loadCalendar(store, at)
+	if err != nil {
+		return err
+	}
+	if open, reason, next := calendar.status(store, at); !open {
+		return &StoreClosedError{Reason: reason, NextOpeningAt: next}
+	}
+	return nil
+}
+
+// buildSchedule loads the schedule of a store and evaluates it at the given time
+func (s *StoreHoursService) buildSchedule(store *models.Store, at time.Time) (*StoreSchedule, error) {
+	calendar, err := s.loadCalendar(store, at)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &StoreSchedule{
+		StoreID:      store.ID,
+		TimeZone:     calendar.location.String(),
+		IsPaused:     store.IsPaused,
+		PausedUntil:  store.PausedUntil,
+		PauseReason:  store.PauseReason,
+		OpeningHours: calendar.openingHours,
+		SpecialHours: calendar.specialHours,
+	}
+	schedule.IsOpen, schedule.ClosedReason, schedule.NextOpeningAt = calendar.status(store, at)
+	return schedule, nil
+}
+
+// loadCalendar loads the weekly schedule and the special hours that are current or upcoming at the given time
+func (s *StoreHoursService) loadCalendar(store *models.Store, at time.Time) (*storeCalendar, error) {
+	location, err := time.LoadLocation(store.TimeZone)
+	if err != nil {
+		return nil, errors.New("invalid store time zone: " + store.TimeZone)
+	}
+
+	openingHours, err := s.hoursRepo.ListOpeningHours(store.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Include yesterday, whose hours may run past midnight
+	from := at.In(location).AddDate(0, 0, -1).Format(dateLayout)
+	specialHours, err := s.hoursRepo.ListSpecialHours(store.ID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return &storeCalendar{location: location, openingHours: openingHours, specialHours: specialHours}, nil
+}
+
+// getManagedStore loads a store whose schedule the user may manage (store owners and managers, or superuser)
+func (s *StoreHoursService) getManagedStore(storeID, userID uuid.UUID, role models.Role) (*models.Store, error) {
+	return s.access.Authorize(storeID, userID, role, models.PermissionManageSchedule)
+}
+
+// storeCalendar evaluates the schedule of a store in its time zone
+type storeCalendar struct {
+	location     *time.Location
+	openingHours []models.StoreOpeningHours
+	specialHours []models.StoreSpecialHours // Most recently created first
+}
+
+// openPeriod is a concrete interval in which a store is open
+type openPeriod struct {
+	start time.Time
+	end   time.Time
+}
+
+// status reports whether the store accepts orders at the given time.
+// When closed it also returns the reason and the next opening time, if any.
+func (c *storeCalendar) status(store *models.Store, at time.Time) (bool, string, *time.Time) {
+	if store.IsPaused && (store.PausedUntil == nil || store.PausedUntil.After(at)) {
+		reason := "paused"
+		if store.PauseReason != "" {
+			reason += ": " + store.PauseReason
+		}
+		if store.PausedUntil == nil {
+			return false, reason, nil
+		}
+		return false, reason, c.nextOpening(*store.PausedUntil)
+	}
+
+	next := c.nextOpening(at)
+	if next != nil && next.Equal(at.In(c.location)) {
+		return true, "", nil
+	}
+
+	reason := "outside opening hours"
+	if special := c.specialOn(at.In(c.location).Format(dateLayout)); special != nil && special.Closed && special.Reason != "" {
+		reason = special.Reason
+	}
+	return false, reason, next
+}
+
+// nextOpening returns the given time if the store is open then, otherwise the start of
+// the next opening period within the search window. It returns nil if there is none.
+func (c *storeCalendar) nextOpening(from time.Time) *time.Time {
+	local := from.In(c.location)
+	// Start with yesterday, whose hours may run past midnight
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.location).AddDate(0, 0, -1)
+
+	for i := 0; i <= openingSearchDays; i++ {
+		for _, period := range c.periodsOn(day.AddDate(0, 0, i)) {
+			if !period.end.After(local) {
+				continue
+			}
+			if !period.start.After(local) {
+				return &local
+			}
+			start := period.start
+			return &start
+		}
+	}
+	return nil
+}
+
+// periodsOn returns the opening periods that start on the given day, sorted by start
+func (c *storeCalendar) periodsOn(day time.Time) []openPeriod {
+	if special := c.specialOn(day.Format(dateLayout)); special != nil {
+		if special.Closed {
+			return nil
+		}
+		clock, err := parseClockRange(special.OpensAt, special.ClosesAt)
+		if err != nil {
+			return nil
+		}
+		return []openPeriod{clock.on(day)}
+	}
+
+	if len(c.openingHours) == 0 {
+		return []openPeriod{{start: day, end: day.AddDate(0, 0, 1)}}
+	}
+
+	var periods []openPeriod
+	for _, hours := range c.openingHours {
+		if hours.Weekday != int(day.Weekday()) {
+			continue
+		}
+		clock, err := parseClockRange(hours.OpensAt, hours.ClosesAt)
+		if err != nil {
+			continue
+		}
+		periods = append(periods, clock.on(day))
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].start.Before(periods[j].start) })
+	return periods
+}
+
+// specialOn returns the special hours that apply on a date (YYYY-MM-DD), if any
+func (c *storeCalendar) specialOn(date string) *models.StoreSpecialHours {
+	for i := range c.specialHours {
+		if c.specialHours[i].StartDate <= date && date <= c.specialHours[i].EndDate {
+			return &c.specialHours[i]
+		}
+	}
+	return nil
+}
+
+// clockRange is an opening period in minutes since midnight.
+// Closing minutes past 24:00 belong to the next day.
+type clockRange struct {
+	opens  int
+	closes int
+}
+
+// on returns the concrete period of the clock range on the given day
+func (r clockRange) on(day time.Time) openPeriod {
+	return openPeriod{start: clockTime(day, r.opens), end: clockTime(day, r.closes)}
+}
+
+// clockTime returns the time the given minutes after midnight of a day; minutes past 24:00
+// fall on the next day. Times skipped when clocks go forward are moved forward by the
+// length of the gap, so 02:30 becomes 03:30 when clocks jump from 02:00 to 03:00.
+func clockTime(day time.Time, minutes int) time.Time {
+	t := time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, day.Location())
+
+	// time.Date resolves skipped times with the offset after the gap, which moves them backward
+	want := time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, time.UTC)
+	got := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	return t.Add(want.Sub(got))
+}
+
+// parseClockRange parses opening and closing times in the HH:MM format
+func parseClockRange(opensAt, closesAt string) (clockRange, error) {
+	opens, err := parseClock(opensAt)
+	if err != nil {
+		return clockRange{}, err
+	}
+	closes, err := parseClock(closesAt)
+	if err != nil {
+		return clockRange{}, err
+	}
+	if opens == 24*60 {
+		return clockRange{}, errors.New("opening time must be before 24:00")
+	}
+	if opens == closes {
+		return clockRange{}, errors.New("opening and closing times must differ")
+	}
+	if closes < opens {
+		closes += 24 * 60
+	}
+	return clockRange{opens: opens, closes: closes}, nil
+}
+
+// parseClock parses a time of day in the HH:MM format into minutes since midnight.
+// 24:00 is accepted as the end of the day.
+func parseClock(value string) (int, error) {
+	invalid := errors.New("invalid time " + strconv.Quote(value) + ", use the HH:MM format")
+
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, invalid
+	}
+	for _, part := range parts {
+		if len(part) != 2 || part[0] < '0' || part[0] > '9' || part[1] < '0' || part[1] > '9' {
+			return 0, invalid
+		}
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, invalid
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, invalid
+	}
+	if hours < 0 || minutes < 0 || minutes > 59 || hours > 24 || (hours == 24 && minutes != 0) {
+		return 0, invalid
+	}
+	return hours*60 + minutes, nil
+}
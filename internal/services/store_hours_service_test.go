@@ -0,0 +1,169 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ruranjo/unientrega/internal/models"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "00:00", want: 0},
+		{value: "09:30", want: 570},
+		{value: "23:59", want: 1439},
+		{value: "24:00", want: 1440},
+		{value: "24:01", wantErr: true},
+		{value: "25:00", wantErr: true},
+		{value: "12:60", wantErr: true},
+		{value: "9:30", wantErr: true},
+		{value: "+9:30", wantErr: true},
+		{value: "-1:00", wantErr: true},
+		{value: "09:3a", wantErr: true},
+		{value: "0930", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseClock(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseClock(%q) = %d, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseClock(%q) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseClock(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseClockRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		opensAt  string
+		closesAt string
+		want     clockRange
+		wantErr  bool
+	}{
+		{name: "same day", opensAt: "09:00", closesAt: "17:00", want: clockRange{opens: 540, closes: 1020}},
+		{name: "past midnight", opensAt: "22:00", closesAt: "02:00", want: clockRange{opens: 1320, closes: 1560}},
+		{name: "until midnight", opensAt: "18:00", closesAt: "24:00", want: clockRange{opens: 1080, closes: 1440}},
+		{name: "until midnight as 00:00", opensAt: "18:00", closesAt: "00:00", want: clockRange{opens: 1080, closes: 1440}},
+		{name: "whole day", opensAt: "00:00", closesAt: "24:00", want: clockRange{opens: 0, closes: 1440}},
+		{name: "opens at 24:00", opensAt: "24:00", closesAt: "06:00", wantErr: true},
+		{name: "empty period", opensAt: "10:00", closesAt: "10:00", wantErr: true},
+		{name: "invalid closing time", opensAt: "10:00", closesAt: "10", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseClockRange(tt.opensAt, tt.closesAt)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseClockRange() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseClockRange() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseClockRange() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreCalendarStatus(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	at := func(value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, newYork)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	ptr := func(value time.Time) *time.Time { return &value }
+	weekdays := func(opensAt, closesAt string) []models.StoreOpeningHours {
+		hours := make([]models.StoreOpeningHours, 0, 7)
+		for day := 0; day < 7; day++ {
+			hours = append(hours, models.StoreOpeningHours{Weekday: day, OpensAt: opensAt, ClosesAt: closesAt})
+		}
+		return hours
+	}
+
+	fridayNight := []models.StoreOpeningHours{{Weekday: int(time.Friday), OpensAt: "22:00", ClosesAt: "02:00"}}
+	mondayEvening := []models.StoreOpeningHours{{Weekday: int(time.Monday), OpensAt: "18:00", ClosesAt: "24:00"}}
+	// 2026-03-08 02:00-03:00 does not exist in New York
+	springForward := []models.StoreOpeningHours{{Weekday: int(time.Sunday), OpensAt: "02:30", ClosesAt: "05:00"}}
+	// 2026-11-01 01:00-02:00 happens twice in New York
+	fallBack := []models.StoreOpeningHours{{Weekday: int(time.Saturday), OpensAt: "22:00", ClosesAt: "02:00"}}
+
+	tests := []struct {
+		name       string
+		hours      []models.StoreOpeningHours
+		special    []models.StoreSpecialHours
+		store      models.Store
+		at         time.Time
+		wantOpen   bool
+		wantReason string
+		wantNext   *time.Time
+	}{
+		{name: "no schedule is always open", at: at("2026-10-19 03:00"), wantOpen: true},
+		{name: "before hours past midnight", hours: fridayNight, at: at("2026-10-30 21:00"),
+			wantReason: "outside opening hours", wantNext: ptr(at("2026-10-30 22:00"))},
+		{name: "after midnight of the previous day", hours: fridayNight, at: at("2026-10-31 01:59"), wantOpen: true},
+		{name: "closing time past midnight", hours: fridayNight, at: at("2026-10-31 02:00"),
+			wantReason: "outside opening hours", wantNext: ptr(at("2026-11-06 22:00"))},
+		{name: "last minute before 24:00", hours: mondayEvening, at: at("2026-10-19 23:59"), wantOpen: true},
+		{name: "24:00 closes at midnight", hours: mondayEvening, at: at("2026-10-20 00:00"),
+			wantReason: "outside opening hours", wantNext: ptr(at("2026-10-26 18:00"))},
+		{name: "opening in a DST gap moves forward", hours: springForward, at: at("2026-03-08 01:30"),
+			wantReason: "outside opening hours", wantNext: ptr(time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC))},
+		{name: "open after a DST gap", hours: springForward, at: at("2026-03-08 04:00"), wantOpen: true},
+		{name: "open through the repeated hour", hours: fallBack, at: time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC), wantOpen: true},
+		{name: "closed after the repeated hour", hours: fallBack, at: time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC),
+			wantReason: "outside opening hours", wantNext: ptr(at("2026-11-07 22:00"))},
+		{name: "pause ending mid-period", hours: weekdays("09:00", "17:00"), at: at("2026-10-19 11:00"),
+			store:      models.Store{IsPaused: true, PausedUntil: ptr(at("2026-10-19 12:30")), PauseReason: "rush"},
+			wantReason: "paused: rush", wantNext: ptr(at("2026-10-19 12:30"))},
+		{name: "pause ending after hours", hours: weekdays("09:00", "17:00"), at: at("2026-10-19 11:00"),
+			store:      models.Store{IsPaused: true, PausedUntil: ptr(at("2026-10-19 18:00"))},
+			wantReason: "paused", wantNext: ptr(at("2026-10-20 09:00"))},
+		{name: "pause that has ended", hours: weekdays("09:00", "17:00"), at: at("2026-10-19 13:00"),
+			store: models.Store{IsPaused: true, PausedUntil: ptr(at("2026-10-19 12:30"))}, wantOpen: true},
+		{name: "pause without end", hours: weekdays("09:00", "17:00"), at: at("2026-10-19 13:00"),
+			store: models.Store{IsPaused: true}, wantReason: "paused"},
+		{name: "special closure", hours: weekdays("09:00", "17:00"), at: at("2026-10-19 13:00"),
+			special:    []models.StoreSpecialHours{{StartDate: "2026-10-19", EndDate: "2026-10-20", Closed: true, Reason: "inventory"}},
+			wantReason: "inventory", wantNext: ptr(at("2026-10-21 09:00"))},
+		{name: "special hours past midnight", hours: weekdays("09:00", "17:00"), at: at("2026-10-20 00:30"),
+			special:  []models.StoreSpecialHours{{StartDate: "2026-10-19", EndDate: "2026-10-19", OpensAt: "20:00", ClosesAt: "01:00"}},
+			wantOpen: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := &storeCalendar{location: newYork, openingHours: tt.hours, specialHours: tt.special}
+			open, reason, next := calendar.status(&tt.store, tt.at)
+			if open != tt.wantOpen || reason != tt.wantReason {
+				t.Errorf("status() = %v, %q, want %v, %q", open, reason, tt.wantOpen, tt.wantReason)
+			}
+			switch {
+			case next == nil && tt.wantNext == nil:
+			case next == nil || tt.wantNext == nil || !next.Equal(*tt.wantNext):
+				t.Errorf("status() next opening = %v, want %v", next, tt.wantNext)
+			}
+		})
+	}
+}
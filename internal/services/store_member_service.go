@@ -0,0 +1,342 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/mailer"
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+	"github.com/ruranjo/unientrega/internal/utils"
+)
+
+// ErrLastOwner is returned when a change would leave a store without owners
+var ErrLastOwner = errors.New("a store must keep at least one owner")
+
+// StoreMemberService handles store staff: members, their roles and email invitations.
+// Owners manage every member; managers manage staff only.
+type StoreMemberService struct {
+	txManager     *repository.TxManager
+	storeRepo     *repository.StoreRepository
+	memberRepo    *repository.StoreMemberRepository
+	userRepo      *repository.UserRepository
+	access        *StoreAccess
+	mailer        mailer.Mailer
+	invitationTTL time.Duration
+}
+
+// NewStoreMemberService creates a new store member service
+func NewStoreMemberService(
+	txManager *repository.TxManager,
+	storeRepo *repository.StoreRepository,
+	memberRepo *repository.StoreMemberRepository,
+	userRepo *repository.UserRepository,
+	access *StoreAccess,
+	mailer mailer.Mailer,
+	invitationTTL time.Duration,
+) *StoreMemberService {
+	return &StoreMemberService{
+		txManager:     txManager,
+		storeRepo:     storeRepo,
+		memberRepo:    memberRepo,
+		userRepo:      userRepo,
+		access:        access,
+		mailer:        mailer,
+		invitationTTL: invitationTTL,
+	}
+}
+
+// InviteMemberRequest represents an invitation to join a store
+type InviteMemberRequest struct {
+	Email string                 `json:"email" binding:"required,email"`
+	Role  models.StoreMemberRole `json:"role" binding:"required"`
+}
+
+// UpdateMemberRequest represents a role change of a store member
+type UpdateMemberRequest struct {
+	Role models.StoreMemberRole `json:"role" binding:"required"`
+}
+
+// AcceptInvitationRequest represents the acceptance of a store invitation
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ListMembers returns the members of a store (any member or superuser)
+func (s *StoreMemberService) ListMembers(storeID, userID uuid.UUID, role models.Role) ([]models.StoreMember, error) {
+	if _, err := s.storeRepo.GetByID(storeID); err != nil {
+		return nil, errors.New("store not found")
+	}
+	if role != models.RoleSuperUser {
+		member, err := s.access.Membership(storeID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if member == nil {
+			return nil, errors.New("permission denied")
+		}
+	}
+	return s.memberRepo.ListByStore(storeID)
+}
+
+// ListUserMemberships returns the stores a user works at
+func (s *StoreMemberService) ListUserMemberships(userID uuid.UUID) ([]models.StoreMember, error) {
+	return s.memberRepo.ListByUser(userID)
+}
+
+// InviteMember emails an invitation to join a store with a role.
+// A new invitation replaces the pending invitations of the same email address.
+func (s *StoreMemberService) InviteMember(storeID, userID uuid.UUID, role models.Role, req *InviteMemberRequest) (*models.StoreInvitation, error) {
+	if !req.Role.IsValid() {
+		return nil, errors.New("invalid member role")
+	}
+	store, err := s.access.Authorize(storeID, userID, role, models.PermissionManageMembers)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCanAssign(storeID, userID, role, req.Role); err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if user, err := s.userRepo.GetByEmail(email); err == nil {
+		member, err := s.access.Membership(storeID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if member != nil {
+			return nil, errors.New("user is already a member of the store")
+		}
+	}
+
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	invitation := &models.StoreInvitation{
+		StoreID:   storeID,
+		Email:     email,
+		Role:      req.Role,
+		TokenHash: utils.HashToken(token),
+		Status:    models.StoreInvitationPending,
+		InvitedBy: userID,
+		ExpiresAt: time.Now().Add(s.invitationTTL),
+	}
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		memberRepo := s.memberRepo.WithTx(tx)
+		if err := memberRepo.RevokePendingByEmail(storeID, email); err != nil {
+			return err
+		}
+		return memberRepo.CreateInvitation(invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mailer.Send(invitationEmail(store, invitation, token)); err != nil {
+		log.Printf("Failed to send store invitation %s: %v", invitation.ID, err)
+		if _, revokeErr := s.memberRepo.RevokeInvitation(invitation.ID); revokeErr != nil {
+			log.Printf("Failed to revoke unsent store invitation %s: %v", invitation.ID, revokeErr)
+		}
+		return nil, errors.New("failed to send invitation email")
+	}
+
+	return invitation, nil
+}
+
+// ListInvitations returns the pending invitations of a store
+func (s *StoreMemberService) ListInvitations(storeID, userID uuid.UUID, role models.Role) ([]models.StoreInvitation, error) {
+	if _, err := s.access.Authorize(storeID, userID, role, models.PermissionManageMembers); err != nil {
+		return nil, err
+	}
+	return s.memberRepo.ListPendingInvitations(storeID)
+}
+
+// RevokeInvitation cancels a pending invitation
+func (s *StoreMemberService) RevokeInvitation(storeID, invitationID, userID uuid.UUID, role models.Role) error {
+	if _, err := s.access.Authorize(storeID, userID, role, models.PermissionManageMembers); err != nil {
+		return err
+	}
+
+	invitation, err := s.memberRepo.GetInvitation(invitationID)
+	if err != nil {
+		return err
+	}
+	if invitation.StoreID != storeID {
+		return errors.New("invitation not found")
+	}
+	if err := s.checkCanAssign(storeID, userID, role, invitation.Role); err != nil {
+		return err
+	}
+
+	revoked, err := s.memberRepo.RevokeInvitation(invitation.ID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.New("invitation is no longer pending")
+	}
+	return nil
+}
+
+// AcceptInvitation adds the user to the store of an invitation sent to their email address.
+// Customers who join a store get the store role; they must sign in again to use store features.
+func (s *StoreMemberService) AcceptInvitation(userID uuid.UUID, req *AcceptInvitationRequest) (*models.StoreMember, error) {
+	invitation, err := s.memberRepo.GetInvitationByTokenHash(utils.HashToken(strings.TrimSpace(req.Token)))
+	if err != nil {
+		return nil, errors.New("invalid invitation")
+	}
+	if invitation.Status != models.StoreInvitationPending || invitation.IsExpired() {
+		return nil, errors.New("invitation has expired or is no longer valid")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, errors.New("permission denied")
+	}
+
+	var member *models.StoreMember
+	err = s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		memberRepo := s.memberRepo.WithTx(tx)
+		accepted, err := memberRepo.AcceptInvitation(invitation.ID, userID, time.Now())
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return errors.New("invitation has expired or is no longer valid")
+		}
+
+		member = &models.StoreMember{
+			StoreID:   invitation.StoreID,
+			UserID:    userID,
+			Role:      invitation.Role,
+			InvitedBy: &invitation.InvitedBy,
+		}
+		if err := memberRepo.Create(member); err != nil {
+			return err
+		}
+
+		if user.Role == models.RoleClient {
+			return s.userRepo.WithTx(tx).UpdateRole(userID, models.RoleStore)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// UpdateMember changes the role of a store member
+func (s *StoreMemberService) UpdateMember(storeID, memberUserID, userID uuid.UUID, role models.Role, req *UpdateMemberRequest) (*models.StoreMember, error) {
+	if !req.Role.IsValid() {
+		return nil, errors.New("invalid member role")
+	}
+	if _, err := s.access.Authorize(storeID, userID, role, models.PermissionManageMembers); err != nil {
+		return nil, err
+	}
+
+	member, err := s.memberRepo.GetByStoreAndUser(storeID, memberUserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCanAssign(storeID, userID, role, member.Role); err != nil {
+		return nil, err
+	}
+	if err := s.checkCanAssign(storeID, userID, role, req.Role); err != nil {
+		return nil, err
+	}
+	if member.Role == models.StoreMemberOwner && req.Role != models.StoreMemberOwner {
+		if err := s.checkNotLastOwner(storeID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.memberRepo.UpdateRole(member.ID, req.Role); err != nil {
+		return nil, err
+	}
+	member.Role = req.Role
+	return member, nil
+}
+
+// RemoveMember removes a member from a store. Members may always leave a store themselves.
+func (s *StoreMemberService) RemoveMember(storeID, memberUserID, userID uuid.UUID, role models.Role) error {
+	if _, err := s.storeRepo.GetByID(storeID); err != nil {
+		return errors.New("store not found")
+	}
+
+	member, err := s.memberRepo.GetByStoreAndUser(storeID, memberUserID)
+	if err != nil {
+		return err
+	}
+	if memberUserID != userID {
+		if _, err := s.access.Authorize(storeID, userID, role, models.PermissionManageMembers); err != nil {
+			return err
+		}
+		if err := s.checkCanAssign(storeID, userID, role, member.Role); err != nil {
+			return err
+		}
+	}
+	if member.Role == models.StoreMemberOwner {
+		if err := s.checkNotLastOwner(storeID); err != nil {
+			return err
+		}
+	}
+
+	return s.memberRepo.Delete(member.ID)
+}
+
+// checkCanAssign checks that the user may grant, change or remove a member role.
+// Owners and superusers manage every role; other members only roles below their own.
+func (s *StoreMemberService) checkCanAssign(storeID, userID uuid.UUID, role models.Role, memberRole models.StoreMemberRole) error {
+	if role == models.RoleSuperUser {
+		return nil
+	}
+	actor, err := s.access.Membership(storeID, userID)
+	if err != nil {
+		return err
+	}
+	if actor == nil {
+		return errors.New("permission denied")
+	}
+	if actor.Role != models.StoreMemberOwner && memberRole.Rank() >= actor.Role.Rank() {
+		return errors.New("permission denied")
+	}
+	return nil
+}
+
+// checkNotLastOwner fails if the store has a single owner
+func (s *StoreMemberService) checkNotLastOwner(storeID uuid.UUID) error {
+	owners, err := s.memberRepo.CountByRole(storeID, models.StoreMemberOwner)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// invitationEmail builds the email carrying the acceptance token of an invitation
+func invitationEmail(store *models.Store, invitation *models.StoreInvitation, token string) mailer.Message {
+	return mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You have been invited to join %s", store.Name),
+		Body: fmt.Sprintf(
+			"You have been invited to join %s as %s.\n\n"+
+				"Sign in with %s and accept the invitation with this token:\n\n%s\n\n"+
+				"The invitation expires on %s.\n",
+			store.Name, invitation.Role, invitation.Email, token, invitation.ExpiresAt.Format("2006-01-02 15:04 MST"),
+		),
+	}
+}
@@ -3,28 +3,46 @@ package services
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"github.com/ruranjo/unientrega/internal/models"
 	"github.com/ruranjo/unientrega/internal/repository"
+	"github.com/ruranjo/unientrega/internal/utils"
 )
 
 // StoreService handles business logic for stores
 type StoreService struct {
-	storeRepo *repository.StoreRepository
-	userRepo  *repository.UserRepository
+	txManager    *repository.TxManager
+	storeRepo    *repository.StoreRepository
+	userRepo     *repository.UserRepository
+	locationRepo *repository.LocationRepository
+	memberRepo   *repository.StoreMemberRepository
+	access       *StoreAccess
 }
 
 // NewStoreService creates a new store service
-func NewStoreService(storeRepo *repository.StoreRepository, userRepo *repository.UserRepository) *StoreService {
+func NewStoreService(
+	txManager *repository.TxManager,
+	storeRepo *repository.StoreRepository,
+	userRepo *repository.UserRepository,
+	locationRepo *repository.LocationRepository,
+	memberRepo *repository.StoreMemberRepository,
+	access *StoreAccess,
+) *StoreService {
 	return &StoreService{
-		storeRepo: storeRepo,
-		userRepo:  userRepo,
+		txManager:    txManager,
+		storeRepo:    storeRepo,
+		userRepo:     userRepo,
+		locationRepo: locationRepo,
+		memberRepo:   memberRepo,
+		access:       access,
 	}
 }
 
-// CreateStore creates a new store with validation
+// CreateStore creates a new store with validation. The creator becomes its first owner.
 func (s *StoreService) CreateStore(store *models.Store) error {
 	// Validate store name
 	if strings.TrimSpace(store.Name) == "" {
@@ -41,7 +59,30 @@ func (s *StoreService) CreateStore(store *models.Store) error {
 		return errors.New("owner ID is required")
 	}
 
-	return s.storeRepo.Create(store)
+	// Validate catalog location if provided
+	if err := s.validateLocation(store); err != nil {
+		return err
+	}
+
+	// Validate the time zone of the opening hours
+	store.TimeZone = strings.TrimSpace(store.TimeZone)
+	if store.TimeZone == "" {
+		store.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(store.TimeZone); err != nil {
+		return errors.New("invalid time zone: " + store.TimeZone)
+	}
+
+	return s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.storeRepo.WithTx(tx).Create(store); err != nil {
+			return err
+		}
+		return s.memberRepo.WithTx(tx).Create(&models.StoreMember{
+			StoreID: store.ID,
+			UserID:  store.OwnerID,
+			Role:    models.StoreMemberOwner,
+		})
+	})
 }
 
 // GetStoreByID retrieves a store by ID
@@ -54,6 +95,11 @@ func (s *StoreService) GetStoresByOwner(ownerID uuid.UUID) ([]*models.Store, err
 	return s.storeRepo.GetByOwnerID(ownerID)
 }
 
+// AuthorizeStore checks that the user has a permission at a store and returns the store
+func (s *StoreService) AuthorizeStore(storeID, userID uuid.UUID, role models.Role, permission models.StorePermission) (*models.Store, error) {
+	return s.access.Authorize(storeID, userID, role, permission)
+}
+
 // UpdateStore updates a store with validation
 func (s *StoreService) UpdateStore(store *models.Store) error {
 	// Validate store name
@@ -67,6 +113,11 @@ func (s *StoreService) UpdateStore(store *models.Store) error {
 		return err
 	}
 
+	// Validate catalog location if provided
+	if err := s.validateLocation(store); err != nil {
+		return err
+	}
+
 	return s.storeRepo.Update(store)
 }
 
@@ -81,35 +132,39 @@ func (s *StoreService) DeleteStore(id uuid.UUID) error {
 	return s.storeRepo.Delete(id)
 }
 
-// ListStores returns a list of stores with optional filters
-func (s *StoreService) ListStores(limit, offset int, activeOnly bool) ([]*models.Store, error) {
-	// Set default limit if not provided or invalid
-	if limit <= 0 {
-		limit = 10
-	}
-	// Cap maximum limit
-	if limit > 100 {
-		limit = 100
-	}
-
-	// Ensure offset is non-negative
-	if offset < 0 {
-		offset = 0
+// ListStores returns a page of the stores matching a list query and the cursor of the next page
+func (s *StoreService) ListStores(limit, offset int, cursor string, list utils.ListQuery) ([]*models.Store, string, error) {
+	page, err := newPage(limit, offset, cursor, 10, 100)
+	if err != nil {
+		return nil, "", err
 	}
-
-	return s.storeRepo.List(limit, offset, activeOnly)
+	return s.storeRepo.List(page, list)
 }
 
-// CountStores returns the total number of stores
-func (s *StoreService) CountStores(activeOnly bool) (int64, error) {
-	return s.storeRepo.Count(activeOnly)
+// CountStores returns the total number of stores matching the filters of a list query
+func (s *StoreService) CountStores(list utils.ListQuery) (int64, error) {
+	return s.storeRepo.Count(list)
 }
 
-// IsStoreOwner checks if a user is the owner of a store
+// IsStoreOwner checks if a user is an owner member of a store
 func (s *StoreService) IsStoreOwner(userID, storeID uuid.UUID) (bool, error) {
-	store, err := s.storeRepo.GetByID(storeID)
-	if err != nil {
+	if _, err := s.storeRepo.GetByID(storeID); err != nil {
 		return false, err
 	}
-	return store.OwnerID == userID, nil
+	member, err := s.access.Membership(storeID, userID)
+	if err != nil || member == nil {
+		return false, err
+	}
+	return member.Role == models.StoreMemberOwner, nil
+}
+
+// validateLocation checks that the catalog location of a store exists
+func (s *StoreService) validateLocation(store *models.Store) error {
+	if store.LocationID == nil {
+		return nil
+	}
+	if _, err := s.locationRepo.GetByID(*store.LocationID); err != nil {
+		return errors.New("invalid location ID")
+	}
+	return nil
 }
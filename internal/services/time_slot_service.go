@@ -0,0 +1,240 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/ruranjo/unientrega/internal/models"
+	"github.com/ruranjo/unientrega/internal/repository"
+)
+
+// ErrSchedulingNotOffered is returned when a store does not take scheduled orders
+var ErrSchedulingNotOffered = errors.New("store does not take scheduled orders")
+
+// ErrSlotFull is returned when a time slot has no capacity left
+var ErrSlotFull = errors.New("time slot is fully booked")
+
+const (
+	defaultSlotLeadMinutes  = 15
+	defaultSlotMaxDaysAhead = 7
+)
+
+// slotLength is the duration of a scheduling time slot
+const slotLength = models.SlotMinutes * time.Minute
+
+// TimeSlotService handles scheduled pickup and delivery time slots.
+// Slots are cut from the store opening hours and hold a limited number of orders each.
+type TimeSlotService struct {
+	storeRepo  *repository.StoreRepository
+	slotRepo   *repository.TimeSlotRepository
+	storeHours *StoreHoursService
+	access     *StoreAccess
+}
+
+// NewTimeSlotService creates a new time slot service
+func NewTimeSlotService(storeRepo *repository.StoreRepository, slotRepo *repository.TimeSlotRepository, storeHours *StoreHoursService, access *StoreAccess) *TimeSlotService {
+	return &TimeSlotService{
+		storeRepo:  storeRepo,
+		slotRepo:   slotRepo,
+		storeHours: storeHours,
+		access:     access,
+	}
+}
+
+// TimeSlotSettingsRequest represents the scheduled order settings of a store
+type TimeSlotSettingsRequest struct {
+	Capacity     int `json:"capacity" binding:"min=0"` // Maximum orders per 15-minute slot, 0 disables scheduling
+	LeadMinutes  int `json:"lead_minutes"`             // Defaults to 15
+	MaxDaysAhead int `json:"max_days_ahead"`           // Defaults to 7
+}
+
+// TimeSlot is a schedulable slot with its current availability
+type TimeSlot struct {
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Capacity    int       `json:"capacity"`
+	Booked      int       `json:"booked"`
+	Available   int       `json:"available"`
+	IsAvailable bool      `json:"is_available"` // Has capacity left and can still be booked
+}
+
+// GetSettings returns the scheduled order settings of a store
+func (s *TimeSlotService) GetSettings(storeID uuid.UUID) (*models.TimeSlotSettings, error) {
+	settings, err := s.slotRepo.GetSettings(storeID)
+	if err != nil {
+		return nil, ErrSchedulingNotOffered
+	}
+	return settings, nil
+}
+
+// SetSettings configures scheduled orders of a store (store owners and managers, or superuser)
+func (s *TimeSlotService) SetSettings(storeID, userID uuid.UUID, role models.Role, req *TimeSlotSettingsRequest) (*models.TimeSlotSettings, error) {
+	if _, err := s.access.Authorize(storeID, userID, role, models.PermissionManageSchedule); err != nil {
+		return nil, err
+	}
+
+	settings := &models.TimeSlotSettings{
+		StoreID:      storeID,
+		Capacity:     req.Capacity,
+		LeadMinutes:  req.LeadMinutes,
+		MaxDaysAhead: req.MaxDaysAhead,
+	}
+	if settings.LeadMinutes == 0 {
+		settings.LeadMinutes = defaultSlotLeadMinutes
+	}
+	if settings.MaxDaysAhead == 0 {
+		settings.MaxDaysAhead = defaultSlotMaxDaysAhead
+	}
+	if settings.Capacity < 0 || settings.LeadMinutes < 0 || settings.MaxDaysAhead < 0 {
+		return nil, errors.New("capacity, lead minutes and max days ahead must not be negative")
+	}
+
+	if err := s.slotRepo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+	return s.slotRepo.GetSettings(storeID)
+}
+
+// ListSlots returns the time slots of a store starting on a date (YYYY-MM-DD, store time zone).
+// An empty date lists the slots of the current day.
+func (s *TimeSlotService) ListSlots(storeID uuid.UUID, date string) ([]TimeSlot, error) {
+	store, err := s.storeRepo.GetByID(storeID)
+	if err != nil {
+		return nil, errors.New("store not found")
+	}
+	settings, err := s.GetSettings(storeID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Capacity == 0 {
+		return nil, ErrSchedulingNotOffered
+	}
+
+	location, err := time.LoadLocation(store.TimeZone)
+	if err != nil {
+		return nil, errors.New("invalid store time zone: " + store.TimeZone)
+	}
+	if date == "" {
+		date = time.Now().In(location).Format(dateLayout)
+	}
+	day, err := time.ParseInLocation(dateLayout, date, location)
+	if err != nil {
+		return nil, errors.New("date must use the YYYY-MM-DD format")
+	}
+	nextDay := day.AddDate(0, 0, 1)
+
+	calendar, err := s.storeHours.loadCalendar(store, day)
+	if err != nil {
+		return nil, err
+	}
+
+	// Periods of the previous day may run past midnight into the requested date
+	periods := append(calendar.periodsOn(day.AddDate(0, 0, -1)), calendar.periodsOn(day)...)
+	starts := make(map[time.Time]bool)
+	for _, period := range periods {
+		for start := ceilToSlot(period.start); !start.Add(slotLength).After(period.end); start = start.Add(slotLength) {
+			if !start.Before(day) && start.Before(nextDay) {
+				starts[start] = true
+			}
+		}
+	}
+
+	bookings, err := s.slotRepo.ListBookings(storeID, day, nextDay)
+	if err != nil {
+		return nil, err
+	}
+	booked := make(map[int64]int, len(bookings))
+	for _, booking := range bookings {
+		booked[booking.StartsAt.Unix()] = booking.Booked
+	}
+
+	now := time.Now()
+	slots := make([]TimeSlot, 0, len(starts))
+	for start := range starts {
+		slot := TimeSlot{
+			StartsAt: start,
+			EndsAt:   start.Add(slotLength),
+			Capacity: settings.Capacity,
+			Booked:   booked[start.Unix()],
+		}
+		slot.Available = slot.Capacity - slot.Booked
+		if slot.Available < 0 {
+			slot.Available = 0
+		}
+		if slot.Available > 0 && s.bookable(settings, start, now) {
+			open, _, _ := calendar.status(store, start)
+			slot.IsAvailable = open
+		}
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].StartsAt.Before(slots[j].StartsAt) })
+	return slots, nil
+}
+
+// ValidateSlot checks that an order can be scheduled at the given time and returns
+// the scheduling settings of the store. Capacity is checked when the slot is reserved.
+func (s *TimeSlotService) ValidateSlot(store *models.Store, at time.Time) (*models.TimeSlotSettings, error) {
+	settings, err := s.GetSettings(store.ID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Capacity == 0 {
+		return nil, ErrSchedulingNotOffered
+	}
+
+	if !ceilToSlot(at).Equal(at) {
+		return nil, fmt.Errorf("scheduled time must be the start of a %d-minute slot", models.SlotMinutes)
+	}
+	now := time.Now()
+	if !s.bookable(settings, at, now) {
+		return nil, fmt.Errorf("scheduled time must be between %d minutes and %d days from now", settings.LeadMinutes, settings.MaxDaysAhead)
+	}
+
+	// The whole slot must fall within the opening hours
+	if err := s.storeHours.CheckOpen(store, at); err != nil {
+		return nil, err
+	}
+	if err := s.storeHours.CheckOpen(store, at.Add(slotLength-time.Minute)); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// reserve books one order in a slot, inside the order transaction
+func (s *TimeSlotService) reserve(tx *gorm.DB, storeID uuid.UUID, startsAt time.Time, capacity int) error {
+	ok, err := s.slotRepo.WithTx(tx).Reserve(storeID, startsAt, capacity)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSlotFull
+	}
+	return nil
+}
+
+// release frees the capacity booked by a cancelled order, inside the cancellation transaction
+func (s *TimeSlotService) release(tx *gorm.DB, storeID uuid.UUID, startsAt time.Time) error {
+	return s.slotRepo.WithTx(tx).Release(storeID, startsAt)
+}
+
+// bookable reports whether a slot is within the lead time and booking window of the store
+func (s *TimeSlotService) bookable(settings *models.TimeSlotSettings, start, now time.Time) bool {
+	earliest := now.Add(time.Duration(settings.LeadMinutes) * time.Minute)
+	latest := now.AddDate(0, 0, settings.MaxDaysAhead)
+	return !start.Before(earliest) && !start.After(latest)
+}
+
+// ceilToSlot rounds a time up to the start of a slot.
+// Time zone offsets are multiples of 15 minutes, so slots align with the local clock.
+func ceilToSlot(t time.Time) time.Time {
+	start := t.Truncate(slotLength)
+	if start.Before(t) {
+		start = start.Add(slotLength)
+	}
+	return start
+}
@@ -6,22 +6,28 @@ import (
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
 	"github.com/ruranjo/unientrega/internal/models"
 	"github.com/ruranjo/unientrega/internal/repository"
+	"github.com/ruranjo/unientrega/internal/utils"
 )
 
 // UserService handles business logic for users
 type UserService struct {
+	txManager         *repository.TxManager
 	userRepo          *repository.UserRepository
 	passwordResetRepo *repository.PasswordResetRepository
+	sessionRepo       *repository.SessionRepository
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo *repository.UserRepository, passwordResetRepo *repository.PasswordResetRepository) *UserService {
+func NewUserService(txManager *repository.TxManager, userRepo *repository.UserRepository, passwordResetRepo *repository.PasswordResetRepository, sessionRepo *repository.SessionRepository) *UserService {
 	return &UserService{
+		txManager:         txManager,
 		userRepo:          userRepo,
 		passwordResetRepo: passwordResetRepo,
+		sessionRepo:       sessionRepo,
 	}
 }
 
@@ -70,20 +76,32 @@ func (s *UserService) UpdateUser(user *models.User) error {
 	return s.userRepo.Update(user)
 }
 
-// UpdatePassword updates a user's password
-func (s *UserService) UpdatePassword(userID uuid.UUID, newPassword string) error {
-	user, err := s.userRepo.GetByID(userID)
+// UpdatePassword updates a user's password and signs the user out of every other session,
+// keeping the session the change was made from. Both happen in one transaction.
+func (s *UserService) UpdatePassword(userID uuid.UUID, newPassword string, currentSessionID uuid.UUID) error {
+	hashedPassword, err := HashPassword(newPassword)
 	if err != nil {
 		return err
 	}
 
-	hashedPassword, err := HashPassword(newPassword)
+	return s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.setPassword(s.userRepo.WithTx(tx), userID, hashedPassword); err != nil {
+			return err
+		}
+		_, err := s.sessionRepo.WithTx(tx).RevokeByUser(userID, currentSessionID, models.SessionPasswordChange)
+		return err
+	})
+}
+
+// setPassword stores the hash of a new password of a user
+func (s *UserService) setPassword(userRepo *repository.UserRepository, userID uuid.UUID, hashedPassword string) error {
+	user, err := userRepo.GetByID(userID)
 	if err != nil {
 		return err
 	}
 
 	user.Password = hashedPassword
-	return s.userRepo.Update(user)
+	return userRepo.Update(user)
 }
 
 // DeleteUser soft deletes a user
@@ -91,9 +109,18 @@ func (s *UserService) DeleteUser(id uuid.UUID) error {
 	return s.userRepo.Delete(id)
 }
 
-// ListUsers returns a list of users with pagination
-func (s *UserService) ListUsers(limit, offset int, role models.Role) ([]*models.User, error) {
-	return s.userRepo.List(limit, offset, role)
+// ListUsers returns a page of the users matching a list query and the cursor of the next page
+func (s *UserService) ListUsers(limit, offset int, cursor string, list utils.ListQuery) ([]*models.User, string, error) {
+	page, err := newPage(limit, offset, cursor, 10, 100)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.userRepo.List(page, list)
+}
+
+// CountUsers returns the total number of users matching the filters of a list query
+func (s *UserService) CountUsers(list utils.ListQuery) (int64, error) {
+	return s.userRepo.Count(list)
 }
 
 // AuthenticateUser validates user credentials
@@ -206,19 +233,32 @@ func (s *UserService) ValidateResetToken(token string) (*models.PasswordReset, e
 	return reset, nil
 }
 
-// ResetPasswordWithToken resets a user's password using a valid token
+// ResetPasswordWithToken resets a user's password using a valid token and signs the user
+// out of every session, all in one transaction
 func (s *UserService) ResetPasswordWithToken(token, newPassword string) error {
 	reset, err := s.ValidateResetToken(token)
 	if err != nil {
 		return err
 	}
 
-	// Update user password
-	err = s.UpdatePassword(reset.UserID, newPassword)
+	hashedPassword, err := HashPassword(newPassword)
 	if err != nil {
 		return err
 	}
 
-	// Mark token as used
-	return s.passwordResetRepo.MarkAsUsed(token)
+	return s.txManager.WithinTransaction(func(tx *gorm.DB) error {
+		// Update user password
+		if err := s.setPassword(s.userRepo.WithTx(tx), reset.UserID, hashedPassword); err != nil {
+			return err
+		}
+
+		// Mark token as used
+		if err := s.passwordResetRepo.WithTx(tx).MarkAsUsed(token); err != nil {
+			return err
+		}
+
+		// Whoever knew the old password may still hold a refresh token
+		_, err := s.sessionRepo.WithTx(tx).RevokeByUser(reset.UserID, uuid.Nil, models.SessionPasswordReset)
+		return err
+	})
 }
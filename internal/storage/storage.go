@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ruranjo/unientrega/internal/config"
+)
+
+// ErrNotFound is returned when a stored object does not exist
+var ErrNotFound = errors.New("file not found")
+
+// Storage stores uploaded files under keys such as "print-jobs/<id>.pdf".
+// Implementations must be safe for concurrent use.
+type Storage interface {
+	// Save writes the content of r under key, replacing any existing file, and returns the written size
+	Save(key string, r io.Reader) (int64, error)
+	// Open returns the content stored under key, or ErrNotFound
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the file stored under key; deleting a missing file is not an error
+	Delete(key string) error
+}
+
+// New returns the storage backend selected in the configuration
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.LocalPath)
+	}
+	return nil, errors.New("unknown storage driver: " + cfg.Driver)
+}
@@ -14,9 +14,10 @@ var jwtSecret = []byte("your-secret-key-change-this-in-production")
 
 // Custom claims structure
 type Claims struct {
-	UserID uuid.UUID   `json:"user_id"`
-	Email  string      `json:"email"`
-	Role   models.Role `json:"role"`
+	UserID    uuid.UUID   `json:"user_id"`
+	Email     string      `json:"email"`
+	Role      models.Role `json:"role"`
+	SessionID uuid.UUID   `json:"sid"` // Session the token was issued to
 	jwt.RegisteredClaims
 }
 
@@ -25,12 +26,13 @@ func SetJWTSecret(secret string) {
 	jwtSecret = []byte(secret)
 }
 
-// GenerateToken generates a JWT access token (15 minutes expiry)
-func GenerateToken(userID uuid.UUID, email string, role models.Role) (string, error) {
+// GenerateToken generates a JWT access token (15 minutes expiry) for a session
+func GenerateToken(userID uuid.UUID, email string, role models.Role, sessionID uuid.UUID) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -42,18 +44,6 @@ func GenerateToken(userID uuid.UUID, email string, role models.Role) (string, er
 	return token.SignedString(jwtSecret)
 }
 
-// GenerateRefreshToken generates a refresh token (7 days expiry)
-func GenerateRefreshToken(userID uuid.UUID) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Subject:   userID.String(),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
-}
-
 // ValidateToken validates and parses a JWT token
 func ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
@@ -75,30 +65,6 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
-// ValidateRefreshToken validates a refresh token and returns the user ID
-func ValidateRefreshToken(tokenString string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-		return jwtSecret, nil
-	})
-
-	if err != nil {
-		return uuid.Nil, err
-	}
-
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
-		userID, err := uuid.Parse(claims.Subject)
-		if err != nil {
-			return uuid.Nil, errors.New("invalid user ID in token")
-		}
-		return userID, nil
-	}
-
-	return uuid.Nil, errors.New("invalid refresh token")
-}
-
 // ExtractClaims extracts claims from a token without full validation (for expired tokens)
 func ExtractClaims(tokenString string) (*Claims, error) {
 	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &Claims{})
@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidListQuery is wrapped by the errors of list queries with unknown fields,
+// operators or values
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+// FilterOperator compares a field with the values of a filter
+type FilterOperator string
+
+// FilterOperator constants
+const (
+	FilterEq       FilterOperator = "eq"
+	FilterNe       FilterOperator = "ne"
+	FilterGt       FilterOperator = "gt"
+	FilterGte      FilterOperator = "gte"
+	FilterLt       FilterOperator = "lt"
+	FilterLte      FilterOperator = "lte"
+	FilterIn       FilterOperator = "in"
+	FilterContains FilterOperator = "contains"
+)
+
+// IsValid checks if the operator is supported
+func (o FilterOperator) IsValid() bool {
+	switch o {
+	case FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte, FilterIn, FilterContains:
+		return true
+	}
+	return false
+}
+
+// Filter is a condition on one field of a list, e.g. price[gte]=500
+type Filter struct {
+	Field    string
+	Operator FilterOperator
+	Values   []string // A single value, except for the in operator
+}
+
+// SortField orders a list by one field
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListQuery holds the filters, sort order and field selection of a list request.
+// It is checked against the fields a list supports when the list is queried.
+type ListQuery struct {
+	Filters []Filter
+	Sort    []SortField
+	Fields  []string // JSON fields to return, empty for all
+}
+
+// AddFilter adds a filter to the query, e.g. for a parameter a handler reads itself
+func (q *ListQuery) AddFilter(field string, operator FilterOperator, values ...string) {
+	q.Filters = append(q.Filters, Filter{Field: field, Operator: operator, Values: values})
+}
+
+// listQueryParams are query parameters that are never filters
+var listQueryParams = map[string]bool{"limit": true, "offset": true, "cursor": true, "sort": true, "fields": true}
+
+var filterParamPattern = regexp.MustCompile(`^([a-z_]+)(?:\[([a-z]+)\])?$`)
+
+// ParseListQuery reads a list query from the query parameters of a request:
+//
+//	status=ready or status[eq]=ready    equality, the default operator
+//	created_at[gte]=2024-01-01           ne, gt, gte, lt, lte and contains work alike
+//	status[in]=pending,ready             any of several values
+//	sort=-created_at,name                sort fields, descending with a leading minus
+//	fields=id,name,price                 JSON fields to return
+//
+// Parameters the handler reads itself are passed as ignored and are not treated as filters.
+func ParseListQuery(values url.Values, ignored ...string) (ListQuery, error) {
+	var query ListQuery
+
+	skip := make(map[string]bool, len(ignored))
+	for _, name := range ignored {
+		skip[name] = true
+	}
+
+	for param, paramValues := range values {
+		if listQueryParams[param] || skip[param] {
+			continue
+		}
+		match := filterParamPattern.FindStringSubmatch(param)
+		if match == nil {
+			return ListQuery{}, fmt.Errorf("%w: invalid filter %q", ErrInvalidListQuery, param)
+		}
+
+		filter := Filter{Field: match[1], Operator: FilterOperator(match[2])}
+		if filter.Operator == "" {
+			filter.Operator = FilterEq
+		}
+		if !filter.Operator.IsValid() {
+			return ListQuery{}, fmt.Errorf("%w: unknown operator %q", ErrInvalidListQuery, match[2])
+		}
+
+		if filter.Operator == FilterIn {
+			for _, value := range paramValues {
+				filter.Values = append(filter.Values, splitList(value)...)
+			}
+			if len(filter.Values) == 0 {
+				return ListQuery{}, fmt.Errorf("%w: filter %q needs at least one value", ErrInvalidListQuery, param)
+			}
+		} else {
+			if len(paramValues) != 1 {
+				return ListQuery{}, fmt.Errorf("%w: filter %q accepts a single value", ErrInvalidListQuery, param)
+			}
+			// An empty value filters nothing, as if the parameter was not sent
+			if strings.TrimSpace(paramValues[0]) == "" {
+				continue
+			}
+			filter.Values = paramValues
+		}
+		query.Filters = append(query.Filters, filter)
+	}
+
+	// Map iteration order is random; keep the generated SQL stable
+	sort.Slice(query.Filters, func(i, j int) bool {
+		if query.Filters[i].Field != query.Filters[j].Field {
+			return query.Filters[i].Field < query.Filters[j].Field
+		}
+		return query.Filters[i].Operator < query.Filters[j].Operator
+	})
+
+	for _, field := range splitList(values.Get("sort")) {
+		desc := strings.HasPrefix(field, "-")
+		query.Sort = append(query.Sort, SortField{Field: strings.TrimPrefix(field, "-"), Desc: desc})
+	}
+	query.Fields = splitList(values.Get("fields"))
+
+	return query, nil
+}
+
+// SelectFields keeps only the given JSON fields of each item of a list, returning the list
+// unchanged when no fields are given. Fields the items do not have are rejected.
+func SelectFields(items interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(items))
+	for _, field := range fields {
+		if !known[field] {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidListQuery, field)
+		}
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	selected := make([]map[string]json.RawMessage, 0, len(rows))
+	for _, row := range rows {
+		item := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := row[field]; ok {
+				item[field] = value
+			}
+		}
+		selected = append(selected, item)
+	}
+	return selected, nil
+}
+
+// jsonFieldNames returns the JSON field names of the elements of a slice of structs
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Slice || t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	names := make(map[string]bool)
+	if t.Kind() != reflect.Struct {
+		return names
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// splitList splits a comma separated parameter, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
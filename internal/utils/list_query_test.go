@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		ignored []string
+		want    ListQuery
+		wantErr bool
+	}{
+		{name: "empty", query: ""},
+		{name: "equality by default", query: "status=ready",
+			want: ListQuery{Filters: []Filter{{Field: "status", Operator: FilterEq, Values: []string{"ready"}}}}},
+		{name: "explicit operator", query: "price[gte]=500",
+			want: ListQuery{Filters: []Filter{{Field: "price", Operator: FilterGte, Values: []string{"500"}}}}},
+		{name: "in with a list", query: "status[in]=pending,%20ready",
+			want: ListQuery{Filters: []Filter{{Field: "status", Operator: FilterIn, Values: []string{"pending", "ready"}}}}},
+		{name: "in with repeated parameters", query: "status[in]=pending&status[in]=ready,",
+			want: ListQuery{Filters: []Filter{{Field: "status", Operator: FilterIn, Values: []string{"pending", "ready"}}}}},
+		{name: "in without values", query: "status[in]=", wantErr: true},
+		{name: "in with only separators", query: "status[in]=,%20,", wantErr: true},
+		{name: "unknown operator", query: "status[like]=ready", wantErr: true},
+		{name: "empty operator", query: "status[]=ready", wantErr: true},
+		{name: "upper case operator", query: "status[EQ]=ready", wantErr: true},
+		{name: "invalid field", query: "Status=ready", wantErr: true},
+		{name: "nested brackets", query: "status[eq][in]=ready", wantErr: true},
+		{name: "repeated single value filter", query: "status=ready&status=pending", wantErr: true},
+		{name: "empty value filters nothing", query: "status=&name[contains]=%20"},
+		{name: "pagination parameters are not filters", query: "limit=10&offset=20&cursor=abc"},
+		{name: "ignored parameters", query: "store_id=1&status=ready", ignored: []string{"store_id"},
+			want: ListQuery{Filters: []Filter{{Field: "status", Operator: FilterEq, Values: []string{"ready"}}}}},
+		{name: "filters are sorted", query: "total[lte]=900&status=ready&total[gte]=100",
+			want: ListQuery{Filters: []Filter{
+				{Field: "status", Operator: FilterEq, Values: []string{"ready"}},
+				{Field: "total", Operator: FilterGte, Values: []string{"100"}},
+				{Field: "total", Operator: FilterLte, Values: []string{"900"}},
+			}}},
+		{name: "sort and fields", query: "sort=-created_at,%20name,&fields=id,,name",
+			want: ListQuery{
+				Sort:   []SortField{{Field: "created_at", Desc: true}, {Field: "name"}},
+				Fields: []string{"id", "name"},
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseListQuery(values, tt.ignored...)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidListQuery) {
+					t.Fatalf("ParseListQuery(%q) = %+v, %v, want ErrInvalidListQuery", tt.query, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseListQuery(%q) error = %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseListQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
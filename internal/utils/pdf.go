@@ -0,0 +1,826 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// ErrInvalidPDF is returned when a document is not a readable PDF file
+var ErrInvalidPDF = errors.New("file is not a valid PDF document")
+
+// ErrPDFTooComplex is returned when reaching the page tree of a document would inflate
+// more compressed data, or visit more page tree nodes, than allowed
+var ErrPDFTooComplex = errors.New("PDF document is too complex to count its pages")
+
+const (
+	// maxPDFInflatedSize limits how much all compressed streams together may expand while counting pages
+	maxPDFInflatedSize = 32 << 20
+	// maxPDFPageTreeNodes limits the number of page tree nodes visited while counting pages
+	maxPDFPageTreeNodes = 100000
+	// maxPDFXrefSections limits the number of cross-reference sections followed through /Prev
+	maxPDFXrefSections = 256
+)
+
+var pdfObjectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// CountPDFPages returns the number of pages of a PDF document.
+// It follows the cross-reference table from the trailer /Root to the page tree and counts
+// its leaves, which must agree with the /Count of the tree root. When the cross-reference
+// table is damaged the objects are located by scanning the file instead. Only the compressed
+// streams needed to reach the page tree are inflated, within a total size budget.
+func CountPDFPages(data []byte) (int, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\n\r "), []byte("%PDF-")) {
+		return 0, ErrInvalidPDF
+	}
+
+	budget := int64(maxPDFInflatedSize)
+	doc := newPDFDocument(data, &budget)
+	err := doc.loadXref()
+	pages := 0
+	if err == nil {
+		pages, err = doc.countPages()
+	}
+	if err != nil && !errors.Is(err, ErrPDFTooComplex) {
+		// Damaged or unusual cross-reference data, locate the objects by scanning the file
+		doc = newPDFDocument(data, &budget)
+		doc.rebuildXref()
+		pages, err = doc.countPages()
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrPDFTooComplex) || errors.Is(err, ErrInvalidPDF) {
+			return 0, err
+		}
+		return 0, ErrInvalidPDF
+	}
+	return pages, nil
+}
+
+// pdfXrefEntry locates an object of a document
+type pdfXrefEntry struct {
+	free       bool
+	compressed bool
+	offset     int // Byte offset of an uncompressed object
+	stream     int // Object stream holding a compressed object
+	index      int // Index of a compressed object in its stream
+}
+
+// pdfObjectStream is a decoded object stream
+type pdfObjectStream struct {
+	data    []byte
+	nums    []int // Object numbers, by index
+	offsets []int // Object offsets in data, by index
+}
+
+// pdfDocument resolves the objects of a PDF file through its cross-reference table
+type pdfDocument struct {
+	data       []byte
+	budget     *int64 // Bytes compressed streams may still inflate to
+	xref       map[int]pdfXrefEntry
+	trailer    pdfDict
+	objects    map[int]interface{}
+	objStreams map[int]*pdfObjectStream
+	resolving  map[int]bool
+
+	// Object streams not searched yet, when the cross-reference table was rebuilt by scanning
+	pendingObjStreams []int
+}
+
+func newPDFDocument(data []byte, budget *int64) *pdfDocument {
+	return &pdfDocument{
+		data:       data,
+		budget:     budget,
+		xref:       map[int]pdfXrefEntry{},
+		objects:    map[int]interface{}{},
+		objStreams: map[int]*pdfObjectStream{},
+		resolving:  map[int]bool{},
+	}
+}
+
+// countPages counts the leaves of the page tree and checks them against the /Count of its root
+func (d *pdfDocument) countPages() (int, error) {
+	if d.trailer == nil {
+		return 0, fmt.Errorf("%w: no trailer", ErrInvalidPDF)
+	}
+	catalog, err := d.resolveDict(d.trailer["Root"])
+	if err != nil {
+		return 0, err
+	}
+	root, err := d.resolveDict(catalog["Pages"])
+	if err != nil {
+		return 0, err
+	}
+	if root["Type"] != pdfName("Pages") {
+		return 0, fmt.Errorf("%w: no page tree", ErrInvalidPDF)
+	}
+	declared, err := d.resolveInt(root["Count"])
+	if err != nil {
+		return 0, err
+	}
+
+	visited := map[pdfRef]bool{}
+	if ref, ok := catalog["Pages"].(pdfRef); ok {
+		visited[ref] = true
+	}
+	pages, err := d.countLeaves(root, visited)
+	if err != nil {
+		return 0, err
+	}
+	if pages == 0 {
+		return 0, fmt.Errorf("%w: document has no pages", ErrInvalidPDF)
+	}
+	if pages != declared {
+		return 0, fmt.Errorf("%w: page count %d does not match the %d pages of the page tree", ErrInvalidPDF, declared, pages)
+	}
+	return pages, nil
+}
+
+// countLeaves counts the pages under a page tree node
+func (d *pdfDocument) countLeaves(node pdfDict, visited map[pdfRef]bool) (int, error) {
+	kids, err := d.resolve(node["Kids"])
+	if err != nil {
+		return 0, err
+	}
+	array, ok := kids.(pdfArray)
+	if !ok {
+		return 0, fmt.Errorf("%w: page tree node has no kids", ErrInvalidPDF)
+	}
+
+	pages := 0
+	for _, kid := range array {
+		ref, ok := kid.(pdfRef)
+		if !ok {
+			return 0, fmt.Errorf("%w: page tree kid is not a reference", ErrInvalidPDF)
+		}
+		if visited[ref] {
+			return 0, fmt.Errorf("%w: page tree has a cycle", ErrInvalidPDF)
+		}
+		visited[ref] = true
+		if len(visited) > maxPDFPageTreeNodes {
+			return 0, ErrPDFTooComplex
+		}
+
+		child, err := d.resolveDict(ref)
+		if err != nil {
+			return 0, err
+		}
+		switch child["Type"] {
+		case pdfName("Pages"):
+			count, err := d.countLeaves(child, visited)
+			if err != nil {
+				return 0, err
+			}
+			pages += count
+		case pdfName("Page"):
+			pages++
+		default:
+			return 0, fmt.Errorf("%w: page tree kid is neither a page nor a page tree node", ErrInvalidPDF)
+		}
+	}
+	return pages, nil
+}
+
+// resolve returns the object a reference points to, or the object itself if it is no reference
+func (d *pdfDocument) resolve(obj interface{}) (interface{}, error) {
+	ref, ok := obj.(pdfRef)
+	if !ok {
+		return obj, nil
+	}
+	if cached, ok := d.objects[ref.num]; ok {
+		return cached, nil
+	}
+	if d.resolving[ref.num] {
+		return nil, fmt.Errorf("%w: object %d refers to itself", ErrInvalidPDF, ref.num)
+	}
+	d.resolving[ref.num] = true
+	defer delete(d.resolving, ref.num)
+
+	resolved, err := d.load(ref.num)
+	if err != nil {
+		return nil, err
+	}
+	d.objects[ref.num] = resolved
+	return resolved, nil
+}
+
+// resolveDict resolves an object that must be a dictionary; streams give their dictionary
+func (d *pdfDocument) resolveDict(obj interface{}) (pdfDict, error) {
+	resolved, err := d.resolve(obj)
+	if err != nil {
+		return nil, err
+	}
+	switch value := resolved.(type) {
+	case pdfDict:
+		return value, nil
+	case *pdfStream:
+		return value.dict, nil
+	}
+	return nil, fmt.Errorf("%w: expected a dictionary", ErrInvalidPDF)
+}
+
+// resolveInt resolves an object that must be a non-negative integer
+func (d *pdfDocument) resolveInt(obj interface{}) (int, error) {
+	resolved, err := d.resolve(obj)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := resolved.(int)
+	if !ok || value < 0 {
+		return 0, fmt.Errorf("%w: expected a non-negative integer", ErrInvalidPDF)
+	}
+	return value, nil
+}
+
+// load reads an object from where the cross-reference table locates it
+func (d *pdfDocument) load(num int) (interface{}, error) {
+	entry, ok := d.xref[num]
+	for !ok && len(d.pendingObjStreams) > 0 {
+		if err := d.searchObjectStream(); err != nil {
+			return nil, err
+		}
+		entry, ok = d.xref[num]
+	}
+	if !ok || entry.free {
+		return nil, fmt.Errorf("%w: object %d not found", ErrInvalidPDF, num)
+	}
+
+	if entry.compressed {
+		stream, err := d.objectStream(entry.stream)
+		if err != nil {
+			return nil, err
+		}
+		index := entry.index
+		if index >= len(stream.nums) || stream.nums[index] != num {
+			index = -1
+			for i, n := range stream.nums {
+				if n == num {
+					index = i
+					break
+				}
+			}
+			if index < 0 {
+				return nil, fmt.Errorf("%w: object %d not found in its object stream", ErrInvalidPDF, num)
+			}
+		}
+		lexer := &pdfLexer{data: stream.data, pos: stream.offsets[index]}
+		return lexer.object(0)
+	}
+
+	found, obj, err := d.objectAt(entry.offset)
+	if err != nil {
+		return nil, err
+	}
+	if found != num {
+		return nil, fmt.Errorf("%w: object %d is not at its cross-reference offset", ErrInvalidPDF, num)
+	}
+	return obj, nil
+}
+
+// objectAt reads the indirect object starting at an offset of the file and returns its number
+func (d *pdfDocument) objectAt(offset int) (int, interface{}, error) {
+	if offset < 0 || offset >= len(d.data) {
+		return 0, nil, fmt.Errorf("%w: offset out of range", ErrInvalidPDF)
+	}
+	lexer := &pdfLexer{data: d.data, pos: offset}
+	num, err := lexer.integer()
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, err := lexer.integer(); err != nil {
+		return 0, nil, err
+	}
+	if lexer.token() != "obj" {
+		return 0, nil, fmt.Errorf("%w: expected object %d", ErrInvalidPDF, num)
+	}
+	obj, err := lexer.object(0)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	dict, ok := obj.(pdfDict)
+	if !ok || !lexer.hasPrefix("stream") {
+		return num, obj, nil
+	}
+	lexer.pos += len("stream")
+	if lexer.pos < len(d.data) && d.data[lexer.pos] == '\r' {
+		lexer.pos++
+	}
+	if lexer.pos < len(d.data) && d.data[lexer.pos] == '\n' {
+		lexer.pos++
+	}
+	data, err := d.streamData(dict, lexer.pos)
+	if err != nil {
+		return 0, nil, err
+	}
+	return num, &pdfStream{dict: dict, data: data}, nil
+}
+
+// streamData returns the raw data of a stream starting at an offset. The stream /Length is
+// used when it ends right before endstream, otherwise the data runs up to endstream.
+func (d *pdfDocument) streamData(dict pdfDict, start int) ([]byte, error) {
+	if length, err := d.resolveInt(dict["Length"]); err == nil && length <= len(d.data)-start {
+		end := &pdfLexer{data: d.data, pos: start + length}
+		if end.hasPrefix("endstream") {
+			return d.data[start : start+length], nil
+		}
+	}
+
+	end := bytes.Index(d.data[start:], []byte("endstream"))
+	if end < 0 {
+		return nil, fmt.Errorf("%w: unterminated stream", ErrInvalidPDF)
+	}
+	data := d.data[start : start+end]
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	return data, nil
+}
+
+// loadXref reads the cross-reference sections from the last one back through /Prev.
+// Newer sections take precedence over older ones.
+func (d *pdfDocument) loadXref() error {
+	start := bytes.LastIndex(d.data, []byte("startxref"))
+	if start < 0 {
+		return fmt.Errorf("%w: no startxref", ErrInvalidPDF)
+	}
+	lexer := &pdfLexer{data: d.data, pos: start + len("startxref")}
+	offset, err := lexer.integer()
+	if err != nil {
+		return err
+	}
+
+	visited := map[int]bool{}
+	for !visited[offset] {
+		visited[offset] = true
+		if len(visited) > maxPDFXrefSections {
+			return fmt.Errorf("%w: too many cross-reference sections", ErrInvalidPDF)
+		}
+
+		entries, trailer, err := d.xrefSection(offset)
+		if err != nil {
+			return err
+		}
+		if d.trailer == nil {
+			d.trailer = trailer
+		}
+
+		// Hybrid files list compressed objects in a stream, they take precedence over the table
+		if streamOffset, ok := trailer["XRefStm"].(int); ok && !visited[streamOffset] {
+			visited[streamOffset] = true
+			streamEntries, _, err := d.xrefSection(streamOffset)
+			if err != nil {
+				return err
+			}
+			d.addXrefEntries(streamEntries)
+		}
+		d.addXrefEntries(entries)
+
+		prev, ok := trailer["Prev"].(int)
+		if !ok {
+			break
+		}
+		offset = prev
+	}
+
+	if d.trailer["Root"] == nil {
+		return fmt.Errorf("%w: trailer has no root", ErrInvalidPDF)
+	}
+	return nil
+}
+
+// addXrefEntries adds the entries of an older cross-reference section
+func (d *pdfDocument) addXrefEntries(entries map[int]pdfXrefEntry) {
+	for num, entry := range entries {
+		if _, ok := d.xref[num]; !ok {
+			d.xref[num] = entry
+		}
+	}
+}
+
+// xrefSection reads the cross-reference table or stream at an offset with its trailer dictionary
+func (d *pdfDocument) xrefSection(offset int) (map[int]pdfXrefEntry, pdfDict, error) {
+	if offset < 0 || offset >= len(d.data) {
+		return nil, nil, fmt.Errorf("%w: cross-reference offset out of range", ErrInvalidPDF)
+	}
+	lexer := &pdfLexer{data: d.data, pos: offset}
+	if lexer.hasPrefix("xref") {
+		lexer.pos += len("xref")
+		return d.xrefTable(lexer)
+	}
+
+	_, obj, err := d.objectAt(offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	stream, ok := obj.(*pdfStream)
+	if !ok || stream.dict["Type"] != pdfName("XRef") {
+		return nil, nil, fmt.Errorf("%w: no cross-reference at offset %d", ErrInvalidPDF, offset)
+	}
+	entries, err := d.xrefStream(stream)
+	return entries, stream.dict, err
+}
+
+// xrefTable reads the subsections of a cross-reference table and its trailer
+func (d *pdfDocument) xrefTable(lexer *pdfLexer) (map[int]pdfXrefEntry, pdfDict, error) {
+	entries := map[int]pdfXrefEntry{}
+	for {
+		if lexer.hasPrefix("trailer") {
+			lexer.pos += len("trailer")
+			obj, err := lexer.object(0)
+			if err != nil {
+				return nil, nil, err
+			}
+			trailer, ok := obj.(pdfDict)
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: trailer is not a dictionary", ErrInvalidPDF)
+			}
+			return entries, trailer, nil
+		}
+
+		first, err := lexer.integer()
+		if err != nil {
+			return nil, nil, err
+		}
+		count, err := lexer.integer()
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := 0; i < count; i++ {
+			offset, err := lexer.integer()
+			if err != nil {
+				return nil, nil, err
+			}
+			if _, err := lexer.integer(); err != nil {
+				return nil, nil, err
+			}
+			switch lexer.token() {
+			case "n":
+				entries[first+i] = pdfXrefEntry{offset: offset}
+			case "f":
+				entries[first+i] = pdfXrefEntry{free: true}
+			default:
+				return nil, nil, fmt.Errorf("%w: invalid cross-reference entry", ErrInvalidPDF)
+			}
+		}
+	}
+}
+
+// xrefStream reads the entries of a cross-reference stream
+func (d *pdfDocument) xrefStream(stream *pdfStream) (map[int]pdfXrefEntry, error) {
+	data, err := d.decode(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	widths, err := d.resolveInts(stream.dict["W"])
+	if err != nil || len(widths) != 3 {
+		return nil, fmt.Errorf("%w: invalid cross-reference stream widths", ErrInvalidPDF)
+	}
+	rowLength := 0
+	for _, width := range widths {
+		if width > 8 {
+			return nil, fmt.Errorf("%w: invalid cross-reference stream widths", ErrInvalidPDF)
+		}
+		rowLength += width
+	}
+	if rowLength == 0 {
+		return nil, fmt.Errorf("%w: invalid cross-reference stream widths", ErrInvalidPDF)
+	}
+
+	index := []int{0, len(data) / rowLength}
+	if _, ok := stream.dict["Index"]; ok {
+		if index, err = d.resolveInts(stream.dict["Index"]); err != nil || len(index)%2 != 0 {
+			return nil, fmt.Errorf("%w: invalid cross-reference stream index", ErrInvalidPDF)
+		}
+	} else if size, err := d.resolveInt(stream.dict["Size"]); err == nil && size < index[1] {
+		index[1] = size
+	}
+
+	entries := map[int]pdfXrefEntry{}
+	pos := 0
+	for i := 0; i < len(index); i += 2 {
+		for num := index[i]; num < index[i]+index[i+1] && pos+rowLength <= len(data); num++ {
+			fields := [3]int{1, 0, 0} // The type defaults to uncompressed when it has no width
+			for f, width := range widths {
+				if width == 0 {
+					continue
+				}
+				fields[f] = 0
+				for _, b := range data[pos : pos+width] {
+					fields[f] = fields[f]<<8 | int(b)
+				}
+				pos += width
+			}
+
+			switch fields[0] {
+			case 0:
+				entries[num] = pdfXrefEntry{free: true}
+			case 1:
+				entries[num] = pdfXrefEntry{offset: fields[1]}
+			case 2:
+				entries[num] = pdfXrefEntry{compressed: true, stream: fields[1], index: fields[2]}
+			}
+		}
+	}
+	return entries, nil
+}
+
+// rebuildXref locates the objects of a file with a damaged cross-reference table by scanning
+// for object headers; later definitions of an object replace earlier ones. The trailer is the
+// last trailer dictionary or cross-reference stream with a /Root, or else points to the catalog.
+func (d *pdfDocument) rebuildXref() {
+	headers := pdfObjectHeader.FindAllSubmatchIndex(d.data, -1)
+	for _, match := range headers {
+		if match[0] > 0 && !isPDFDelimiter(d.data[match[0]-1]) {
+			continue
+		}
+		num, err := strconv.Atoi(string(d.data[match[2]:match[3]]))
+		if err != nil {
+			continue
+		}
+		d.xref[num] = pdfXrefEntry{offset: match[0]}
+	}
+
+	nums := make([]int, 0, len(d.xref))
+	for num := range d.xref {
+		nums = append(nums, num)
+	}
+	sort.Slice(nums, func(i, j int) bool { return d.xref[nums[i]].offset < d.xref[nums[j]].offset })
+
+	var catalog pdfRef
+	for _, num := range nums {
+		_, obj, err := d.objectAt(d.xref[num].offset)
+		if err != nil {
+			continue
+		}
+		dict, ok := obj.(pdfDict)
+		if stream, isStream := obj.(*pdfStream); isStream {
+			dict, ok = stream.dict, true
+		}
+		if !ok {
+			continue
+		}
+		switch dict["Type"] {
+		case pdfName("ObjStm"):
+			d.pendingObjStreams = append(d.pendingObjStreams, num)
+		case pdfName("XRef"):
+			if dict["Root"] != nil {
+				d.trailer = dict
+			}
+		case pdfName("Catalog"):
+			catalog = pdfRef{num: num}
+		}
+	}
+
+	for end := len(d.data); ; {
+		start := bytes.LastIndex(d.data[:end], []byte("trailer"))
+		if start < 0 {
+			break
+		}
+		lexer := &pdfLexer{data: d.data, pos: start + len("trailer")}
+		if obj, err := lexer.object(0); err == nil {
+			if trailer, ok := obj.(pdfDict); ok && trailer["Root"] != nil {
+				d.trailer = trailer
+				break
+			}
+		}
+		end = start
+	}
+
+	if d.trailer == nil && catalog.num != 0 {
+		d.trailer = pdfDict{"Root": catalog}
+	}
+}
+
+// searchObjectStream adds the objects of the next pending object stream to the
+// cross-reference table, unless they are defined elsewhere. Damaged object streams are
+// skipped, only running out of budget is an error.
+func (d *pdfDocument) searchObjectStream() error {
+	num := d.pendingObjStreams[0]
+	d.pendingObjStreams = d.pendingObjStreams[1:]
+	stream, err := d.objectStream(num)
+	if errors.Is(err, ErrPDFTooComplex) {
+		return err
+	}
+	if err != nil {
+		return nil
+	}
+	for index, obj := range stream.nums {
+		if _, ok := d.xref[obj]; !ok {
+			d.xref[obj] = pdfXrefEntry{compressed: true, stream: num, index: index}
+		}
+	}
+	return nil
+}
+
+// objectStream decodes an object stream and reads its object offsets
+func (d *pdfDocument) objectStream(num int) (*pdfObjectStream, error) {
+	if stream, ok := d.objStreams[num]; ok {
+		return stream, nil
+	}
+
+	obj, err := d.resolve(pdfRef{num: num})
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := obj.(*pdfStream)
+	if !ok || stream.dict["Type"] != pdfName("ObjStm") {
+		return nil, fmt.Errorf("%w: object %d is not an object stream", ErrInvalidPDF, num)
+	}
+	data, err := d.decode(stream)
+	if err != nil {
+		return nil, err
+	}
+	count, err := d.resolveInt(stream.dict["N"])
+	if err != nil {
+		return nil, err
+	}
+	first, err := d.resolveInt(stream.dict["First"])
+	if err != nil {
+		return nil, err
+	}
+	if first > len(data) || count > first {
+		return nil, fmt.Errorf("%w: invalid object stream %d", ErrInvalidPDF, num)
+	}
+
+	objStream := &pdfObjectStream{data: data}
+	lexer := &pdfLexer{data: data[:first]}
+	for i := 0; i < count; i++ {
+		obj, err := lexer.integer()
+		if err != nil {
+			return nil, err
+		}
+		offset, err := lexer.integer()
+		if err != nil {
+			return nil, err
+		}
+		if first+offset >= len(data) {
+			return nil, fmt.Errorf("%w: invalid object stream %d", ErrInvalidPDF, num)
+		}
+		objStream.nums = append(objStream.nums, obj)
+		objStream.offsets = append(objStream.offsets, first+offset)
+	}
+	d.objStreams[num] = objStream
+	return objStream, nil
+}
+
+// resolveInts resolves an array of non-negative integers
+func (d *pdfDocument) resolveInts(obj interface{}) ([]int, error) {
+	resolved, err := d.resolve(obj)
+	if err != nil {
+		return nil, err
+	}
+	array, ok := resolved.(pdfArray)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected an array", ErrInvalidPDF)
+	}
+	values := make([]int, len(array))
+	for i, item := range array {
+		if values[i], err = d.resolveInt(item); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// decode returns the data of a stream. Only Flate compression, optionally with PNG
+// predictors, is supported, as used by cross-reference and object streams.
+func (d *pdfDocument) decode(stream *pdfStream) ([]byte, error) {
+	filter, err := d.resolve(stream.dict["Filter"])
+	if err != nil {
+		return nil, err
+	}
+	params, err := d.resolve(stream.dict["DecodeParms"])
+	if err != nil {
+		return nil, err
+	}
+	if array, ok := filter.(pdfArray); ok && len(array) == 1 {
+		filter = array[0]
+		if paramsArray, ok := params.(pdfArray); ok && len(paramsArray) == 1 {
+			if params, err = d.resolve(paramsArray[0]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	switch filter {
+	case nil:
+		return stream.data, nil
+	case pdfName("FlateDecode"):
+	default:
+		return nil, fmt.Errorf("%w: unsupported stream filter", ErrInvalidPDF)
+	}
+
+	data, err := d.inflate(stream.data)
+	if err != nil {
+		return nil, err
+	}
+
+	paramsDict, _ := params.(pdfDict)
+	predictor := d.intParam(paramsDict, "Predictor", 1)
+	switch {
+	case predictor == 1:
+		return data, nil
+	case predictor >= 10:
+		colors := d.intParam(paramsDict, "Colors", 1)
+		bits := d.intParam(paramsDict, "BitsPerComponent", 8)
+		columns := d.intParam(paramsDict, "Columns", 1)
+		return unpredictPNG(data, colors, bits, columns)
+	default:
+		return nil, fmt.Errorf("%w: unsupported stream predictor", ErrInvalidPDF)
+	}
+}
+
+// intParam returns an integer decode parameter, or its default when it is not set
+func (d *pdfDocument) intParam(params pdfDict, key pdfName, fallback int) int {
+	if value, err := d.resolveInt(params[key]); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// inflate decompresses Flate data, charging its size to the budget of the document
+func (d *pdfDocument) inflate(raw []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid compressed stream", ErrInvalidPDF)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, *d.budget+1))
+	if int64(len(data)) > *d.budget {
+		*d.budget = 0
+		return nil, ErrPDFTooComplex
+	}
+	*d.budget -= int64(len(data))
+	// Truncated streams are common, keep what could be inflated
+	if err != nil && len(data) == 0 {
+		return nil, fmt.Errorf("%w: invalid compressed stream", ErrInvalidPDF)
+	}
+	return data, nil
+}
+
+// unpredictPNG reverses the PNG row filters applied to predicted stream data
+func unpredictPNG(data []byte, colors, bits, columns int) ([]byte, error) {
+	pixelLength := (colors*bits + 7) / 8
+	rowLength := (colors*bits*columns + 7) / 8
+	if pixelLength == 0 || rowLength == 0 || rowLength >= len(data) {
+		return nil, fmt.Errorf("%w: invalid stream predictor parameters", ErrInvalidPDF)
+	}
+
+	output := make([]byte, 0, len(data)/(rowLength+1)*rowLength)
+	prev := make([]byte, rowLength)
+	for pos := 0; pos+rowLength+1 <= len(data); pos += rowLength + 1 {
+		filter := data[pos]
+		row := append([]byte(nil), data[pos+1:pos+1+rowLength]...)
+		for i := range row {
+			var left, upLeft byte
+			if i >= pixelLength {
+				left, upLeft = row[i-pixelLength], prev[i-pixelLength]
+			}
+			up := prev[i]
+			switch filter {
+			case 0:
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			default:
+				return nil, fmt.Errorf("%w: invalid PNG row filter", ErrInvalidPDF)
+			}
+		}
+		output = append(output, row...)
+		prev = row
+	}
+	return output, nil
+}
+
+// paeth is the PNG Paeth predictor
+func paeth(left, up, upLeft byte) byte {
+	p := int(left) + int(up) - int(upLeft)
+	pa, pb, pc := absInt(p-int(left)), absInt(p-int(up)), absInt(p-int(upLeft))
+	switch {
+	case pa <= pb && pa <= pc:
+		return left
+	case pb <= pc:
+		return up
+	default:
+		return upLeft
+	}
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
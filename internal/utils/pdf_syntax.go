@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PDF objects as read by pdfLexer: integers are int, reals float64, strings []byte,
+// booleans bool and null nil
+type (
+	pdfName  string
+	pdfArray []interface{}
+	pdfDict  map[pdfName]interface{}
+)
+
+// pdfRef is a reference to an indirect object
+type pdfRef struct {
+	num int
+	gen int
+}
+
+// pdfStream is a stream object with its undecoded data
+type pdfStream struct {
+	dict pdfDict
+	data []byte
+}
+
+// maxPDFNesting limits how deeply arrays and dictionaries may be nested
+const maxPDFNesting = 64
+
+// pdfLexer reads PDF objects from a byte slice
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+// isPDFSpace checks if a byte is PDF white space
+func isPDFSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+// isPDFDelimiter checks if a byte ends a name, number or keyword
+func isPDFDelimiter(c byte) bool {
+	return isPDFSpace(c) || strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// skipSpace skips white space and comments
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isPDFSpace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// hasPrefix skips white space and checks if the next token starts with a keyword
+func (l *pdfLexer) hasPrefix(keyword string) bool {
+	l.skipSpace()
+	return bytes.HasPrefix(l.data[l.pos:], []byte(keyword))
+}
+
+// token reads a run of regular characters
+func (l *pdfLexer) token() string {
+	l.skipSpace()
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// integer reads a non-negative integer
+func (l *pdfLexer) integer() (int, error) {
+	token := l.token()
+	value, err := strconv.Atoi(token)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%w: expected an integer, got %q", ErrInvalidPDF, token)
+	}
+	return value, nil
+}
+
+// object reads the next object; references are returned unresolved
+func (l *pdfLexer) object(depth int) (interface{}, error) {
+	if depth > maxPDFNesting {
+		return nil, fmt.Errorf("%w: objects are nested too deeply", ErrInvalidPDF)
+	}
+
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidPDF)
+	}
+
+	switch c := l.data[l.pos]; {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isPDFDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfName(l.data[start:l.pos]), nil
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return l.dict(depth)
+	case c == '<':
+		end := bytes.IndexByte(l.data[l.pos:], '>')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated hex string", ErrInvalidPDF)
+		}
+		value := l.data[l.pos+1 : l.pos+end]
+		l.pos += end + 1
+		return value, nil
+	case c == '[':
+		l.pos++
+		return l.array(depth)
+	case c == '(':
+		return l.literalString()
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.number()
+	}
+
+	switch token := l.token(); token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected token %q", ErrInvalidPDF, token)
+	}
+}
+
+// dict reads the entries of a dictionary after its opening <<
+func (l *pdfLexer) dict(depth int) (pdfDict, error) {
+	dict := pdfDict{}
+	for {
+		if l.hasPrefix(">>") {
+			l.pos += 2
+			return dict, nil
+		}
+		key, err := l.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			return nil, fmt.Errorf("%w: dictionary key is not a name", ErrInvalidPDF)
+		}
+		value, err := l.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		dict[name] = value
+	}
+}
+
+// array reads the items of an array after its opening bracket
+func (l *pdfLexer) array(depth int) (pdfArray, error) {
+	array := pdfArray{}
+	for {
+		if l.hasPrefix("]") {
+			l.pos++
+			return array, nil
+		}
+		item, err := l.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, item)
+	}
+}
+
+// literalString reads a string in parentheses, which may contain balanced parentheses
+// and escapes; escapes are kept as they are
+func (l *pdfLexer) literalString() ([]byte, error) {
+	start := l.pos + 1
+	open := 0
+	for ; l.pos < len(l.data); l.pos++ {
+		switch l.data[l.pos] {
+		case '\\':
+			l.pos++
+		case '(':
+			open++
+		case ')':
+			open--
+			if open == 0 {
+				l.pos++
+				return l.data[start : l.pos-1], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: unterminated string", ErrInvalidPDF)
+}
+
+// number reads a number, or a reference when an integer is followed by a generation and R
+func (l *pdfLexer) number() (interface{}, error) {
+	token := l.token()
+	if strings.ContainsRune(token, '.') {
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %q", ErrInvalidPDF, token)
+		}
+		return value, nil
+	}
+	value, err := strconv.Atoi(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid number %q", ErrInvalidPDF, token)
+	}
+
+	// Look ahead for "gen R"
+	if value >= 0 {
+		start := l.pos
+		if gen, err := l.integer(); err == nil && l.hasPrefix("R") &&
+			(l.pos+1 == len(l.data) || isPDFDelimiter(l.data[l.pos+1])) {
+			l.pos++
+			return pdfRef{num: value, gen: gen}, nil
+		}
+		l.pos = start
+	}
+	return value, nil
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// classicPDF writes objects 1..n with a classic cross-reference table. With shift the
+// table offsets are off by a few bytes, as in files edited by careless tools.
+func classicPDF(objects []string, shift int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset+shift)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// pageTree returns a catalog, a page tree root declaring count pages and pages leaves
+func pageTree(pages, count int) []string {
+	kids := make([]string, pages)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", i+3)
+	}
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), count),
+	}
+	for range kids {
+		objects = append(objects, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>")
+	}
+	return objects
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+// xrefStreamPDF keeps the page tree in an object stream and locates it through a
+// cross-reference stream using the PNG Up predictor
+func xrefStreamPDF(pages int) []byte {
+	tree := pageTree(pages, pages)
+	objStm := len(tree) + 1
+
+	var header, body bytes.Buffer
+	for i, obj := range tree[1:] {
+		fmt.Fprintf(&header, "%d %d ", i+2, body.Len())
+		body.WriteString(obj + "\n")
+	}
+	packed := deflate(append(header.Bytes(), body.Bytes()...))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+	catalog := buf.Len()
+	fmt.Fprintf(&buf, "1 0 obj\n%s\nendobj\n", tree[0])
+	stream := buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /ObjStm /N %d /First %d /Filter /FlateDecode /Length %d >>\nstream\n",
+		objStm, len(tree)-1, header.Len(), len(packed))
+	buf.Write(packed)
+	buf.WriteString("\nendstream\nendobj\n")
+	xref := buf.Len()
+
+	// Rows of [type, offset or stream, index] with W [1 2 1]
+	rows := [][4]byte{{0, 0, 0, 0}, {1, byte(catalog >> 8), byte(catalog), 0}}
+	for i := range tree[1:] {
+		rows = append(rows, [4]byte{2, byte(objStm >> 8), byte(objStm), byte(i)})
+	}
+	rows = append(rows, [4]byte{1, byte(stream >> 8), byte(stream), 0}, [4]byte{1, byte(xref >> 8), byte(xref), 0})
+	var predicted bytes.Buffer
+	var previous [4]byte
+	for _, row := range rows {
+		predicted.WriteByte(2)
+		for i := range row {
+			predicted.WriteByte(row[i] - previous[i])
+		}
+		previous = row
+	}
+	packed = deflate(predicted.Bytes())
+
+	fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /XRef /Size %d /Root 1 0 R /W [1 2 1] /Filter /FlateDecode "+
+		"/DecodeParms << /Predictor 12 /Columns 4 >> /Length %d >>\nstream\n", objStm+1, len(rows), len(packed))
+	buf.Write(packed)
+	fmt.Fprintf(&buf, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", xref)
+	return buf.Bytes()
+}
+
+// inflateBombPDF has no cross-reference data and hides its page tree behind object
+// streams that inflate to more than the budget
+func inflateBombPDF() []byte {
+	zeros := deflate(make([]byte, 20<<20))
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	for num := 3; num < 8; num++ {
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /ObjStm /N 1 /First 4 /Filter /FlateDecode /Length %d >>\nstream\n", num, len(zeros))
+		buf.Write(zeros)
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+	return buf.Bytes()
+}
+
+func TestCountPDFPages(t *testing.T) {
+	nested := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 3 >>",
+		"<< /Type /Pages /Parent 2 0 R /Kids [5 0 R 6 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+		"<< /Type /Page /Parent 3 0 R >>",
+		"<< /Type /Page /Parent 3 0 R >>",
+	}
+	cycle := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Pages /Parent 2 0 R /Kids [2 0 R] /Count 1 >>",
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    int
+		wantErr error
+	}{
+		{name: "classic xref table", data: classicPDF(pageTree(3, 3), 0), want: 3},
+		{name: "nested page tree", data: classicPDF(nested, 0), want: 3},
+		{name: "xref and object streams", data: xrefStreamPDF(4), want: 4},
+		{name: "damaged xref is rebuilt", data: classicPDF(pageTree(2, 2), 3), want: 2},
+		{name: "count disagrees with the page tree", data: classicPDF(pageTree(3, 500), 0), wantErr: ErrInvalidPDF},
+		{name: "page tree cycle", data: classicPDF(cycle, 0), wantErr: ErrInvalidPDF},
+		{name: "exceeds the inflate budget", data: inflateBombPDF(), wantErr: ErrPDFTooComplex},
+		{name: "not a PDF", data: []byte("hello"), wantErr: ErrInvalidPDF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CountPDFPages(tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CountPDFPages() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CountPDFPages() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CountPDFPages() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
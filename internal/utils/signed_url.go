@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// SignResource returns an HMAC signature granting access to a resource until expiresAt
+func SignResource(secret, resource string, expiresAt time.Time) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(resource + "\n" + strconv.FormatInt(expiresAt.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyResourceSignature checks a signature created by SignResource and that it has not expired
+func VerifyResourceSignature(secret, resource string, expiresUnix int64, signature string) bool {
+	if time.Now().Unix() > expiresUnix {
+		return false
+	}
+	expected := SignResource(secret, resource, time.Unix(expiresUnix, 0))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
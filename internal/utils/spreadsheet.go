@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// SpreadsheetFormat is a supported spreadsheet file format
+type SpreadsheetFormat string
+
+// SpreadsheetFormat constants
+const (
+	SpreadsheetCSV  SpreadsheetFormat = "csv"
+	SpreadsheetXLSX SpreadsheetFormat = "xlsx"
+)
+
+// ErrUnsupportedSpreadsheet is returned for files that are neither CSV nor XLSX
+var ErrUnsupportedSpreadsheet = errors.New("only CSV and XLSX files are supported")
+
+// SpreadsheetFormatOf returns the format of a file by its extension
+func SpreadsheetFormatOf(fileName string) (SpreadsheetFormat, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return SpreadsheetCSV, nil
+	case ".xlsx":
+		return SpreadsheetXLSX, nil
+	}
+	return "", ErrUnsupportedSpreadsheet
+}
+
+// ContentType returns the MIME type of the format
+func (f SpreadsheetFormat) ContentType() string {
+	if f == SpreadsheetXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ReadSpreadsheet returns the rows of a CSV file or of the first sheet of an XLSX workbook.
+// Rows may have fewer cells than the header when trailing cells are empty.
+func ReadSpreadsheet(data []byte, format SpreadsheetFormat) ([][]string, error) {
+	switch format {
+	case SpreadsheetCSV:
+		// Spreadsheet programs often prepend a byte order mark
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, errors.New("invalid CSV file: " + err.Error())
+		}
+		return rows, nil
+	case SpreadsheetXLSX:
+		workbook, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.New("invalid XLSX file")
+		}
+		defer workbook.Close()
+		rows, err := workbook.GetRows(workbook.GetSheetName(0))
+		if err != nil {
+			return nil, errors.New("invalid XLSX file: " + err.Error())
+		}
+		return rows, nil
+	}
+	return nil, ErrUnsupportedSpreadsheet
+}
+
+// WriteSpreadsheet writes rows as a CSV file or as a single sheet XLSX workbook
+func WriteSpreadsheet(w io.Writer, rows [][]string, format SpreadsheetFormat) error {
+	switch format {
+	case SpreadsheetCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case SpreadsheetXLSX:
+		workbook := excelize.NewFile()
+		defer workbook.Close()
+		sheet := workbook.GetSheetName(0)
+		for i, row := range rows {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return err
+			}
+			values := make([]interface{}, len(row))
+			for j, value := range row {
+				values[j] = value
+			}
+			if err := workbook.SetSheetRow(sheet, cell, &values); err != nil {
+				return err
+			}
+		}
+		return workbook.Write(w)
+	}
+	return ErrUnsupportedSpreadsheet
+}